@@ -0,0 +1,204 @@
+package cmds
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/go-go-golems/glazed/pkg/cmds"
+	"github.com/go-go-golems/glazed/pkg/cmds/layers"
+	"github.com/go-go-golems/glazed/pkg/cmds/parameters"
+	"github.com/go-go-golems/glazed/pkg/helpers/csv"
+	"github.com/go-go-golems/glazed/pkg/helpers/diff"
+	"github.com/go-go-golems/glazed/pkg/middlewares"
+	"github.com/go-go-golems/glazed/pkg/settings"
+	"github.com/go-go-golems/glazed/pkg/types"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+type DiffSettings struct {
+	Keys             []string `glazed.parameter:"key"`
+	InputFormat      string   `glazed.parameter:"input-format"`
+	IgnoreFields     []string `glazed.parameter:"ignore-field"`
+	IncludeUnchanged bool     `glazed.parameter:"include-unchanged"`
+	Old              string   `glazed.parameter:"old"`
+	New              string   `glazed.parameter:"new"`
+}
+
+type DiffCommand struct {
+	*cmds.CommandDescription
+}
+
+func NewDiffCommand() (*DiffCommand, error) {
+	glazedParameterLayer, err := settings.NewGlazedParameterLayers()
+	if err != nil {
+		return nil, errors.Wrap(err, "could not create Glazed parameter layer")
+	}
+
+	return &DiffCommand{
+		CommandDescription: cmds.NewCommandDescription(
+			"diff",
+			cmds.WithShort("Compare two data sets row by row"),
+			cmds.WithLong("Match the rows of two data sets on their key columns and "+
+				"output the added, removed and changed rows, with the old and new value of each field."),
+			cmds.WithFlags(
+				parameters.NewParameterDefinition(
+					"key",
+					parameters.ParameterTypeStringList,
+					parameters.WithHelp("Columns used to match the rows of both inputs"),
+					parameters.WithRequired(true),
+				),
+				parameters.NewParameterDefinition(
+					"input-format",
+					parameters.ParameterTypeChoice,
+					parameters.WithHelp("Format of the inputs (auto detects the format from the file extension)"),
+					parameters.WithChoices([]string{"auto", "json", "yaml", "csv", "tsv"}),
+					parameters.WithDefault("auto"),
+				),
+				parameters.NewParameterDefinition(
+					"ignore-field",
+					parameters.ParameterTypeStringList,
+					parameters.WithHelp("Fields that are not compared"),
+					parameters.WithDefault([]string{}),
+				),
+				parameters.NewParameterDefinition(
+					"include-unchanged",
+					parameters.ParameterTypeBool,
+					parameters.WithHelp("Also output the rows that didn't change"),
+					parameters.WithDefault(false),
+				),
+			),
+			cmds.WithArguments(
+				parameters.NewParameterDefinition(
+					"old",
+					parameters.ParameterTypeString,
+					parameters.WithHelp("Old data set"),
+					parameters.WithRequired(true),
+				),
+				parameters.NewParameterDefinition(
+					"new",
+					parameters.ParameterTypeString,
+					parameters.WithHelp("New data set"),
+					parameters.WithRequired(true),
+				),
+			),
+			cmds.WithLayers(
+				glazedParameterLayer,
+			),
+		),
+	}, nil
+}
+
+func (d *DiffCommand) Run(
+	ctx context.Context,
+	parsedLayers map[string]*layers.ParsedParameterLayer,
+	ps map[string]interface{},
+	gp middlewares.Processor,
+) error {
+	s, err := cmds.Settings[DiffSettings](parsedLayers, ps)
+	if err != nil {
+		return err
+	}
+	if len(s.Keys) == 0 {
+		return errors.New("at least one key column is required")
+	}
+
+	oldRows, err := loadRows(s.Old, s.InputFormat)
+	if err != nil {
+		return err
+	}
+	newRows, err := loadRows(s.New, s.InputFormat)
+	if err != nil {
+		return err
+	}
+
+	differ := diff.NewRowDiffer(
+		s.Keys,
+		diff.WithIgnoreFields(s.IgnoreFields),
+		diff.WithIncludeUnchanged(s.IncludeUnchanged),
+	)
+	rows, err := differ.Diff(oldRows, newRows)
+	if err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		err = gp.AddRow(ctx, row)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// loadRows reads the rows of a JSON, YAML, CSV or TSV file. JSON and YAML files
+// can either contain an array of objects or a single object.
+func loadRows(fileName string, format string) ([]types.Row, error) {
+	if format == "" || format == "auto" {
+		switch strings.ToLower(filepath.Ext(fileName)) {
+		case ".yaml", ".yml":
+			format = "yaml"
+		case ".csv":
+			format = "csv"
+		case ".tsv":
+			format = "tsv"
+		default:
+			format = "json"
+		}
+	}
+
+	var b []byte
+	var err error
+	if fileName == "-" {
+		b, err = io.ReadAll(os.Stdin)
+	} else {
+		b, err = os.ReadFile(fileName)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not read %s", fileName)
+	}
+
+	switch format {
+	case "json":
+		if bytes.HasPrefix(bytes.TrimSpace(b), []byte("[")) {
+			rows := []types.Row{}
+			err = json.Unmarshal(b, &rows)
+			return rows, errors.Wrapf(err, "could not decode %s as JSON array", fileName)
+		}
+		row := types.NewRow()
+		err = json.Unmarshal(b, &row)
+		return []types.Row{row}, errors.Wrapf(err, "could not decode %s as JSON object", fileName)
+
+	case "yaml":
+		rows := []types.Row{}
+		if err = yaml.Unmarshal(b, &rows); err == nil {
+			return rows, nil
+		}
+		row := types.NewRow()
+		err = yaml.Unmarshal(b, &row)
+		return []types.Row{row}, errors.Wrapf(err, "could not decode %s as YAML", fileName)
+
+	case "csv", "tsv":
+		options := []csv.ParseCSVOption{}
+		if format == "tsv" {
+			options = append(options, csv.WithComma('\t'))
+		}
+		header, data, err := csv.ParseCSV(bytes.NewReader(b), options...)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not parse %s as %s", fileName, format)
+		}
+		rows := []types.Row{}
+		for _, d := range data {
+			rows = append(rows, types.NewRowFromMapWithColumns(d, header))
+		}
+		return rows, nil
+
+	default:
+		return nil, errors.Errorf("unknown input format %s", format)
+	}
+}
@@ -0,0 +1,184 @@
+package cmds
+
+import (
+	"context"
+	"github.com/go-go-golems/glazed/pkg/cmds"
+	"github.com/go-go-golems/glazed/pkg/cmds/layers"
+	"github.com/go-go-golems/glazed/pkg/cmds/parameters"
+	"github.com/go-go-golems/glazed/pkg/config"
+	"github.com/go-go-golems/glazed/pkg/middlewares"
+	"github.com/go-go-golems/glazed/pkg/settings"
+	"github.com/go-go-golems/glazed/pkg/types"
+	"github.com/pkg/errors"
+	"sort"
+	"strings"
+)
+
+// NewProfilesCommands returns the commands listing and showing the profiles stored in the
+// config files of the application appName.
+func NewProfilesCommands(appName string) ([]cmds.Command, error) {
+	listCmd, err := NewProfilesListCommand(appName)
+	if err != nil {
+		return nil, err
+	}
+	showCmd, err := NewProfilesShowCommand(appName)
+	if err != nil {
+		return nil, err
+	}
+	return []cmds.Command{listCmd, showCmd}, nil
+}
+
+func newConfigFlag() *parameters.ParameterDefinition {
+	return parameters.NewParameterDefinition(
+		"config",
+		parameters.ParameterTypeString,
+		parameters.WithHelp("Load profiles from this config file, in addition to the default ones"),
+	)
+}
+
+func loadProfiles(appName string, ps map[string]interface{}) ([]*config.Profile, error) {
+	files := []string{}
+	if configFile, ok := ps["config"].(string); ok && configFile != "" {
+		files = append(files, configFile)
+	}
+	c, err := config.LoadAppConfig(appName, files...)
+	if err != nil {
+		return nil, err
+	}
+	return c.GetProfiles()
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	ret := []string{}
+	for k := range m {
+		ret = append(ret, k)
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+type ProfilesListCommand struct {
+	*cmds.CommandDescription
+	appName string
+}
+
+func NewProfilesListCommand(appName string) (*ProfilesListCommand, error) {
+	glazedParameterLayer, err := settings.NewGlazedParameterLayers()
+	if err != nil {
+		return nil, errors.Wrap(err, "could not create Glazed parameter layer")
+	}
+
+	return &ProfilesListCommand{
+		CommandDescription: cmds.NewCommandDescription(
+			"list",
+			cmds.WithShort("List the profiles defined in the config files"),
+			cmds.WithParents("profiles"),
+			cmds.WithFlags(newConfigFlag()),
+			cmds.WithLayers(glazedParameterLayer),
+		),
+		appName: appName,
+	}, nil
+}
+
+func (p *ProfilesListCommand) Run(
+	ctx context.Context,
+	parsedLayers map[string]*layers.ParsedParameterLayer,
+	ps map[string]interface{},
+	gp middlewares.Processor,
+) error {
+	profiles, err := loadProfiles(p.appName, ps)
+	if err != nil {
+		return err
+	}
+
+	for _, profile := range profiles {
+		parameterCount := 0
+		for _, values := range profile.Layers {
+			parameterCount += len(values)
+		}
+		row := types.NewRow(
+			types.MRP("profile", profile.Name),
+			types.MRP("layers", strings.Join(sortedKeys(profile.Layers), ", ")),
+			types.MRP("parameters", parameterCount),
+			types.MRP("files", strings.Join(profile.Files, ", ")),
+		)
+		err = gp.AddRow(ctx, row)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+type ProfilesShowCommand struct {
+	*cmds.CommandDescription
+	appName string
+}
+
+func NewProfilesShowCommand(appName string) (*ProfilesShowCommand, error) {
+	glazedParameterLayer, err := settings.NewGlazedParameterLayers()
+	if err != nil {
+		return nil, errors.Wrap(err, "could not create Glazed parameter layer")
+	}
+
+	return &ProfilesShowCommand{
+		CommandDescription: cmds.NewCommandDescription(
+			"show",
+			cmds.WithShort("Show the settings of a profile"),
+			cmds.WithParents("profiles"),
+			cmds.WithFlags(newConfigFlag()),
+			cmds.WithArguments(
+				parameters.NewParameterDefinition(
+					"profile",
+					parameters.ParameterTypeString,
+					parameters.WithHelp("Name of the profile"),
+					parameters.WithRequired(true),
+				),
+			),
+			cmds.WithLayers(glazedParameterLayer),
+		),
+		appName: appName,
+	}, nil
+}
+
+func (p *ProfilesShowCommand) Run(
+	ctx context.Context,
+	parsedLayers map[string]*layers.ParsedParameterLayer,
+	ps map[string]interface{},
+	gp middlewares.Processor,
+) error {
+	name, ok := ps["profile"].(string)
+	if !ok {
+		return errors.New("missing profile name")
+	}
+
+	profiles, err := loadProfiles(p.appName, ps)
+	if err != nil {
+		return err
+	}
+
+	for _, profile := range profiles {
+		if profile.Name != name {
+			continue
+		}
+
+		for _, slug := range sortedKeys(profile.Layers) {
+			values := profile.Layers[slug]
+			for _, parameter := range sortedKeys(values) {
+				row := types.NewRow(
+					types.MRP("layer", slug),
+					types.MRP("parameter", parameter),
+					types.MRP("value", values[parameter]),
+				)
+				err = gp.AddRow(ctx, row)
+				if err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+
+	return errors.Errorf("profile %s not found in config files", name)
+}
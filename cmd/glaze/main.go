@@ -4,8 +4,10 @@ import (
 	"github.com/go-go-golems/glazed/cmd/glaze/cmds"
 	"github.com/go-go-golems/glazed/cmd/glaze/cmds/html"
 	"github.com/go-go-golems/glazed/pkg/cli"
+	glazedcmds "github.com/go-go-golems/glazed/pkg/cmds"
 	"github.com/go-go-golems/glazed/pkg/doc"
 	"github.com/go-go-golems/glazed/pkg/help"
+	"github.com/go-go-golems/glazed/pkg/tools"
 	"github.com/spf13/cobra"
 )
 
@@ -24,15 +26,20 @@ func main() {
 
 	helpSystem.SetupCobraRootCommand(rootCmd)
 
+	options := []cli.CobraParserOption{
+		cli.WithConfig("glaze"),
+		cli.WithEnvPrefix("GLAZE"),
+	}
+
 	jsonCmd, err := cmds.NewJsonCommand()
 	cobra.CheckErr(err)
-	command, err := cli.BuildCobraCommandFromGlazeCommand(jsonCmd)
+	command, err := cli.BuildCobraCommandFromGlazeCommand(jsonCmd, options...)
 	cobra.CheckErr(err)
 	rootCmd.AddCommand(command)
 
 	yamlCmd, err := cmds.NewYamlCommand()
 	cobra.CheckErr(err)
-	command, err = cli.BuildCobraCommandFromGlazeCommand(yamlCmd)
+	command, err = cli.BuildCobraCommandFromGlazeCommand(yamlCmd, options...)
 	cobra.CheckErr(err)
 	rootCmd.AddCommand(command)
 	rootCmd.AddCommand(cmds.DocsCmd)
@@ -40,16 +47,32 @@ func main() {
 
 	exampleCmd, err := cmds.NewExampleCommand()
 	cobra.CheckErr(err)
-	command, err = cli.BuildCobraCommandFromGlazeCommand(exampleCmd)
+	command, err = cli.BuildCobraCommandFromGlazeCommand(exampleCmd, options...)
 	cobra.CheckErr(err)
 	rootCmd.AddCommand(command)
 
 	csvCmd, err := cmds.NewCsvCommand()
 	cobra.CheckErr(err)
-	command, err = cli.BuildCobraCommandFromGlazeCommand(csvCmd)
+	command, err = cli.BuildCobraCommandFromGlazeCommand(csvCmd, options...)
+	cobra.CheckErr(err)
+	rootCmd.AddCommand(command)
+
+	diffCmd, err := cmds.NewDiffCommand()
+	cobra.CheckErr(err)
+	command, err = cli.BuildCobraCommandFromGlazeCommand(diffCmd, options...)
 	cobra.CheckErr(err)
 	rootCmd.AddCommand(command)
 
+	profilesCommands, err := cmds.NewProfilesCommands("glaze")
+	cobra.CheckErr(err)
+	err = cli.AddCommandsToRootCommand(rootCmd, profilesCommands, nil, options...)
+	cobra.CheckErr(err)
+
+	rootCmd.AddCommand(cli.NewServeToolsCommand(
+		[]glazedcmds.Command{jsonCmd, yamlCmd, exampleCmd, csvCmd, diffCmd},
+		tools.WithInfo("glaze", version),
+	))
+
 	htmlCommand, err := html.NewHTMLCommand()
 	cobra.CheckErr(err)
 	rootCmd.AddCommand(htmlCommand)
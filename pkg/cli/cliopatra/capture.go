@@ -32,7 +32,8 @@ func getCliopatraFlag(
 			Name:  name,
 			Short: p.Help,
 			Type:  p.Type,
-			Value: v,
+			// sensitive values are masked, unless explicitly revealed (see parameters.RevealParameters)
+			Value: p.RedactValue(v),
 		}
 		if p.Type == parameters.ParameterTypeBool {
 			param.NoValue = true
@@ -47,7 +48,7 @@ func getCliopatraFlag(
 		// Right now we can only kind of guess, by doing some comparison.
 		//
 		// See https://github.com/go-go-golems/glazed/issues/239
-		if !p.IsEqualToDefault(v) {
+		if !p.IsEqualToDefault(parameters.RevealValue(v)) {
 			ret = append(ret, param)
 		}
 	}
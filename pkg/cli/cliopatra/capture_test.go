@@ -6,6 +6,7 @@ import (
 	"github.com/go-go-golems/glazed/pkg/cmds/parameters"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
 	"testing"
 )
 
@@ -175,3 +176,37 @@ func TestSingleLayer(t *testing.T) {
 	assert.Equal(t, "test", p.Flags[0].Name)
 	assert.Equal(t, "", p.Flags[0].Short)
 }
+
+func TestSecretsAreMasked(t *testing.T) {
+	description := cmds.NewCommandDescription("test",
+		cmds.WithFlags(
+			parameters.NewParameterDefinition("token", parameters.ParameterTypeSecret),
+			parameters.NewParameterDefinition("password", parameters.ParameterTypeString,
+				parameters.WithSensitive(true)),
+			parameters.NewParameterDefinition("user", parameters.ParameterTypeString),
+		),
+	)
+	ps := map[string]interface{}{
+		"token":    parameters.Secret("s3cr3t"),
+		"password": "hunter2",
+		"user":     "manuel",
+	}
+
+	p := NewProgramFromCapture(description, ps)
+	b, err := yaml.Marshal(p)
+	require.NoError(t, err)
+	assert.NotContains(t, string(b), "s3cr3t")
+	assert.NotContains(t, string(b), "hunter2")
+	assert.Contains(t, string(b), "manuel")
+
+	// secrets are passed to the program when running it
+	args, err := p.ComputeArgs(ps)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"--token", "s3cr3t", "--password", "hunter2", "--user", "manuel"}, args)
+
+	p = NewProgramFromCapture(description, parameters.RevealParameters(description.GetAllParameterDefinitions(), ps))
+	b, err = yaml.Marshal(p)
+	require.NoError(t, err)
+	assert.Contains(t, string(b), "s3cr3t")
+	assert.Contains(t, string(b), "hunter2")
+}
@@ -334,7 +334,14 @@ func (p *Program) RunIntoWriter(
 		return err2
 	}
 
-	log.Debug().Str("path", path).Strs("args", args).Msg("running program")
+	if e := log.Debug(); e.Enabled() {
+		// don't log the secrets passed to the program
+		redactedArgs, err := p.computeArgs(ps, false)
+		if err != nil {
+			return err
+		}
+		e.Str("path", path).Strs("args", redactedArgs).Msg("running program")
+	}
 
 	cmd := exec.CommandContext(ctx, path, args...)
 	cmd.Env = []string{}
@@ -343,7 +350,14 @@ func (p *Program) RunIntoWriter(
 	for k, v := range p.Env {
 		cmd.Env = append(cmd.Env, k+"="+v)
 	}
-	log.Trace().Strs("env", cmd.Env).Msg("environment")
+	if e := log.Trace(); e.Enabled() {
+		// only log the names, the environment can contain secrets
+		names := []string{}
+		for _, v := range cmd.Env {
+			names = append(names, strings.SplitN(v, "=", 2)[0])
+		}
+		e.Strs("env", names).Msg("environment")
+	}
 
 	if p.Stdin != "" {
 		cmd.Stdin = strings.NewReader(p.Stdin)
@@ -357,9 +371,20 @@ func (p *Program) RunIntoWriter(
 	return nil
 }
 
+// ComputeArgs renders the flags and arguments of the program, using the values in ps
+// if present. Secrets are rendered as is, since the arguments are passed to the program.
 func (p *Program) ComputeArgs(ps map[string]interface{}) ([]string, error) {
+	return p.computeArgs(ps, true)
+}
+
+func (p *Program) computeArgs(ps map[string]interface{}, revealSecrets bool) ([]string, error) {
 	var err error
 
+	renderOptions := []parameters.RenderOption{}
+	if revealSecrets {
+		renderOptions = append(renderOptions, parameters.WithRevealSecrets())
+	}
+
 	args := []string{}
 
 	args = append(args, p.Verbs...)
@@ -389,14 +414,14 @@ func (p *Program) ComputeArgs(ps map[string]interface{}) ([]string, error) {
 		if !ok {
 			value_ = flag.Raw
 		} else {
-			value_, err = parameters.RenderValue(flag.Type, value)
+			value_, err = parameters.RenderValue(flag.Type, value, renderOptions...)
 			if err != nil {
 				return nil, errors.Wrapf(err, "could not render flag %s", flag.Name)
 			}
 		}
 
 		if value_ == "" {
-			value_, err = parameters.RenderValue(flag.Type, flag.Value)
+			value_, err = parameters.RenderValue(flag.Type, flag.Value, renderOptions...)
 			if err != nil {
 				return nil, errors.Wrapf(err, "could not render flag %s", flag.Name)
 			}
@@ -411,14 +436,14 @@ func (p *Program) ComputeArgs(ps map[string]interface{}) ([]string, error) {
 		if !ok {
 			value_ = arg.Raw
 		} else {
-			value_, err = parameters.RenderValue(arg.Type, value)
+			value_, err = parameters.RenderValue(arg.Type, value, renderOptions...)
 			if err != nil {
 				return nil, errors.Wrapf(err, "could not render arg %s", arg.Name)
 			}
 		}
 
 		if value_ == "" {
-			value_, err = parameters.RenderValue(arg.Type, arg.Value)
+			value_, err = parameters.RenderValue(arg.Type, arg.Value, renderOptions...)
 			if err != nil {
 				return nil, errors.Wrapf(err, "could not render arg %s", arg.Name)
 			}
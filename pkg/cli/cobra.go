@@ -4,23 +4,25 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"github.com/go-go-golems/glazed/pkg/cli/cliopatra"
+	"github.com/go-go-golems/glazed/pkg/cli/prompt"
 	"github.com/go-go-golems/glazed/pkg/cmds"
 	"github.com/go-go-golems/glazed/pkg/cmds/alias"
 	"github.com/go-go-golems/glazed/pkg/cmds/layers"
 	"github.com/go-go-golems/glazed/pkg/cmds/parameters"
+	"github.com/go-go-golems/glazed/pkg/completion"
+	"github.com/go-go-golems/glazed/pkg/config"
 	"github.com/go-go-golems/glazed/pkg/formatters"
 	"github.com/go-go-golems/glazed/pkg/helpers"
 	"github.com/go-go-golems/glazed/pkg/helpers/list"
-	strings2 "github.com/go-go-golems/glazed/pkg/helpers/strings"
 	"github.com/go-go-golems/glazed/pkg/middlewares"
 	"github.com/go-go-golems/glazed/pkg/settings"
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
-	"gopkg.in/yaml.v3"
 	"os"
+	"sort"
+	"strconv"
 	"strings"
 )
 
@@ -77,7 +79,11 @@ func GetVerbsFromCobraCommand(cmd *cobra.Command) []string {
 	return verbs
 }
 
-func BuildCobraCommandFromCommandAndFunc(s cmds.Command, run CobraRunFunc) (*cobra.Command, error) {
+func BuildCobraCommandFromCommandAndFunc(
+	s cmds.Command,
+	run CobraRunFunc,
+	options ...CobraParserOption,
+) (*cobra.Command, error) {
 	description := s.Description()
 
 	// check if we need to add the glazedCommandLayer
@@ -113,6 +119,16 @@ func BuildCobraCommandFromCommandAndFunc(s cmds.Command, run CobraRunFunc) (*cob
 					parameters.ParameterTypeBool,
 					parameters.WithHelp("Print the command's YAML"),
 				),
+				parameters.NewParameterDefinition(
+					"print-schema",
+					parameters.ParameterTypeBool,
+					parameters.WithHelp("Print the JSON Schema of the command's parameters"),
+				),
+				parameters.NewParameterDefinition(
+					"reveal-secrets",
+					parameters.ParameterTypeBool,
+					parameters.WithHelp("Include the values of secret parameters when creating aliases, commands and cliopatra programs"),
+				),
 				parameters.NewParameterDefinition(
 					"load-parameters-from-json",
 					parameters.ParameterTypeString,
@@ -129,219 +145,222 @@ func BuildCobraCommandFromCommandAndFunc(s cmds.Command, run CobraRunFunc) (*cob
 		}, description.Layers...)
 	}
 
-	cobraParser, err := NewCobraParserFromCommandDescription(description)
+	// prompt on the terminal by default, keeping stdout for the output of the command
+	options = append([]CobraParserOption{
+		WithPrompter(prompt.NewLinePrompter(os.Stdin, os.Stderr)),
+		WithAutoPrompt(prompt.IsTerminal()),
+	}, options...)
+
+	cobraParser, err := NewCobraParserFromCommandDescription(description, options...)
 	if err != nil {
 		return nil, err
 	}
 
 	cmd := cobraParser.Cmd
 
-	cmd.Run = func(cmd *cobra.Command, args []string) {
-		loadParametersFromJSON, err := cmd.Flags().GetString("load-parameters-from-json")
-		if err != nil {
-			cobra.CheckErr(err)
-			os.Exit(1)
+	// the schema can be printed without passing the required arguments
+	validateArgs := cmd.Args
+	cmd.Args = func(cmd *cobra.Command, args []string) error {
+		printSchema, err := cmd.Flags().GetBool("print-schema")
+		if err == nil && printSchema {
+			return nil
 		}
+		return validateArgs(cmd, args)
+	}
 
-		var parsedLayers map[string]*layers.ParsedParameterLayer
-		var ps map[string]interface{}
+	glazedCommandHooks := glazedCommandHooks()
 
-		if loadParametersFromJSON != "" {
-			result := map[string]interface{}{}
-			bytes, err := os.ReadFile(loadParametersFromJSON)
-			if err != nil {
-				cobra.CheckErr(err)
-			}
-			err = json.Unmarshal(bytes, &result)
-			if err != nil {
-				cobra.CheckErr(err)
-			}
+	cmd.Run = func(cmd *cobra.Command, args []string) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
 
-			parsedLayers, ps, err = cmds.ParseCommandFromMap(description, result)
-			if err != nil {
-				cobra.CheckErr(err)
+		go func() {
+			err := helpers.CancelOnSignal(ctx, os.Interrupt, cancel)
+			if err != nil && err != context.Canceled {
+				fmt.Println(err)
 			}
+		}()
 
-			// Need to update the parsedLayers from command line flags too...
+		// the glazed-command flags are handled before all the other hooks
+		hooks := append([]*Hooks{glazedCommandHooks}, GetHooks(cmd)...)
+		hooks = append(hooks, cobraParser.hooks...)
+		inv := &Invocation{Cmd: cmd, Command: s, Args: args}
 
-			// finally, load normal command line flags and arguments
-			ps_, err := GatherParametersFromCobraCommand(cmd, description, args, true, true)
-			if err != nil {
-				cobra.CheckErr(err)
+		var parseErr error
+		err := executeWithHooks(ctx, hooks, inv, func(ctx context.Context, inv *Invocation) error {
+			showHelp, err := cobraParser.parseInvocation(inv)
+			if showHelp {
+				parseErr = err
 			}
+			return err
+		}, run)
 
-			for _, layer := range parsedLayers {
-				parameterDefinitions := layer.Layer.GetParameterDefinitions()
-				pds := []*parameters.ParameterDefinition{}
-				for _, p := range parameterDefinitions {
-					pds = append(pds, p)
-				}
+		// show help if the flags and arguments couldn't be parsed
+		if err != nil && err == parseErr {
+			fmt.Println(err)
+			err := cmd.Help()
+			cobra.CheckErr(err)
+			os.Exit(1)
+		}
+		if err != context.Canceled {
+			cobra.CheckErr(err)
+		}
+	}
 
-				ps_, err := parameters.GatherFlagsFromCobraCommand(cmd, pds, true, true, layer.Layer.GetPrefix())
-				if err != nil {
-					cobra.CheckErr(err)
-				}
+	return cmd, nil
+}
 
-				for k, v := range ps_ {
-					ps[k] = v
-					layer.Parameters[k] = v
-				}
-			}
+// parseInvocation loads the flags of the command from the environment, prompts for
+// parameters and parses them, either from the command line, or from the file passed
+// with --load-parameters-from-json.
+//
+// showHelp is true if the flags and arguments of the command line couldn't be parsed, as
+// opposed to failing to load them from the environment, the prompt or the JSON file.
+func (c *CobraParser) parseInvocation(inv *Invocation) (showHelp bool, err error) {
+	cmd := inv.Cmd
+	description := c.description
 
-			for k, v := range ps_ {
-				ps[k] = v
-			}
-		} else {
-			parsedLayers, ps, err = cobraParser.Parse(args)
-			// show help if there is an error
-			if err != nil {
-				fmt.Println(err)
-				err := cmd.Help()
-				cobra.CheckErr(err)
-				os.Exit(1)
-			}
-		}
+	err = c.SetFlagsFromEnv()
+	if err != nil {
+		return false, err
+	}
+
+	// prompt before handling the command flags, so that aliases and commands
+	// are created with the prompted values
+	inv.Args, err = c.PromptParameters(inv.Args)
+	if err != nil {
+		return false, err
+	}
 
-		printYAML, err := cmd.Flags().GetBool("print-yaml")
-		cobra.CheckErr(err)
+	loadParametersFromJSON, err := cmd.Flags().GetString("load-parameters-from-json")
+	if err != nil {
+		return false, err
+	}
 
-		if printYAML {
-			err = s.ToYAML(os.Stdout)
-			cobra.CheckErr(err)
-			return
+	if loadParametersFromJSON == "" {
+		parsedLayers, ps, err := c.Parse(inv.Args)
+		if err != nil {
+			return true, err
 		}
+		inv.ParsedLayers, inv.Parameters = parsedLayers, ps
+		return false, nil
+	}
 
-		createCliopatra, err := cmd.Flags().GetString("create-cliopatra")
-		cobra.CheckErr(err)
-
-		if createCliopatra != "" {
-			verbs := GetVerbsFromCobraCommand(cmd)
-			if len(verbs) == 0 {
-				cobra.CheckErr(errors.New("could not get verbs from cobra command"))
-			}
-			p := cliopatra.NewProgramFromCapture(
-				s.Description(),
-				ps,
-				cliopatra.WithVerbs(verbs[1:]...),
-				cliopatra.WithName(createCliopatra),
-				cliopatra.WithPath(verbs[0]),
-			)
-
-			// print as yaml
-			sb := strings.Builder{}
-			encoder := yaml.NewEncoder(&sb)
-			err = encoder.Encode(p)
-			cobra.CheckErr(err)
+	result := map[string]interface{}{}
+	bytes, err := os.ReadFile(loadParametersFromJSON)
+	if err != nil {
+		return false, err
+	}
+	err = json.Unmarshal(bytes, &result)
+	if err != nil {
+		return false, err
+	}
 
-			fmt.Println(sb.String())
-			os.Exit(0)
-		}
+	parsedLayers, ps, err := cmds.ParseCommandFromMap(description, result)
+	if err != nil {
+		return false, err
+	}
 
-		createCliAlias, err := cmd.Flags().GetString("create-alias")
-		cobra.CheckErr(err)
-		if createCliAlias != "" {
-			alias := &alias.CommandAlias{
-				Name:      createCliAlias,
-				AliasFor:  description.Name,
-				Arguments: args,
-				Flags:     map[string]string{},
-			}
+	// finally, load normal command line flags and arguments
+	ps_, err := GatherParametersFromCobraCommand(cmd, description, inv.Args, true, true)
+	if err != nil {
+		return false, err
+	}
 
-			cmd.Flags().Visit(func(flag *pflag.Flag) {
-				if flag.Name != "create-alias" {
-					switch flag.Value.Type() {
-					case "stringSlice":
-						slice, _ := cmd.Flags().GetStringSlice(flag.Name)
-						alias.Flags[flag.Name] = strings.Join(slice, ",")
-					case "intSlice":
-						slice, _ := cmd.Flags().GetIntSlice(flag.Name)
-						alias.Flags[flag.Name] = strings.Join(strings2.IntSliceToStringSlice(slice), ",")
-
-					case "floatSlice":
-						slice, _ := cmd.Flags().GetFloat64Slice(flag.Name)
-						alias.Flags[flag.Name] = strings.Join(strings2.Float64SliceToStringSlice(slice), ",")
-
-					default:
-						alias.Flags[flag.Name] = flag.Value.String()
-					}
-				}
-			})
+	for _, layer := range parsedLayers {
+		parameterDefinitions := layer.Layer.GetParameterDefinitions()
+		pds := []*parameters.ParameterDefinition{}
+		for _, p := range parameterDefinitions {
+			pds = append(pds, p)
+		}
 
-			// marshal alias to yaml
-			sb := strings.Builder{}
-			encoder := yaml.NewEncoder(&sb)
-			err = encoder.Encode(alias)
-			cobra.CheckErr(err)
+		ps_, err := parameters.GatherFlagsFromCobraCommand(cmd, pds, true, true, layer.Layer.GetPrefix())
+		if err != nil {
+			return false, err
+		}
 
-			fmt.Println(sb.String())
-			os.Exit(0)
+		for k, v := range ps_ {
+			ps[k] = v
+			layer.Parameters[k] = v
 		}
+	}
 
-		createNewCommand, _ := cmd.Flags().GetString("create-command")
-		// TODO(manuel, 2023-02-26) This only outputs the command description, not the actual command
-		// This is already helpful, but is really just half the story. To make this work
-		// generically, CreateNewCommand() should be part of the interface.
-		//
-		// See https://github.com/go-go-golems/glazed/issues/170
-		if createNewCommand != "" {
-			clonedArguments := []*parameters.ParameterDefinition{}
-			for _, arg := range description.Arguments {
-				newArg := arg.Copy()
-				v, ok := ps[arg.Name]
-				if ok {
-					newArg.Default = v
-				}
-				clonedArguments = append(clonedArguments, newArg)
-			}
-			clonedFlags := []*parameters.ParameterDefinition{}
-			for _, flag := range description.Flags {
-				newFlag := flag.Copy()
-				v, ok := ps[flag.Name]
-				if ok {
-					newFlag.Default = v
-				}
-				clonedFlags = append(clonedFlags, newFlag)
-			}
+	for k, v := range ps_ {
+		ps[k] = v
+	}
 
-			cmd := &cmds.CommandDescription{
-				Name:      createNewCommand,
-				Short:     description.Short,
-				Long:      description.Long,
-				Arguments: clonedArguments,
-				Flags:     clonedFlags,
-			}
+	provided := c.getProvidedParameters(inv.Args)
+	for k := range result {
+		provided[k] = true
+	}
+	err = description.CheckParameterRelations(ps, provided)
+	if err != nil {
+		return false, err
+	}
 
-			// encode as yaml
-			sb := strings.Builder{}
-			encoder := yaml.NewEncoder(&sb)
-			err = encoder.Encode(cmd)
-			cobra.CheckErr(err)
+	inv.ParsedLayers, inv.Parameters = parsedLayers, ps
+	return false, nil
+}
 
-			fmt.Println(sb.String())
-			os.Exit(0)
+// getSensitiveFlagNames returns the cobra flag names of the secret and sensitive parameters
+// of the command, including the prefix of their layer.
+func getSensitiveFlagNames(description *cmds.CommandDescription) map[string]bool {
+	ret := map[string]bool{}
+	add := func(prefix string, p *parameters.ParameterDefinition) {
+		if p.IsSensitive() {
+			ret[strings.ReplaceAll(prefix+p.Name, "_", "-")] = true
 		}
+	}
+	for _, layer := range description.Layers {
+		for _, p := range layer.GetParameterDefinitions() {
+			add(layer.GetPrefix(), p)
+		}
+	}
+	for _, p := range description.Flags {
+		add("", p)
+	}
+	return ret
+}
 
-		ctx, cancel := context.WithCancel(context.Background())
-		defer cancel()
-
-		go func() {
-			err := helpers.CancelOnSignal(ctx, os.Interrupt, cancel)
-			if err != nil && err != context.Canceled {
-				fmt.Println(err)
+// redactArguments masks the positional arguments that belong to sensitive parameters.
+// The last argument definition can be a list, and takes all the remaining arguments.
+func redactArguments(arguments []*parameters.ParameterDefinition, args []string) []string {
+	ret := []string{}
+	for i, arg := range args {
+		if len(arguments) > 0 {
+			p := arguments[len(arguments)-1]
+			if i < len(arguments) {
+				p = arguments[i]
+			}
+			if p.IsSensitive() && !parameters.IsSecretReference(arg) {
+				arg = parameters.RedactedValue
 			}
-		}()
-
-		err = run(ctx, parsedLayers, ps)
-		if _, ok := err.(*cmds.ExitWithoutGlazeError); ok {
-			os.Exit(0)
 		}
+		ret = append(ret, arg)
+	}
+	return ret
+}
 
+// printCommandSchema prints the JSON Schema of the parameters of the command, leaving
+// out the flags of the glazed-command layer which only make sense on the command line.
+func printCommandSchema(description *cmds.CommandDescription) error {
+	d := *description
+	d.Layers = []layers.ParameterLayer{}
+	for _, layer := range description.Layers {
+		if layer.GetSlug() != "glazed-command" {
+			d.Layers = append(d.Layers, layer)
+		}
 	}
 
-	return cmd, nil
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(d.ToJSONSchema())
 }
 
-func BuildCobraCommandFromBareCommand(c cmds.BareCommand) (*cobra.Command, error) {
+func BuildCobraCommandFromBareCommand(
+	c cmds.BareCommand,
+	options ...CobraParserOption,
+) (*cobra.Command, error) {
 	cmd, err := BuildCobraCommandFromCommandAndFunc(c, func(
 		ctx context.Context,
 		parsedLayers map[string]*layers.ParsedParameterLayer,
@@ -352,10 +371,10 @@ func BuildCobraCommandFromBareCommand(c cmds.BareCommand) (*cobra.Command, error
 			return nil
 		}
 		if err != context.Canceled {
-			cobra.CheckErr(err)
+			return err
 		}
 		return nil
-	})
+	}, options...)
 
 	if err != nil {
 		return nil, err
@@ -364,7 +383,10 @@ func BuildCobraCommandFromBareCommand(c cmds.BareCommand) (*cobra.Command, error
 	return cmd, nil
 }
 
-func BuildCobraCommandFromWriterCommand(s cmds.WriterCommand) (*cobra.Command, error) {
+func BuildCobraCommandFromWriterCommand(
+	s cmds.WriterCommand,
+	options ...CobraParserOption,
+) (*cobra.Command, error) {
 	cmd, err := BuildCobraCommandFromCommandAndFunc(s, func(
 		ctx context.Context,
 		parsedLayers map[string]*layers.ParsedParameterLayer,
@@ -375,10 +397,10 @@ func BuildCobraCommandFromWriterCommand(s cmds.WriterCommand) (*cobra.Command, e
 			return nil
 		}
 		if err != context.Canceled {
-			cobra.CheckErr(err)
+			return err
 		}
 		return nil
-	})
+	}, options...)
 
 	if err != nil {
 		return nil, err
@@ -387,8 +409,11 @@ func BuildCobraCommandFromWriterCommand(s cmds.WriterCommand) (*cobra.Command, e
 	return cmd, nil
 }
 
-func BuildCobraCommandAlias(alias *alias.CommandAlias) (*cobra.Command, error) {
-	cmd, err := BuildCobraCommandFromCommand(alias.AliasedCommand)
+func BuildCobraCommandAlias(
+	alias *alias.CommandAlias,
+	options ...CobraParserOption,
+) (*cobra.Command, error) {
+	cmd, err := BuildCobraCommandFromCommand(alias.AliasedCommand, options...)
 	if err != nil {
 		return nil, err
 	}
@@ -449,18 +474,21 @@ func findOrCreateParentCommand(rootCmd *cobra.Command, parents []string) *cobra.
 	return parentCmd
 }
 
-func BuildCobraCommandFromCommand(command cmds.Command) (*cobra.Command, error) {
+func BuildCobraCommandFromCommand(
+	command cmds.Command,
+	options ...CobraParserOption,
+) (*cobra.Command, error) {
 	var cobraCommand *cobra.Command
 	var err error
 	switch c := command.(type) {
 	case cmds.BareCommand:
-		cobraCommand, err = BuildCobraCommandFromBareCommand(c)
+		cobraCommand, err = BuildCobraCommandFromBareCommand(c, options...)
 
 	case cmds.WriterCommand:
-		cobraCommand, err = BuildCobraCommandFromWriterCommand(c)
+		cobraCommand, err = BuildCobraCommandFromWriterCommand(c, options...)
 
 	case cmds.GlazeCommand:
-		cobraCommand, err = BuildCobraCommandFromGlazeCommand(c)
+		cobraCommand, err = BuildCobraCommandFromGlazeCommand(c, options...)
 
 	default:
 		return nil, errors.Errorf("Unknown command type %T", c)
@@ -476,6 +504,7 @@ func AddCommandsToRootCommand(
 	rootCmd *cobra.Command,
 	commands []cmds.Command,
 	aliases []*alias.CommandAlias,
+	options ...CobraParserOption,
 ) error {
 	commandsByName := map[string]cmds.Command{}
 
@@ -484,7 +513,7 @@ func AddCommandsToRootCommand(
 		description := command.Description()
 		parentCmd := findOrCreateParentCommand(rootCmd, description.Parents)
 
-		cobraCommand, err := BuildCobraCommandFromCommand(command)
+		cobraCommand, err := BuildCobraCommandFromCommand(command, options...)
 		if err != nil {
 			log.Warn().Err(err).Str("command", description.Name).Str("source", description.Source).Msg("Could not build cobra command")
 			return nil
@@ -505,7 +534,7 @@ func AddCommandsToRootCommand(
 		alias.AliasedCommand = aliasedCommand
 
 		parentCmd := findOrCreateParentCommand(rootCmd, alias.Parents)
-		cobraCommand, err := BuildCobraCommandAlias(alias)
+		cobraCommand, err := BuildCobraCommandAlias(alias, options...)
 		if err != nil {
 			return err
 		}
@@ -529,9 +558,87 @@ func AddCommandsToRootCommand(
 type CobraParser struct {
 	Cmd         *cobra.Command
 	description *cmds.CommandDescription
+	useEnv      bool
+	envPrefix   string
+	// configAppName is the name used to discover config files, see config.FindConfigFiles
+	configAppName string
+	configFiles   []string
+	// configFlag and profileFlag are the --config and --glazed-profile flags added by the
+	// parser, nil if the command defines flags with the same names
+	configFlag  *pflag.Flag
+	profileFlag *pflag.Flag
+	// interactiveFlag is the --interactive flag added by the parser, nil if the command
+	// defines a flag with the same name
+	interactiveFlag *pflag.Flag
+	prompter        prompt.Prompter
+	autoPrompt      bool
+	prompted        bool
+	hooks           []*Hooks
+}
+
+type CobraParserOption func(*CobraParser)
+
+// parserFlagAnnotation marks the flags added by the parser that are not part of aliases.
+const parserFlagAnnotation = "glazed:parser-flag"
+
+// WithEnvPrefix enables loading the flags of the command and of all its layers from
+// environment variables named <PREFIX>_<FLAG_NAME>, see parameters.ParameterDefinition.EnvVarName.
+//
+// Environment variables take precedence over defaults and config files, but are overridden
+// by flags passed on the command line.
+func WithEnvPrefix(prefix string) CobraParserOption {
+	return func(c *CobraParser) {
+		c.useEnv = true
+		c.envPrefix = prefix
+	}
+}
+
+// WithConfig enables loading the layers and flags of the command from the config files
+// of the application appName (see config.FindConfigFiles), as well as from the file
+// passed with the --config flag, which is added to the command. The --glazed-profile flag
+// selects one of the profiles defined in the config files. If the command already has a flag
+// with one of these names, the flag is left to the command and not used to load the config.
+//
+// Config files take precedence over defaults, but are overridden by environment variables
+// and flags passed on the command line.
+func WithConfig(appName string) CobraParserOption {
+	return func(c *CobraParser) {
+		c.configAppName = appName
+	}
+}
+
+// WithConfigFiles loads the layers and flags of the command from the given config files,
+// in increasing order of precedence. When used with WithConfig, these files override the
+// discovered ones, and are overridden by the file passed with --config.
+func WithConfigFiles(files ...string) CobraParserOption {
+	return func(c *CobraParser) {
+		c.configFiles = append(c.configFiles, files...)
+	}
 }
 
-func NewCobraParserFromCommandDescription(description *cmds.CommandDescription) (*CobraParser, error) {
+// WithPrompter adds the --interactive flag to the command, which prompts for the values of
+// the flags and arguments of the command with the given prompter before parsing them.
+// Prompted values are set like flags passed on the command line. If the command already has
+// an --interactive flag, only the missing required values are prompted for (see WithAutoPrompt).
+func WithPrompter(p prompt.Prompter) CobraParserOption {
+	return func(c *CobraParser) {
+		c.prompter = p
+	}
+}
+
+// WithAutoPrompt prompts for the missing required flags and arguments, including the flags
+// of the layers, instead of failing. It is enabled by default when running on a terminal,
+// and requires a prompter, see WithPrompter.
+func WithAutoPrompt(autoPrompt bool) CobraParserOption {
+	return func(c *CobraParser) {
+		c.autoPrompt = autoPrompt
+	}
+}
+
+func NewCobraParserFromCommandDescription(
+	description *cmds.CommandDescription,
+	options ...CobraParserOption,
+) (*CobraParser, error) {
 	cmd := &cobra.Command{
 		Use:   description.Name,
 		Short: description.Short,
@@ -543,6 +650,10 @@ func NewCobraParserFromCommandDescription(description *cmds.CommandDescription)
 		description: description,
 	}
 
+	for _, o := range options {
+		o(ret)
+	}
+
 	err := parameters.AddFlagsToCobraCommand(cmd.Flags(), description.Flags, "")
 	if err != nil {
 		return nil, err
@@ -567,9 +678,243 @@ func NewCobraParserFromCommandDescription(description *cmds.CommandDescription)
 		return nil, err
 	}
 
+	layers.AddParameterRelationsToCobraCommand(cmd, description.Relations, "")
+
+	err = ret.visitParameters(func(params []*parameters.ParameterDefinition, prefix string) error {
+		return parameters.RegisterFlagCompletions(cmd, params, prefix)
+	})
+	if err != nil {
+		return nil, err
+	}
+	cmd.ValidArgsFunction = parameters.CompleteArguments(description.Arguments)
+
+	if ret.prompter != nil {
+		if cmd.Flags().Lookup("interactive") == nil {
+			cmd.Flags().Bool("interactive", false, "Prompt for the values of the flags and arguments of the command")
+			err = cmd.Flags().SetAnnotation("interactive", parserFlagAnnotation, []string{"true"})
+			if err != nil {
+				return nil, err
+			}
+			ret.interactiveFlag = cmd.Flags().Lookup("interactive")
+		}
+
+		// missing arguments are prompted for when parsing
+		validateArgs := cmd.Args
+		cmd.Args = func(cmd *cobra.Command, args []string) error {
+			err := validateArgs(cmd, args)
+			if err != nil && len(args) < len(description.Arguments) {
+				prompting, err_ := ret.isPrompting()
+				if err_ != nil {
+					return err_
+				}
+				if prompting {
+					return nil
+				}
+			}
+			return err
+		}
+	}
+
+	if ret.configAppName != "" {
+		if cmd.Flags().Lookup("config") == nil {
+			cmd.Flags().String("config", "", "Load parameters from this config file, in addition to the default ones")
+			ret.configFlag = cmd.Flags().Lookup("config")
+		}
+		if cmd.Flags().Lookup("glazed-profile") == nil {
+			cmd.Flags().String("glazed-profile", "", "Apply the settings of this profile from the config files")
+			ret.profileFlag = cmd.Flags().Lookup("glazed-profile")
+		}
+	}
+
+	if ret.useEnv {
+		err = ret.visitParameters(func(params []*parameters.ParameterDefinition, prefix string) error {
+			return parameters.AddEnvVarsToFlags(cmd.Flags(), params, ret.envPrefix, prefix)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return ret, nil
+}
+
+// visitParameters calls f with the flags of the command, and then with the parameters of each layer.
+func (c *CobraParser) visitParameters(f func(params []*parameters.ParameterDefinition, prefix string) error) error {
+	err := f(c.description.Flags, "")
+	if err != nil {
+		return err
+	}
+
+	for _, layer := range c.description.Layers {
+		params := []*parameters.ParameterDefinition{}
+		for _, p := range layer.GetParameterDefinitions() {
+			params = append(params, p)
+		}
+		err = f(params, layer.GetPrefix())
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// LoadConfig loads the config files of the command, as configured with WithConfig and
+// WithConfigFiles. It returns nil if no config is used.
+func (c *CobraParser) LoadConfig() (*config.Config, error) {
+	if c.configAppName == "" && len(c.configFiles) == 0 {
+		return nil, nil
+	}
+
+	if c.configAppName == "" {
+		return config.NewConfigFromFiles(c.configFiles...)
+	}
+
+	files := append([]string{}, c.configFiles...)
+	if c.configFlag != nil && c.configFlag.Value.String() != "" {
+		files = append(files, c.configFlag.Value.String())
+	}
+
+	return config.LoadAppConfig(c.configAppName, files...)
+}
+
+// applyConfigValues overrides the values of ps with the values loaded from config files,
+// unless the corresponding flag was set on the command line or from the environment.
+func (c *CobraParser) applyConfigValues(ps map[string]interface{}, values map[string]interface{}, prefix string) {
+	for k, v := range values {
+		flagName := strings.ReplaceAll(prefix+k, "_", "-")
+		if c.Cmd.Flags().Changed(flagName) {
+			continue
+		}
+		ps[k] = v
+	}
+}
+
+// SetFlagsFromEnv sets the flags that were not passed on the command line from their
+// environment variables, if the parser was created using WithEnvPrefix.
+// It is called by Parse, and only needs to be called explicitly when reading the cobra
+// flags directly.
+func (c *CobraParser) SetFlagsFromEnv() error {
+	if !c.useEnv {
+		return nil
+	}
+
+	return c.visitParameters(func(params []*parameters.ParameterDefinition, prefix string) error {
+		return parameters.SetFlagsFromEnv(c.Cmd.Flags(), params, c.envPrefix, prefix)
+	})
+}
+
+// isPrompting returns true if the parser prompts for missing values, either because the
+// --interactive flag was passed or because auto prompting is enabled.
+func (c *CobraParser) isPrompting() (bool, error) {
+	if c.prompter == nil {
+		return false, nil
+	}
+	if c.autoPrompt {
+		return true, nil
+	}
+	return c.isInteractive()
+}
+
+func (c *CobraParser) isInteractive() (bool, error) {
+	if c.prompter == nil || c.interactiveFlag == nil {
+		return false, nil
+	}
+	return strconv.ParseBool(c.interactiveFlag.Value.String())
+}
+
+// PromptParameters prompts for the flags and arguments of the command that were not passed on
+// the command line nor set from the environment, and sets the flags from the answers.
+// It returns args completed with the prompted arguments.
+//
+// With --interactive, all the flags and arguments of the command are prompted for, as well
+// as the required flags of the layers. Otherwise, only the missing required flags and arguments
+// are prompted for, if auto prompting is enabled. It is called by Parse, and only prompts once.
+func (c *CobraParser) PromptParameters(args []string) ([]string, error) {
+	if c.prompted {
+		return args, nil
+	}
+	c.prompted = true
+
+	all, err := c.isInteractive()
+	if err != nil {
+		return nil, err
+	}
+	prompting, err := c.isPrompting()
+	if err != nil || !prompting {
+		return args, err
+	}
+
+	flags := c.Cmd.Flags()
+	promptFlags := func(params []*parameters.ParameterDefinition, prefix string, all bool) error {
+		for _, p := range params {
+			flagName := strings.ReplaceAll(prefix+p.Name, "_", "-")
+			if flags.Changed(flagName) || !(all || p.Required) {
+				continue
+			}
+			answer, err := prompt.AskParameter(c.prompter, p, prefix)
+			if err != nil {
+				return errors.Wrapf(err, "could not prompt for %s", flagName)
+			}
+			if answer == nil {
+				continue
+			}
+			err = flags.Set(flagName, strings.Join(answer, ","))
+			if err != nil {
+				return errors.Wrapf(err, "invalid value for %s", flagName)
+			}
+		}
+		return nil
+	}
+
+	err = promptFlags(c.description.Flags, "", all)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, layer := range c.description.Layers {
+		pds := layer.GetParameterDefinitions()
+		params := []*parameters.ParameterDefinition{}
+		for _, name := range sortedParameterNames(pds) {
+			params = append(params, pds[name])
+		}
+		err = promptFlags(params, layer.GetPrefix(), false)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	ret := append([]string{}, args...)
+	for i, p := range c.description.Arguments {
+		if i < len(args) {
+			continue
+		}
+		if !(all || p.Required) {
+			break
+		}
+		answer, err := prompt.AskParameter(c.prompter, p, "")
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not prompt for %s", p.Name)
+		}
+		// arguments are positional, the following ones can't be passed without this one
+		if answer == nil {
+			break
+		}
+		ret = append(ret, answer...)
+	}
+
 	return ret, nil
 }
 
+func sortedParameterNames(pds map[string]*parameters.ParameterDefinition) []string {
+	ret := []string{}
+	for name := range pds {
+		ret = append(ret, name)
+	}
+	sort.Strings(ret)
+	return ret
+}
+
 type CobraParameterLayer interface {
 	layers.ParameterLayer
 	// AddFlagsToCobraCommand adds all the flags defined in this layer to the given cobra command.
@@ -611,6 +956,33 @@ func (c *CobraParser) Parse(args []string) (map[string]*layers.ParsedParameterLa
 	parsedLayers := map[string]*layers.ParsedParameterLayer{}
 	ps := map[string]interface{}{}
 
+	err := c.SetFlagsFromEnv()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	args, err = c.PromptParameters(args)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	configLayers := map[string]*layers.ParsedParameterLayer{}
+	configFlags := map[string]interface{}{}
+	config_, err := c.LoadConfig()
+	if err != nil {
+		return nil, nil, err
+	}
+	if config_ != nil {
+		options := []config.ParseOption{}
+		if c.profileFlag != nil && c.profileFlag.Value.String() != "" {
+			options = append(options, config.WithProfile(c.profileFlag.Value.String()))
+		}
+		configLayers, configFlags, err = config_.ParseCommand(c.description, options...)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
 	for _, layer := range c.description.Layers {
 		cobraLayer, ok := layer.(CobraParameterLayer)
 		if !ok {
@@ -622,6 +994,9 @@ func (c *CobraParser) Parse(args []string) (map[string]*layers.ParsedParameterLa
 		if err != nil {
 			return nil, nil, err
 		}
+		if configLayer, ok := configLayers[layer.GetSlug()]; ok {
+			c.applyConfigValues(ps_, configLayer.Parameters, layer.GetPrefix())
+		}
 
 		parsedLayer := &layers.ParsedParameterLayer{Parameters: ps_, Layer: layer}
 		parsedLayers[layer.GetSlug()] = parsedLayer
@@ -647,14 +1022,53 @@ func (c *CobraParser) Parse(args []string) (map[string]*layers.ParsedParameterLa
 	if err != nil {
 		return nil, nil, err
 	}
+	c.applyConfigValues(ps_, configFlags, "")
 
 	for k, v := range ps_ {
 		ps[k] = v
 	}
 
+	// values from config files are explicitly provided as well
+	provided := c.getProvidedParameters(args)
+	for _, configLayer := range configLayers {
+		for k := range configLayer.Parameters {
+			provided[k] = true
+		}
+	}
+	for k := range configFlags {
+		provided[k] = true
+	}
+	err = c.description.CheckParameterRelations(ps, provided)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	return parsedLayers, ps, nil
 }
 
+// getProvidedParameters returns the names of the parameters whose flags were changed,
+// on the command line, from the environment or by prompting, and of the arguments
+// given in args.
+func (c *CobraParser) getProvidedParameters(args []string) map[string]bool {
+	ret := map[string]bool{}
+	_ = c.visitParameters(func(params []*parameters.ParameterDefinition, prefix string) error {
+		for _, p := range params {
+			if c.Cmd.Flags().Changed(strings.ReplaceAll(prefix+p.Name, "_", "-")) {
+				ret[p.Name] = true
+			}
+		}
+		return nil
+	})
+
+	for i := range c.description.Arguments {
+		if i < len(args) {
+			ret[c.description.Arguments[i].Name] = true
+		}
+	}
+
+	return ret
+}
+
 // CreateGlazedProcessorFromCobra is a helper for cobra centric apps that quickly want to add
 // the glazed processing layer.
 //
@@ -694,32 +1108,43 @@ func AddGlazedProcessorFlagsToCobraCommand(cmd *cobra.Command, options ...settin
 	return gpl.AddFlagsToCobraCommand(cmd)
 }
 
-func BuildCobraCommandFromGlazeCommand(cmd_ cmds.GlazeCommand) (*cobra.Command, error) {
+func BuildCobraCommandFromGlazeCommand(
+	cmd_ cmds.GlazeCommand,
+	options ...CobraParserOption,
+) (*cobra.Command, error) {
+	var cmd *cobra.Command
 	cmd, err := BuildCobraCommandFromCommandAndFunc(cmd_, func(
 		ctx context.Context,
 		parsedLayers map[string]*layers.ParsedParameterLayer,
 		ps map[string]interface{},
 	) error {
 		gp, err := settings.SetupTableProcessor(ps)
-		cobra.CheckErr(err)
+		if err != nil {
+			return err
+		}
+
+		// record the columns output by the command, to complete --fields and --sort-by
+		cache, err := completion.NewColumnCacheForCommand(cmd)
+		if err == nil {
+			gp.AddRowMiddlewareInFront(completion.NewColumnCacheMiddleware(cache, completion.GetCommandPath(cmd)))
+		}
 
 		_, err = settings.SetupProcessorOutput(gp, ps, os.Stdout)
-		cobra.CheckErr(err)
+		if err != nil {
+			return err
+		}
 
 		err = cmd_.Run(ctx, parsedLayers, ps, gp)
 		if _, ok := err.(*cmds.ExitWithoutGlazeError); ok {
 			return nil
 		}
-		if err != context.Canceled {
-			cobra.CheckErr(err)
+		if err != nil && err != context.Canceled {
+			return err
 		}
 
 		// Close will run the TableMiddlewares
-		err = gp.Close(ctx)
-		cobra.CheckErr(err)
-
-		return nil
-	})
+		return gp.Close(ctx)
+	}, options...)
 
 	if err != nil {
 		return nil, err
@@ -0,0 +1,143 @@
+package cli
+
+import (
+	"context"
+	"github.com/go-go-golems/glazed/pkg/cmds"
+	"github.com/go-go-golems/glazed/pkg/cmds/layers"
+	"github.com/go-go-golems/glazed/pkg/cmds/parameters"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zenizh/go-capturer"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type captureCommand struct {
+	*cmds.CommandDescription
+	ps map[string]interface{}
+}
+
+func (c *captureCommand) Run(
+	ctx context.Context,
+	parsedLayers map[string]*layers.ParsedParameterLayer,
+	ps map[string]interface{},
+) error {
+	c.ps = ps
+	return nil
+}
+
+func TestConfigFlagsDefinedByTheCommand(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_DIRS", filepath.Join(dir, "etc"))
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(dir, "home"))
+	configFile := filepath.Join(dir, "config.yaml")
+	require.NoError(t, os.WriteFile(configFile, []byte("commands:\n  capture:\n    count: 5\n"), 0644))
+
+	c := &captureCommand{
+		CommandDescription: cmds.NewCommandDescription("capture",
+			cmds.WithFlags(
+				parameters.NewParameterDefinition("count", parameters.ParameterTypeInteger, parameters.WithDefault(1)),
+				// the command's own flags are not used to load the config
+				parameters.NewParameterDefinition("config", parameters.ParameterTypeInteger),
+				parameters.NewParameterDefinition("glazed-profile", parameters.ParameterTypeInteger),
+			),
+		),
+	}
+	cmd, err := BuildCobraCommandFromBareCommand(c,
+		WithConfig("app"),
+		WithConfigFiles(configFile),
+		WithAutoPrompt(false),
+	)
+	require.NoError(t, err)
+
+	root := &cobra.Command{Use: "app"}
+	root.AddCommand(cmd)
+	root.SetArgs([]string{"capture", "--config", "3", "--glazed-profile", "4"})
+	require.NoError(t, root.Execute())
+
+	assert.Equal(t, 5, c.ps["count"])
+	assert.Equal(t, 3, c.ps["config"])
+	assert.Equal(t, 4, c.ps["glazed-profile"])
+}
+
+func TestInteractiveFlagDefinedByTheCommand(t *testing.T) {
+	c := &captureCommand{
+		CommandDescription: cmds.NewCommandDescription("capture",
+			cmds.WithFlags(
+				parameters.NewParameterDefinition("interactive", parameters.ParameterTypeString),
+			),
+		),
+	}
+	cmd, err := BuildCobraCommandFromBareCommand(c, WithAutoPrompt(false))
+	require.NoError(t, err)
+
+	root := &cobra.Command{Use: "app"}
+	root.AddCommand(cmd)
+	root.SetArgs([]string{"capture", "--interactive", "always"})
+	require.NoError(t, root.Execute())
+
+	assert.Equal(t, "always", c.ps["interactive"])
+}
+
+// newBaselineGlazedCommandLayer returns a glazed-command layer with the flags that commands
+// bringing their own layer have, without --print-schema and --reveal-secrets.
+func newBaselineGlazedCommandLayer(t *testing.T) layers.ParameterLayer {
+	layer, err := layers.NewParameterLayer("glazed-command", "General purpose Command options",
+		layers.WithFlags(
+			parameters.NewParameterDefinition("create-command", parameters.ParameterTypeString),
+			parameters.NewParameterDefinition("create-alias", parameters.ParameterTypeString),
+			parameters.NewParameterDefinition("create-cliopatra", parameters.ParameterTypeString),
+			parameters.NewParameterDefinition("print-yaml", parameters.ParameterTypeBool),
+			parameters.NewParameterDefinition("load-parameters-from-json", parameters.ParameterTypeString),
+		),
+	)
+	require.NoError(t, err)
+	return layer
+}
+
+func TestCustomGlazedCommandLayer(t *testing.T) {
+	c := &captureCommand{
+		CommandDescription: cmds.NewCommandDescription("capture",
+			cmds.WithFlags(
+				parameters.NewParameterDefinition("count", parameters.ParameterTypeInteger, parameters.WithDefault(1)),
+			),
+			cmds.WithLayers(newBaselineGlazedCommandLayer(t)),
+		),
+	}
+	cmd, err := BuildCobraCommandFromBareCommand(c, WithAutoPrompt(false))
+	require.NoError(t, err)
+
+	root := &cobra.Command{Use: "app"}
+	root.AddCommand(cmd)
+	root.SetArgs([]string{"capture", "--count", "2"})
+	require.NoError(t, root.Execute())
+
+	assert.Equal(t, 2, c.ps["count"])
+}
+
+func TestCustomGlazedCommandLayerMasksSecrets(t *testing.T) {
+	c := &captureCommand{
+		CommandDescription: cmds.NewCommandDescription("capture",
+			cmds.WithFlags(
+				parameters.NewParameterDefinition("token", parameters.ParameterTypeSecret),
+			),
+			cmds.WithLayers(newBaselineGlazedCommandLayer(t)),
+		),
+	}
+	cmd, err := BuildCobraCommandFromBareCommand(c, WithAutoPrompt(false))
+	require.NoError(t, err)
+
+	root := &cobra.Command{Use: "app"}
+	root.AddCommand(cmd)
+	root.SetArgs([]string{"capture", "--token", "s3cret", "--create-alias", "short"})
+	out := capturer.CaptureStdout(func() {
+		require.NoError(t, root.Execute())
+	})
+
+	assert.Contains(t, out, "name: short")
+	assert.Contains(t, out, parameters.RedactedValue)
+	assert.NotContains(t, out, "s3cret")
+	assert.Nil(t, c.ps)
+}
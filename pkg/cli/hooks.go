@@ -0,0 +1,397 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"github.com/go-go-golems/glazed/pkg/cli/cliopatra"
+	"github.com/go-go-golems/glazed/pkg/cmds"
+	"github.com/go-go-golems/glazed/pkg/cmds/alias"
+	"github.com/go-go-golems/glazed/pkg/cmds/layers"
+	"github.com/go-go-golems/glazed/pkg/cmds/parameters"
+	strings2 "github.com/go-go-golems/glazed/pkg/helpers/strings"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v3"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Invocation describes a single execution of a command built with
+// BuildCobraCommandFromCommandAndFunc, and is passed to its hooks.
+type Invocation struct {
+	Cmd     *cobra.Command
+	Command cmds.Command
+	// Args are the positional arguments of the command line, including the prompted ones
+	// once the parameters have been parsed.
+	Args []string
+	// ParsedLayers and Parameters are set once the parameters have been parsed. Hooks can
+	// modify them before the command is run.
+	ParsedLayers map[string]*layers.ParsedParameterLayer
+	Parameters   map[string]interface{}
+	// Err is the error returned by the command, set before the AfterRun hooks are called.
+	Err error
+}
+
+// Hook is called at one of the stages of the execution of a command. Returning an error
+// aborts the execution, and returning cmds.ExitWithoutGlazeError stops it without error.
+type Hook func(ctx context.Context, inv *Invocation) error
+
+// ErrorHook is called with the error that aborted the execution of a command, and returns
+// the error to report, or nil to ignore it.
+type ErrorHook func(ctx context.Context, inv *Invocation, err error) error
+
+// Hooks are called around the execution of a command, in the following order:
+//
+//   - BeforeParse, before the parameters are loaded from the environment, prompted and parsed
+//   - AfterParse, once the parsed layers and parameters are set on the Invocation
+//   - BeforeRun, right before the command is run
+//   - AfterRun, once the command has returned, whether it failed or not
+//   - OnError, if any of the previous stages failed
+//
+// The flags of the glazed-command layer, such as --print-yaml or --create-alias, are
+// implemented as BeforeParse and AfterParse hooks that stop the execution. They are
+// called before all the other hooks.
+type Hooks struct {
+	BeforeParse []Hook
+	AfterParse  []Hook
+	BeforeRun   []Hook
+	AfterRun    []Hook
+	OnError     []ErrorHook
+}
+
+var (
+	hooksMutex sync.Mutex
+	// commandHooks holds the hooks registered with AddHooks, by cobra command.
+	commandHooks = map[*cobra.Command][]*Hooks{}
+)
+
+// AddHooks registers hooks around the execution of cmd and of all its subcommands.
+// Hooks registered on the root command apply to all the commands of its command tree.
+func AddHooks(cmd *cobra.Command, hooks ...*Hooks) {
+	hooksMutex.Lock()
+	defer hooksMutex.Unlock()
+	commandHooks[cmd] = append(commandHooks[cmd], hooks...)
+}
+
+// GetHooks returns the hooks registered with AddHooks on cmd and its parents, from the
+// root command down to cmd.
+func GetHooks(cmd *cobra.Command) []*Hooks {
+	hooksMutex.Lock()
+	defer hooksMutex.Unlock()
+
+	ret := []*Hooks{}
+	for c := cmd; c != nil; c = c.Parent() {
+		ret = append(append([]*Hooks{}, commandHooks[c]...), ret...)
+	}
+	return ret
+}
+
+// executeWithHooks parses and runs the invocation, calling the hooks around each stage.
+// Before hooks are called from the outermost to the innermost hooks, after and error hooks
+// the other way around.
+func executeWithHooks(
+	ctx context.Context,
+	hooks []*Hooks,
+	inv *Invocation,
+	parse func(ctx context.Context, inv *Invocation) error,
+	run CobraRunFunc,
+) error {
+	err := execute(ctx, hooks, inv, parse, run)
+	if _, ok := err.(*cmds.ExitWithoutGlazeError); ok {
+		return nil
+	}
+	for i := len(hooks) - 1; i >= 0 && err != nil; i-- {
+		for _, h := range hooks[i].OnError {
+			err = h(ctx, inv, err)
+			if err == nil {
+				break
+			}
+		}
+	}
+	return err
+}
+
+func execute(
+	ctx context.Context,
+	hooks []*Hooks,
+	inv *Invocation,
+	parse func(ctx context.Context, inv *Invocation) error,
+	run CobraRunFunc,
+) error {
+	callHooks := func(get func(h *Hooks) []Hook) error {
+		for _, h := range hooks {
+			for _, hook := range get(h) {
+				err := hook(ctx, inv)
+				if err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+
+	err := callHooks(func(h *Hooks) []Hook { return h.BeforeParse })
+	if err != nil {
+		return err
+	}
+	err = parse(ctx, inv)
+	if err != nil {
+		return err
+	}
+	err = callHooks(func(h *Hooks) []Hook { return h.AfterParse })
+	if err != nil {
+		return err
+	}
+	err = callHooks(func(h *Hooks) []Hook { return h.BeforeRun })
+	if err != nil {
+		return err
+	}
+
+	inv.Err = run(ctx, inv.ParsedLayers, inv.Parameters)
+	if _, ok := inv.Err.(*cmds.ExitWithoutGlazeError); ok {
+		inv.Err = nil
+	}
+
+	err = inv.Err
+	for i := len(hooks) - 1; i >= 0; i-- {
+		for _, hook := range hooks[i].AfterRun {
+			err_ := hook(ctx, inv)
+			if err == nil {
+				err = err_
+			}
+		}
+	}
+	return err
+}
+
+// WithHooks registers hooks around the execution of the command, see Hooks.
+// They are called after the hooks of the glazed-command flags and the hooks registered
+// on the command and its parents with AddHooks.
+func WithHooks(hooks ...*Hooks) CobraParserOption {
+	return func(c *CobraParser) {
+		c.hooks = append(c.hooks, hooks...)
+	}
+}
+
+// glazedCommandHooks implements the flags of the glazed-command layer.
+func glazedCommandHooks() *Hooks {
+	return &Hooks{
+		BeforeParse: []Hook{printSchemaHook},
+		AfterParse: []Hook{
+			printYAMLHook,
+			createCliopatraHook,
+			createAliasHook,
+			createCommandHook,
+		},
+	}
+}
+
+// getOptionalBoolFlag returns the value of a boolean flag of the glazed-command layer that
+// is missing from the layers that commands bring themselves, in which case it is false.
+func getOptionalBoolFlag(cmd *cobra.Command, name string) (bool, error) {
+	if cmd.Flags().Lookup(name) == nil {
+		return false, nil
+	}
+	return cmd.Flags().GetBool(name)
+}
+
+func printSchemaHook(ctx context.Context, inv *Invocation) error {
+	printSchema, err := getOptionalBoolFlag(inv.Cmd, "print-schema")
+	if err != nil {
+		return err
+	}
+	if !printSchema {
+		return nil
+	}
+
+	err = printCommandSchema(inv.Command.Description())
+	if err != nil {
+		return err
+	}
+	return &cmds.ExitWithoutGlazeError{}
+}
+
+func printYAMLHook(ctx context.Context, inv *Invocation) error {
+	printYAML, err := inv.Cmd.Flags().GetBool("print-yaml")
+	if err != nil {
+		return err
+	}
+	if !printYAML {
+		return nil
+	}
+
+	err = inv.Command.ToYAML(os.Stdout)
+	if err != nil {
+		return err
+	}
+	return &cmds.ExitWithoutGlazeError{}
+}
+
+// getCapturedParameters returns the parameters to output when creating aliases, commands
+// and cliopatra programs. The values of secret and sensitive parameters are masked,
+// unless explicitly asked otherwise.
+func getCapturedParameters(inv *Invocation) (map[string]interface{}, bool, error) {
+	// secrets stay masked if the command has no --reveal-secrets flag
+	revealSecrets, err := getOptionalBoolFlag(inv.Cmd, "reveal-secrets")
+	if err != nil {
+		return nil, false, err
+	}
+	if revealSecrets {
+		pds := inv.Command.Description().GetAllParameterDefinitions()
+		return parameters.RevealParameters(pds, inv.Parameters), true, nil
+	}
+	return inv.Parameters, false, nil
+}
+
+// printYAML prints v as YAML and stops the execution of the command.
+func printYAML(v interface{}) error {
+	sb := strings.Builder{}
+	encoder := yaml.NewEncoder(&sb)
+	err := encoder.Encode(v)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(sb.String())
+	return &cmds.ExitWithoutGlazeError{}
+}
+
+func createCliopatraHook(ctx context.Context, inv *Invocation) error {
+	createCliopatra, err := inv.Cmd.Flags().GetString("create-cliopatra")
+	if err != nil {
+		return err
+	}
+	if createCliopatra == "" {
+		return nil
+	}
+
+	capturedPs, _, err := getCapturedParameters(inv)
+	if err != nil {
+		return err
+	}
+
+	verbs := GetVerbsFromCobraCommand(inv.Cmd)
+	if len(verbs) == 0 {
+		return errors.New("could not get verbs from cobra command")
+	}
+	p := cliopatra.NewProgramFromCapture(
+		inv.Command.Description(),
+		capturedPs,
+		cliopatra.WithVerbs(verbs[1:]...),
+		cliopatra.WithName(createCliopatra),
+		cliopatra.WithPath(verbs[0]),
+	)
+
+	return printYAML(p)
+}
+
+func createAliasHook(ctx context.Context, inv *Invocation) error {
+	cmd := inv.Cmd
+	createCliAlias, err := cmd.Flags().GetString("create-alias")
+	if err != nil {
+		return err
+	}
+	if createCliAlias == "" {
+		return nil
+	}
+
+	_, revealSecrets, err := getCapturedParameters(inv)
+	if err != nil {
+		return err
+	}
+
+	description := inv.Command.Description()
+	aliasArguments := inv.Args
+	if !revealSecrets {
+		aliasArguments = redactArguments(description.Arguments, inv.Args)
+	}
+	alias := &alias.CommandAlias{
+		Name:      createCliAlias,
+		AliasFor:  description.Name,
+		Arguments: aliasArguments,
+		Flags:     map[string]string{},
+	}
+
+	sensitiveFlags := getSensitiveFlagNames(description)
+
+	cmd.Flags().Visit(func(flag *pflag.Flag) {
+		// values loaded from the environment are not part of the alias
+		if _, ok := flag.Annotations[parameters.EnvSourceAnnotation]; ok {
+			return
+		}
+		if _, ok := flag.Annotations[parserFlagAnnotation]; ok {
+			return
+		}
+		if flag.Name != "create-alias" && flag.Name != "reveal-secrets" {
+			switch flag.Value.Type() {
+			case "stringSlice":
+				slice, _ := cmd.Flags().GetStringSlice(flag.Name)
+				alias.Flags[flag.Name] = strings.Join(slice, ",")
+			case "intSlice":
+				slice, _ := cmd.Flags().GetIntSlice(flag.Name)
+				alias.Flags[flag.Name] = strings.Join(strings2.IntSliceToStringSlice(slice), ",")
+
+			case "floatSlice":
+				slice, _ := cmd.Flags().GetFloat64Slice(flag.Name)
+				alias.Flags[flag.Name] = strings.Join(strings2.Float64SliceToStringSlice(slice), ",")
+
+			default:
+				alias.Flags[flag.Name] = flag.Value.String()
+			}
+
+			if sensitiveFlags[flag.Name] && !revealSecrets &&
+				!parameters.IsSecretReference(alias.Flags[flag.Name]) {
+				alias.Flags[flag.Name] = parameters.RedactedValue
+			}
+		}
+	})
+
+	return printYAML(alias)
+}
+
+func createCommandHook(ctx context.Context, inv *Invocation) error {
+	createNewCommand, _ := inv.Cmd.Flags().GetString("create-command")
+	// TODO(manuel, 2023-02-26) This only outputs the command description, not the actual command
+	// This is already helpful, but is really just half the story. To make this work
+	// generically, CreateNewCommand() should be part of the interface.
+	//
+	// See https://github.com/go-go-golems/glazed/issues/170
+	if createNewCommand == "" {
+		return nil
+	}
+
+	capturedPs, _, err := getCapturedParameters(inv)
+	if err != nil {
+		return err
+	}
+
+	description := inv.Command.Description()
+	clonedArguments := []*parameters.ParameterDefinition{}
+	for _, arg := range description.Arguments {
+		newArg := arg.Copy()
+		v, ok := capturedPs[arg.Name]
+		if ok {
+			newArg.Default = v
+		}
+		clonedArguments = append(clonedArguments, newArg)
+	}
+	clonedFlags := []*parameters.ParameterDefinition{}
+	for _, flag := range description.Flags {
+		newFlag := flag.Copy()
+		v, ok := capturedPs[flag.Name]
+		if ok {
+			newFlag.Default = v
+		}
+		clonedFlags = append(clonedFlags, newFlag)
+	}
+
+	return printYAML(&cmds.CommandDescription{
+		Name:      createNewCommand,
+		Short:     description.Short,
+		Long:      description.Long,
+		Arguments: clonedArguments,
+		Flags:     clonedFlags,
+	})
+}
@@ -0,0 +1,162 @@
+package cli
+
+import (
+	"context"
+	"github.com/go-go-golems/glazed/pkg/cmds"
+	"github.com/go-go-golems/glazed/pkg/cmds/layers"
+	"github.com/go-go-golems/glazed/pkg/cmds/parameters"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+type countCommand struct {
+	*cmds.CommandDescription
+	count int
+	err   error
+}
+
+func (c *countCommand) Run(
+	ctx context.Context,
+	parsedLayers map[string]*layers.ParsedParameterLayer,
+	ps map[string]interface{},
+) error {
+	c.count = ps["count"].(int)
+	return c.err
+}
+
+// recordHooks returns hooks that append the stages they are called at to calls.
+func recordHooks(name string, calls *[]string) *Hooks {
+	record := func(stage string) Hook {
+		return func(ctx context.Context, inv *Invocation) error {
+			*calls = append(*calls, name+":"+stage)
+			return nil
+		}
+	}
+	return &Hooks{
+		BeforeParse: []Hook{record("before-parse")},
+		AfterParse:  []Hook{record("after-parse")},
+		BeforeRun:   []Hook{record("before-run")},
+		AfterRun:    []Hook{record("after-run")},
+		OnError: []ErrorHook{func(ctx context.Context, inv *Invocation, err error) error {
+			*calls = append(*calls, name+":on-error")
+			return err
+		}},
+	}
+}
+
+func newCountCommand(t *testing.T, hooks ...*Hooks) (*countCommand, *cobra.Command) {
+	c := &countCommand{
+		CommandDescription: cmds.NewCommandDescription("count",
+			cmds.WithFlags(
+				parameters.NewParameterDefinition("count", parameters.ParameterTypeInteger, parameters.WithDefault(1)),
+			),
+		),
+	}
+	cmd, err := BuildCobraCommandFromBareCommand(c, WithAutoPrompt(false), WithHooks(hooks...))
+	require.NoError(t, err)
+
+	root := &cobra.Command{Use: "app"}
+	root.AddCommand(cmd)
+	return c, root
+}
+
+func TestHooksOrder(t *testing.T) {
+	calls := []string{}
+	c, root := newCountCommand(t, recordHooks("command", &calls))
+	AddHooks(root, recordHooks("root", &calls))
+
+	root.SetArgs([]string{"count", "--count", "2"})
+	require.NoError(t, root.Execute())
+
+	assert.Equal(t, 2, c.count)
+	assert.Equal(t, []string{
+		"root:before-parse",
+		"command:before-parse",
+		"root:after-parse",
+		"command:after-parse",
+		"root:before-run",
+		"command:before-run",
+		"command:after-run",
+		"root:after-run",
+	}, calls)
+}
+
+func TestHooksModifyParameters(t *testing.T) {
+	c, root := newCountCommand(t, &Hooks{
+		AfterParse: []Hook{func(ctx context.Context, inv *Invocation) error {
+			inv.Parameters["count"] = inv.Parameters["count"].(int) * 10
+			return nil
+		}},
+	})
+
+	root.SetArgs([]string{"count", "--count", "3"})
+	require.NoError(t, root.Execute())
+	assert.Equal(t, 30, c.count)
+}
+
+func TestHooksStopAndErrors(t *testing.T) {
+	c, root := newCountCommand(t, &Hooks{
+		BeforeRun: []Hook{func(ctx context.Context, inv *Invocation) error {
+			return &cmds.ExitWithoutGlazeError{}
+		}},
+	})
+	root.SetArgs([]string{"count", "--count", "3"})
+	require.NoError(t, root.Execute())
+	assert.Equal(t, 0, c.count)
+
+	calls := []string{}
+	var runErr error
+	c, root = newCountCommand(t,
+		&Hooks{
+			AfterRun: []Hook{func(ctx context.Context, inv *Invocation) error {
+				runErr = inv.Err
+				return nil
+			}},
+			// the error is ignored, and cobra doesn't exit
+			OnError: []ErrorHook{func(ctx context.Context, inv *Invocation, err error) error {
+				return nil
+			}},
+		},
+		recordHooks("command", &calls),
+	)
+	c.err = errors.New("failed")
+	root.SetArgs([]string{"count"})
+	require.NoError(t, root.Execute())
+
+	assert.EqualError(t, runErr, "failed")
+	// error hooks are called from the innermost to the outermost hooks
+	assert.Contains(t, calls, "command:on-error")
+}
+
+func TestHooksAreRegisteredPerCommand(t *testing.T) {
+	calls := []string{}
+	c, root := newCountCommand(t)
+	AddHooks(root, recordHooks("root", &calls))
+	_, other := newCountCommand(t)
+
+	// the hooks of another command tree are not called
+	other.SetArgs([]string{"count"})
+	require.NoError(t, other.Execute())
+	assert.Empty(t, calls)
+	assert.Len(t, GetHooks(root.Commands()[0]), 1)
+	assert.Empty(t, GetHooks(other.Commands()[0]))
+
+	root.SetArgs([]string{"count", "--count", "2"})
+	require.NoError(t, root.Execute())
+	assert.Len(t, calls, 4)
+	assert.Equal(t, 2, c.count)
+}
+
+func TestGlazedCommandHooksFirst(t *testing.T) {
+	calls := []string{}
+	c, root := newCountCommand(t, recordHooks("command", &calls))
+
+	// --print-yaml stops the execution before the after-parse hooks of the command
+	root.SetArgs([]string{"count", "--print-yaml"})
+	require.NoError(t, root.Execute())
+	assert.Equal(t, []string{"command:before-parse"}, calls)
+	assert.Equal(t, 0, c.count)
+}
@@ -0,0 +1,141 @@
+package prompt
+
+import (
+	"fmt"
+	"github.com/go-go-golems/glazed/pkg/cmds/parameters"
+	"github.com/pkg/errors"
+	"strconv"
+	"strings"
+)
+
+// splitAnswer returns the answer as the list of arguments that would be passed on the
+// command line. The values of list parameters are separated by commas.
+func splitAnswer(p *parameters.ParameterDefinition, answer string) []string {
+	if !parameters.IsListParameter(p.Type) {
+		return []string{answer}
+	}
+	ret := []string{}
+	for _, s := range strings.Split(answer, ",") {
+		s = strings.TrimSpace(s)
+		if s != "" {
+			ret = append(ret, s)
+		}
+	}
+	return ret
+}
+
+// renderDefault renders the default value of the parameter, or returns the empty string
+// if it has none. The default values of secrets are masked.
+func renderDefault(p *parameters.ParameterDefinition) string {
+	if p.Default == nil {
+		return ""
+	}
+	s, err := parameters.RenderValue(p.Type, p.Default)
+	if err != nil {
+		return fmt.Sprintf("%v", p.Default)
+	}
+	if p.IsSensitive() && s != "" {
+		return parameters.RedactedValue
+	}
+	return s
+}
+
+// validateAnswer parses the answer like a value passed on the command line,
+// and checks it against the constraints of the parameter.
+func validateAnswer(p *parameters.ParameterDefinition, answer string) error {
+	if answer == "" {
+		if p.Required {
+			return errors.New("a value is required")
+		}
+		return nil
+	}
+	v, err := p.ParseParameter(splitAnswer(p, answer))
+	if err != nil {
+		return err
+	}
+	return p.CheckValueConstraints(v)
+}
+
+// AskParameter prompts the user for the value of the parameter, with an input suited to its type:
+// booleans are confirmed, choices are selected and choice lists are multi-selected. The values of
+// the other types are typed in (lists separated by commas) and validated like command line values.
+// Secret and sensitive values are typed in without being shown.
+// The default value of the parameter is pre-filled, and its help is shown next to the question.
+//
+// The prefix is prepended to the name of the parameter shown to the user, like for layer flags.
+//
+// The answer is returned as the list of arguments that would be passed on the command line.
+// If the user keeps the default value of an optional parameter or skips it, nil is returned
+// so that the value of the parameter is left to the other sources (defaults, config files).
+func AskParameter(p Prompter, param *parameters.ParameterDefinition, prefix string) ([]string, error) {
+	defaultValue := renderDefault(param)
+	q := &Question{
+		Label:   prefix + param.Name,
+		Help:    param.Help,
+		Default: defaultValue,
+		Validate: func(answer string) error {
+			if answer == defaultValue && answer != "" {
+				return nil
+			}
+			return validateAnswer(param, answer)
+		},
+	}
+
+	var answer string
+	switch param.Type {
+	case parameters.ParameterTypeBool:
+		if q.Default == "" {
+			q.Default = "false"
+		}
+		v, err := p.Confirm(q)
+		if err != nil {
+			return nil, err
+		}
+		if strconv.FormatBool(v) == q.Default && !param.Required {
+			return nil, nil
+		}
+		return []string{strconv.FormatBool(v)}, nil
+
+	case parameters.ParameterTypeChoice:
+		v, err := p.Select(q, param.Choices)
+		if err != nil {
+			return nil, err
+		}
+		answer = v
+
+	case parameters.ParameterTypeChoiceList:
+		q.Defaults = splitAnswer(param, defaultValue)
+		v, err := p.MultiSelect(q, param.Choices)
+		if err != nil {
+			return nil, err
+		}
+		answer = strings.Join(v, ",")
+
+	default:
+		input := p.Input
+		if param.IsSensitive() {
+			input = p.Password
+		}
+		v, err := input(q)
+		if err != nil {
+			return nil, err
+		}
+		answer = v
+	}
+
+	if answer == "" {
+		return nil, nil
+	}
+	if answer == defaultValue {
+		if !param.Required {
+			return nil, nil
+		}
+		// required arguments need an actual value, which is masked in defaultValue for secrets
+		answer, err := parameters.RenderValue(param.Type, param.Default, parameters.WithRevealSecrets())
+		if err != nil {
+			return nil, err
+		}
+		return splitAnswer(param, answer), nil
+	}
+	return splitAnswer(param, answer), nil
+}
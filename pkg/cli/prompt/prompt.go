@@ -0,0 +1,283 @@
+package prompt
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/mattn/go-isatty"
+	"github.com/pkg/errors"
+	"golang.org/x/term"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Question describes a value asked to the user.
+type Question struct {
+	Label string
+	Help  string
+	// Default is shown to the user, and returned when the answer is empty.
+	// The Default of Confirm is either "true" or "false".
+	Default string
+	// Defaults are the options preselected in a MultiSelect.
+	Defaults []string
+	// Validate is called with each answer until it returns nil. An empty answer
+	// is replaced by Default before being validated.
+	Validate func(answer string) error
+}
+
+// Prompter asks the user for values.
+//
+// The implementations loop until the answer passes the Validate function of the question,
+// and return an error if no answer can be read, for example because the input was closed.
+type Prompter interface {
+	Input(q *Question) (string, error)
+	// Password asks for a secret value like Input, without showing the answer on the screen.
+	Password(q *Question) (string, error)
+	// Select asks the user to choose one of the options.
+	Select(q *Question, options []string) (string, error)
+	// MultiSelect asks the user to choose any number of the options.
+	MultiSelect(q *Question, options []string) ([]string, error)
+	Confirm(q *Question) (bool, error)
+}
+
+// IsTerminal returns true if both stdin and stdout are terminals, in which case the user
+// can be prompted for missing values.
+func IsTerminal() bool {
+	isTerminal := func(f *os.File) bool {
+		return isatty.IsTerminal(f.Fd()) || isatty.IsCygwinTerminal(f.Fd())
+	}
+	return isTerminal(os.Stdin) && isTerminal(os.Stdout)
+}
+
+// LinePrompter is a Prompter that reads answers line by line from an io.Reader,
+// and writes the questions to an io.Writer. Options of Select and MultiSelect
+// can be chosen either by number or by value.
+//
+// It works with any terminal, and can be scripted by providing the answers on its input.
+type LinePrompter struct {
+	// rawIn is the unbuffered input, used to read passwords from the terminal
+	rawIn io.Reader
+	in    *bufio.Reader
+	out   io.Writer
+}
+
+var _ Prompter = (*LinePrompter)(nil)
+
+func NewLinePrompter(in io.Reader, out io.Writer) *LinePrompter {
+	return &LinePrompter{
+		rawIn: in,
+		in:    bufio.NewReader(in),
+		out:   out,
+	}
+}
+
+func (l *LinePrompter) printf(format string, args ...interface{}) {
+	_, _ = fmt.Fprintf(l.out, format, args...)
+}
+
+func (l *LinePrompter) printQuestion(q *Question) {
+	l.printf("? %s", q.Label)
+	if q.Help != "" {
+		l.printf(" - %s", q.Help)
+	}
+	l.printf("\n")
+}
+
+func (l *LinePrompter) readLine() (string, error) {
+	line, err := l.in.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		if err == io.EOF {
+			l.printf("\n")
+			return "", errors.New("no answer, input was closed")
+		}
+		return "", err
+	}
+	return strings.TrimSpace(line), nil
+}
+
+// readPassword reads a line without echoing it when the input is a terminal.
+func (l *LinePrompter) readPassword() (string, error) {
+	f, ok := l.rawIn.(*os.File)
+	if !ok || l.in.Buffered() > 0 || !term.IsTerminal(int(f.Fd())) {
+		return l.readLine()
+	}
+	line, err := term.ReadPassword(int(f.Fd()))
+	// the newline typed by the user is not echoed either
+	l.printf("\n")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(line)), nil
+}
+
+// ask reads answers until parse accepts one, printing the errors it returns.
+func (l *LinePrompter) ask(prompt string, parse func(answer string) error) error {
+	return l.askWith(prompt, l.readLine, parse)
+}
+
+func (l *LinePrompter) askWith(prompt string, read func() (string, error), parse func(answer string) error) error {
+	for {
+		l.printf("%s", prompt)
+		answer, err := read()
+		if err != nil {
+			return err
+		}
+		err = parse(answer)
+		if err != nil {
+			l.printf("  ! %s\n", err)
+			continue
+		}
+		return nil
+	}
+}
+
+func (q *Question) validate(answer string) error {
+	if q.Validate == nil {
+		return nil
+	}
+	return q.Validate(answer)
+}
+
+func (l *LinePrompter) Input(q *Question) (string, error) {
+	return l.input(q, l.readLine)
+}
+
+// Password asks for a value like Input. When reading from a terminal, the answer is not echoed.
+func (l *LinePrompter) Password(q *Question) (string, error) {
+	return l.input(q, l.readPassword)
+}
+
+func (l *LinePrompter) input(q *Question, read func() (string, error)) (string, error) {
+	l.printQuestion(q)
+	prompt := "> "
+	if q.Default != "" {
+		prompt = fmt.Sprintf("[%s] > ", q.Default)
+	}
+
+	var ret string
+	err := l.askWith(prompt, read, func(answer string) error {
+		if answer == "" {
+			answer = q.Default
+		}
+		ret = answer
+		return q.validate(answer)
+	})
+	return ret, err
+}
+
+func (l *LinePrompter) printOptions(options []string, selected map[string]bool) {
+	for i, option := range options {
+		marker := " "
+		if selected[option] {
+			marker = "*"
+		}
+		l.printf("  %s %d) %s\n", marker, i+1, option)
+	}
+}
+
+// findOption returns the option with the given number (starting at 1) or value.
+func findOption(options []string, answer string) (string, error) {
+	for _, option := range options {
+		if option == answer {
+			return option, nil
+		}
+	}
+	if i, err := strconv.Atoi(answer); err == nil && i >= 1 && i <= len(options) {
+		return options[i-1], nil
+	}
+	return "", errors.Errorf("%s is not one of the options", answer)
+}
+
+func (l *LinePrompter) Select(q *Question, options []string) (string, error) {
+	if len(options) == 0 {
+		return "", errors.Errorf("no options to choose from for %s", q.Label)
+	}
+
+	l.printQuestion(q)
+	l.printOptions(options, map[string]bool{q.Default: true})
+	prompt := fmt.Sprintf("Choose 1-%d > ", len(options))
+	if q.Default != "" {
+		prompt = fmt.Sprintf("Choose 1-%d [%s] > ", len(options), q.Default)
+	}
+
+	var ret string
+	err := l.ask(prompt, func(answer string) error {
+		if answer == "" {
+			if q.Default == "" {
+				return q.validate("")
+			}
+			answer = q.Default
+		}
+		option, err := findOption(options, answer)
+		if err != nil {
+			return err
+		}
+		ret = option
+		return q.validate(option)
+	})
+	return ret, err
+}
+
+func (l *LinePrompter) MultiSelect(q *Question, options []string) ([]string, error) {
+	if len(options) == 0 {
+		return nil, errors.Errorf("no options to choose from for %s", q.Label)
+	}
+
+	l.printQuestion(q)
+	selected := map[string]bool{}
+	for _, d := range q.Defaults {
+		selected[d] = true
+	}
+	l.printOptions(options, selected)
+	prompt := "Choose any of 1-%d, separated by commas > "
+	if len(q.Defaults) > 0 {
+		prompt = "Choose any of 1-%d, separated by commas [" + strings.Join(q.Defaults, ",") + "] > "
+	}
+
+	var ret []string
+	err := l.ask(fmt.Sprintf(prompt, len(options)), func(answer string) error {
+		ret = []string{}
+		if answer == "" {
+			ret = append(ret, q.Defaults...)
+			return q.validate(strings.Join(ret, ","))
+		}
+		for _, a := range strings.Split(answer, ",") {
+			a = strings.TrimSpace(a)
+			if a == "" {
+				continue
+			}
+			option, err := findOption(options, a)
+			if err != nil {
+				return err
+			}
+			ret = append(ret, option)
+		}
+		return q.validate(strings.Join(ret, ","))
+	})
+	return ret, err
+}
+
+func (l *LinePrompter) Confirm(q *Question) (bool, error) {
+	l.printQuestion(q)
+	prompt := "[y/N] > "
+	if q.Default == "true" {
+		prompt = "[Y/n] > "
+	}
+
+	var ret bool
+	err := l.ask(prompt, func(answer string) error {
+		switch strings.ToLower(answer) {
+		case "":
+			ret = q.Default == "true"
+		case "y", "yes", "true":
+			ret = true
+		case "n", "no", "false":
+			ret = false
+		default:
+			return errors.Errorf("please answer yes or no")
+		}
+		return q.validate(strconv.FormatBool(ret))
+	})
+	return ret, err
+}
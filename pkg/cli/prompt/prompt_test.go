@@ -0,0 +1,141 @@
+package prompt
+
+import (
+	"bytes"
+	"github.com/go-go-golems/glazed/pkg/cmds/parameters"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+)
+
+func newTestPrompter(input ...string) (*LinePrompter, *bytes.Buffer) {
+	out := &bytes.Buffer{}
+	return NewLinePrompter(strings.NewReader(strings.Join(input, "\n")+"\n"), out), out
+}
+
+func TestLinePrompter(t *testing.T) {
+	p, out := newTestPrompter("", "abc", "", "3", "foo", "2", "1,blue", "", "maybe", "y")
+
+	answer, err := p.Input(&Question{Label: "name", Default: "foo"})
+	require.NoError(t, err)
+	assert.Equal(t, "foo", answer)
+
+	answer, err = p.Input(&Question{Label: "name"})
+	require.NoError(t, err)
+	assert.Equal(t, "abc", answer)
+
+	options := []string{"red", "green", "blue"}
+	answer, err = p.Select(&Question{Label: "color", Default: "green"}, options)
+	require.NoError(t, err)
+	assert.Equal(t, "green", answer)
+	answer, err = p.Select(&Question{Label: "color"}, options)
+	require.NoError(t, err)
+	assert.Equal(t, "blue", answer)
+
+	// invalid answers are asked again
+	answers, err := p.MultiSelect(&Question{Label: "colors", Defaults: []string{"red"}}, options)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"green"}, answers)
+	assert.Contains(t, out.String(), "foo is not one of the options")
+	answers, err = p.MultiSelect(&Question{Label: "colors"}, options)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"red", "blue"}, answers)
+
+	confirmed, err := p.Confirm(&Question{Label: "verbose", Default: "true"})
+	require.NoError(t, err)
+	assert.True(t, confirmed)
+	confirmed, err = p.Confirm(&Question{Label: "verbose"})
+	require.NoError(t, err)
+	assert.True(t, confirmed)
+	assert.Contains(t, out.String(), "please answer yes or no")
+
+	_, err = p.Input(&Question{Label: "name"})
+	assert.ErrorContains(t, err, "input was closed")
+}
+
+func TestAskParameter(t *testing.T) {
+	count := parameters.NewParameterDefinition("count", parameters.ParameterTypeInteger,
+		parameters.WithHelp("Number of items"),
+		parameters.WithMin(1),
+		parameters.WithRequired(true),
+	)
+	p, out := newTestPrompter("", "abc", "0", "5")
+	answer, err := AskParameter(p, count, "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"5"}, answer)
+	assert.Contains(t, out.String(), "? count - Number of items")
+	assert.Contains(t, out.String(), "a value is required")
+
+	format := parameters.NewParameterDefinition("format", parameters.ParameterTypeChoice,
+		parameters.WithChoices([]string{"json", "yaml", "csv"}),
+		parameters.WithDefault("json"),
+	)
+	p, _ = newTestPrompter("", "yaml")
+	answer, err = AskParameter(p, format, "output-")
+	require.NoError(t, err)
+	assert.Nil(t, answer)
+	answer, err = AskParameter(p, format, "output-")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"yaml"}, answer)
+
+	fields := parameters.NewParameterDefinition("fields", parameters.ParameterTypeChoiceList,
+		parameters.WithChoices([]string{"id", "name", "email"}),
+	)
+	p, _ = newTestPrompter("1,3")
+	answer, err = AskParameter(p, fields, "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"id", "email"}, answer)
+
+	ids := parameters.NewParameterDefinition("ids", parameters.ParameterTypeIntegerList)
+	p, _ = newTestPrompter("1,x", "1, 2,3", "")
+	answer, err = AskParameter(p, ids, "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1", "2", "3"}, answer)
+	answer, err = AskParameter(p, ids, "")
+	require.NoError(t, err)
+	assert.Nil(t, answer)
+
+	verbose := parameters.NewParameterDefinition("verbose", parameters.ParameterTypeBool)
+	p, _ = newTestPrompter("yes")
+	answer, err = AskParameter(p, verbose, "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"true"}, answer)
+
+	token := parameters.NewParameterDefinition("token", parameters.ParameterTypeSecret,
+		parameters.WithDefault("hunter2"),
+	)
+	p, out = newTestPrompter("")
+	answer, err = AskParameter(p, token, "")
+	require.NoError(t, err)
+	assert.Nil(t, answer)
+	assert.NotContains(t, out.String(), "hunter2")
+}
+
+// passwordPrompter records the questions asked with Password.
+type passwordPrompter struct {
+	*LinePrompter
+	passwords []string
+}
+
+func (p *passwordPrompter) Password(q *Question) (string, error) {
+	p.passwords = append(p.passwords, q.Label)
+	return p.LinePrompter.Password(q)
+}
+
+func TestAskSecretParameter(t *testing.T) {
+	lp, _ := newTestPrompter("s3cr3t", "alice")
+	p := &passwordPrompter{LinePrompter: lp}
+
+	token := parameters.NewParameterDefinition("token", parameters.ParameterTypeSecret)
+	answer, err := AskParameter(p, token, "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"s3cr3t"}, answer)
+
+	user := parameters.NewParameterDefinition("user", parameters.ParameterTypeString)
+	answer, err = AskParameter(p, user, "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"alice"}, answer)
+
+	assert.Equal(t, []string{"token"}, p.passwords)
+}
@@ -0,0 +1,25 @@
+package cli
+
+import (
+	"github.com/go-go-golems/glazed/pkg/cmds"
+	"github.com/go-go-golems/glazed/pkg/tools"
+	"github.com/spf13/cobra"
+	"os"
+)
+
+// NewServeToolsCommand returns a serve-tools command that exposes commands as tools to
+// editor integrations and agents, over a JSON-RPC protocol on stdin and stdout (see tools.Server).
+func NewServeToolsCommand(commands []cmds.Command, options ...tools.ServerOption) *cobra.Command {
+	return &cobra.Command{
+		Use:   "serve-tools",
+		Short: "Expose the commands as tools over JSON-RPC on stdin and stdout",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			s, err := tools.NewServer(commands, options...)
+			if err != nil {
+				return err
+			}
+			return s.Serve(cmd.Context(), os.Stdin, os.Stdout)
+		},
+	}
+}
@@ -21,6 +21,7 @@ type CommandDescription struct {
 	Flags          []*parameters.ParameterDefinition `yaml:"flags,omitempty"`
 	Arguments      []*parameters.ParameterDefinition `yaml:"arguments,omitempty"`
 	Layers         []layers.ParameterLayer           `yaml:"layers,omitempty"`
+	Relations      []*parameters.ParameterRelation   `yaml:"relations,omitempty"`
 	AdditionalData map[string]interface{}            `yaml:"additionalData,omitempty"`
 
 	Parents []string `yaml:",omitempty"`
@@ -60,6 +61,42 @@ func (c *CommandDescription) GetArgumentMap() map[string]*parameters.ParameterDe
 	return ret
 }
 
+// GetParameterRelations returns the relations declared by the command and by its layers.
+func (c *CommandDescription) GetParameterRelations() []*parameters.ParameterRelation {
+	ret := append([]*parameters.ParameterRelation{}, c.Relations...)
+	for _, layer := range c.Layers {
+		if l, ok := layer.(layers.ParameterRelationsLayer); ok {
+			ret = append(ret, l.GetParameterRelations()...)
+		}
+	}
+	return ret
+}
+
+// GetAllParameterDefinitions returns the parameter definitions of the layers, flags and
+// arguments of the command, by parameter name.
+func (c *CommandDescription) GetAllParameterDefinitions() map[string]*parameters.ParameterDefinition {
+	definitions := map[string]*parameters.ParameterDefinition{}
+	for _, layer := range c.Layers {
+		for k, v := range layer.GetParameterDefinitions() {
+			definitions[k] = v
+		}
+	}
+	for k, v := range c.GetFlagMap() {
+		definitions[k] = v
+	}
+	for k, v := range c.GetArgumentMap() {
+		definitions[k] = v
+	}
+	return definitions
+}
+
+// CheckParameterRelations checks the relations of the command and its layers against
+// the parameters parsed from all layers, flags and arguments. Provided holds the names of
+// the parameters that were explicitly provided.
+func (c *CommandDescription) CheckParameterRelations(ps map[string]interface{}, provided map[string]bool) error {
+	return parameters.CheckParameterRelations(c.GetParameterRelations(), ps, provided)
+}
+
 func (c *CommandDescription) Clone(cloneFlagsAndArguments bool, options ...CommandDescriptionOption) *CommandDescription {
 	// clone flags
 	flags := make([]*parameters.ParameterDefinition, len(c.Flags))
@@ -89,6 +126,9 @@ func (c *CommandDescription) Clone(cloneFlagsAndArguments bool, options ...Comma
 	parents := make([]string, len(c.Parents))
 	copy(parents, c.Parents)
 
+	relations := make([]*parameters.ParameterRelation, len(c.Relations))
+	copy(relations, c.Relations)
+
 	ret := &CommandDescription{
 		Name:      c.Name,
 		Short:     c.Short,
@@ -96,6 +136,7 @@ func (c *CommandDescription) Clone(cloneFlagsAndArguments bool, options ...Comma
 		Flags:     flags,
 		Arguments: arguments,
 		Layers:    layers_,
+		Relations: relations,
 		Parents:   parents,
 		Source:    c.Source,
 	}
@@ -143,6 +184,12 @@ func WithLayers(l ...layers.ParameterLayer) CommandDescriptionOption {
 	}
 }
 
+func WithRelations(r ...*parameters.ParameterRelation) CommandDescriptionOption {
+	return func(c *CommandDescription) {
+		c.Relations = append(c.Relations, r...)
+	}
+}
+
 func WithLayout(l *layout.Layout) CommandDescriptionOption {
 	return func(c *CommandDescription) {
 		c.Layout = l.Sections
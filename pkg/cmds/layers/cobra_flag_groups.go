@@ -41,6 +41,8 @@ type FlagUsage struct {
 	FlagString string
 	Help       string
 	Default    string
+	// Env is the environment variable the flag can be read from, if any.
+	Env string
 }
 
 // FlagGroupUsage is used to render the help for a flag group.
@@ -266,6 +268,10 @@ func getFlagUsage(f *flag.Flag) *FlagUsage {
 		ret.Help += fmt.Sprintf(" (DEPRECATED: %s)", f.Deprecated)
 	}
 
+	if env, ok := f.Annotations[parameters.EnvVarAnnotation]; ok && len(env) > 0 {
+		ret.Env = fmt.Sprintf(" [env: %s]", env[0])
+	}
+
 	return ret
 }
 
@@ -392,3 +398,35 @@ func GetFlagGroups(cmd *cobra.Command) []*FlagGroup {
 
 	return returnGroups
 }
+
+// AddParameterRelationsToCobraCommand records the descriptions of the given relations
+// in the glazed:parameter-relations annotation of the command, so that they can be
+// shown in the help page.
+func AddParameterRelationsToCobraCommand(
+	cmd *cobra.Command,
+	relations []*parameters.ParameterRelation,
+	prefix string,
+) {
+	if len(relations) == 0 {
+		return
+	}
+	if cmd.Annotations == nil {
+		cmd.Annotations = map[string]string{}
+	}
+
+	descriptions := GetParameterRelationDescriptions(cmd)
+	for _, r := range relations {
+		descriptions = append(descriptions, r.Description(prefix))
+	}
+	cmd.Annotations["glazed:parameter-relations"] = strings.Join(descriptions, "\n")
+}
+
+// GetParameterRelationDescriptions returns the descriptions of the parameter relations
+// added with AddParameterRelationsToCobraCommand.
+func GetParameterRelationDescriptions(cmd *cobra.Command) []string {
+	v := cmd.Annotations["glazed:parameter-relations"]
+	if v == "" {
+		return []string{}
+	}
+	return strings.Split(v, "\n")
+}
@@ -0,0 +1,112 @@
+package layers
+
+import (
+	"github.com/go-go-golems/glazed/pkg/cmds/parameters"
+	"github.com/pkg/errors"
+	"reflect"
+)
+
+// Decode fills the struct pointed to by s with the parameters of the layer, see parameters.Decode.
+// The errors of the returned *parameters.DecodeError record the slug of the layer.
+func (ppl *ParsedParameterLayer) Decode(s interface{}) error {
+	err := parameters.Decode(ppl.Parameters, s)
+	if decodeError, ok := err.(*parameters.DecodeError); ok && ppl.Layer != nil {
+		for _, e := range decodeError.Errors {
+			e.Layer = ppl.Layer.GetSlug()
+		}
+	}
+	return err
+}
+
+// DecodeInto decodes the parameters of the layer with the given slug into a new T,
+// which is a struct whose fields are tagged with `glazed.parameter`.
+//
+//	type DatabaseSettings struct {
+//		Host string `glazed.parameter:"host"`
+//		Port *int   `glazed.parameter:"port"`
+//	}
+//
+//	settings, err := layers.DecodeInto[DatabaseSettings](parsedLayers, "database")
+func DecodeInto[T any](parsedLayers map[string]*ParsedParameterLayer, slug string) (*T, error) {
+	parsedLayer, ok := parsedLayers[slug]
+	if !ok {
+		return nil, errors.Errorf("layer %s not found", slug)
+	}
+	ret := new(T)
+	err := parsedLayer.Decode(ret)
+	if err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// DecodeLayers fills the struct pointed to by s with the values of several layers.
+// Fields tagged with `glazed.layer:"<slug>"` are structs (or pointers to structs) decoded
+// from the layer with that slug, and the other fields are decoded from ps as done
+// by parameters.Decode.
+//
+// A missing layer leaves a pointer field nil, and is an error for other fields.
+// Decoding continues after a mismatch or a missing layer, and the returned
+// *parameters.DecodeError lists the errors of all layers.
+func DecodeLayers(parsedLayers map[string]*ParsedParameterLayer, ps map[string]interface{}, s interface{}) error {
+	v := reflect.ValueOf(s)
+	if s == nil || v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return errors.Errorf("can only decode into a pointer to a struct, got %T", s)
+	}
+
+	ret := &parameters.DecodeError{}
+	addErrors := func(err error) error {
+		if decodeError, ok := err.(*parameters.DecodeError); ok {
+			ret.Errors = append(ret.Errors, decodeError.Errors...)
+			return nil
+		}
+		return err
+	}
+
+	v = v.Elem()
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		slug, ok := field.Tag.Lookup("glazed.layer")
+		if !ok || !field.IsExported() {
+			continue
+		}
+
+		parsedLayer, ok := parsedLayers[slug]
+		value := v.Field(i)
+		if value.Kind() == reflect.Ptr {
+			if !ok {
+				continue
+			}
+			if value.IsNil() {
+				value.Set(reflect.New(field.Type.Elem()))
+			}
+			value = value.Elem()
+		} else if !ok {
+			ret.Errors = append(ret.Errors, &parameters.FieldError{
+				Layer: slug,
+				Field: field.Name,
+				Err:   errors.New("layer not found"),
+			})
+			continue
+		}
+		if value.Kind() != reflect.Struct {
+			return errors.Errorf("field %s of layer %s is not a struct", field.Name, slug)
+		}
+
+		err := addErrors(parsedLayer.Decode(value.Addr().Interface()))
+		if err != nil {
+			return err
+		}
+	}
+
+	err := addErrors(parameters.Decode(ps, s))
+	if err != nil {
+		return err
+	}
+
+	if len(ret.Errors) > 0 {
+		return ret
+	}
+	return nil
+}
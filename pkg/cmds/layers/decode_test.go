@@ -0,0 +1,80 @@
+package layers
+
+import (
+	"github.com/go-go-golems/glazed/pkg/cmds/parameters"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+type databaseSettings struct {
+	Host string `glazed.parameter:"host"`
+	Port *int   `glazed.parameter:"port"`
+}
+
+type loggingSettings struct {
+	Level string `glazed.parameter:"level"`
+}
+
+type commandSettings struct {
+	Limit    int              `glazed.parameter:"limit"`
+	Database databaseSettings `glazed.layer:"database"`
+	Logging  *loggingSettings `glazed.layer:"logging"`
+}
+
+func newParsedLayer(t *testing.T, slug string, ps map[string]interface{}) *ParsedParameterLayer {
+	layer, err := NewParameterLayer(slug, slug)
+	require.NoError(t, err)
+	return &ParsedParameterLayer{Layer: layer, Parameters: ps}
+}
+
+func TestDecodeInto(t *testing.T) {
+	parsedLayers := map[string]*ParsedParameterLayer{
+		"database": newParsedLayer(t, "database", map[string]interface{}{"host": "localhost", "port": 5432}),
+	}
+
+	s, err := DecodeInto[databaseSettings](parsedLayers, "database")
+	require.NoError(t, err)
+	assert.Equal(t, "localhost", s.Host)
+	assert.Equal(t, 5432, *s.Port)
+
+	_, err = DecodeInto[databaseSettings](parsedLayers, "missing")
+	assert.ErrorContains(t, err, "layer missing not found")
+}
+
+func TestDecodeLayers(t *testing.T) {
+	parsedLayers := map[string]*ParsedParameterLayer{
+		"database": newParsedLayer(t, "database", map[string]interface{}{"host": "localhost"}),
+	}
+
+	s := &commandSettings{}
+	require.NoError(t, DecodeLayers(parsedLayers, map[string]interface{}{"limit": 10}, s))
+	assert.Equal(t, 10, s.Limit)
+	assert.Equal(t, "localhost", s.Database.Host)
+	assert.Nil(t, s.Database.Port)
+	assert.Nil(t, s.Logging)
+
+	parsedLayers["logging"] = newParsedLayer(t, "logging", map[string]interface{}{"level": "debug"})
+	parsedLayers["database"].Parameters["port"] = "not a port"
+	s = &commandSettings{}
+	err := DecodeLayers(parsedLayers, map[string]interface{}{"limit": []string{"x"}}, s)
+	require.Error(t, err)
+	assert.Equal(t, "debug", s.Logging.Level)
+
+	decodeError, ok := err.(*parameters.DecodeError)
+	require.True(t, ok)
+	require.Len(t, decodeError.Errors, 2)
+	assert.Equal(t, "database", decodeError.Errors[0].Layer)
+	assert.Equal(t, "port", decodeError.Errors[0].Parameter)
+	assert.Equal(t, "", decodeError.Errors[1].Layer)
+	assert.Equal(t, "limit", decodeError.Errors[1].Parameter)
+
+	// a missing layer is reported along with the other errors
+	delete(parsedLayers, "database")
+	err = DecodeLayers(parsedLayers, map[string]interface{}{"limit": []string{"x"}}, &commandSettings{})
+	decodeError, ok = err.(*parameters.DecodeError)
+	require.True(t, ok)
+	require.Len(t, decodeError.Errors, 2)
+	assert.EqualError(t, decodeError.Errors[0], "layer database (field Database): layer not found")
+	assert.Equal(t, "limit", decodeError.Errors[1].Parameter)
+}
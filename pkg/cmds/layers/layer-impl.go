@@ -5,6 +5,11 @@ import (
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	"gopkg.in/yaml.v3"
+	"mime/multipart"
+	"net/url"
+	"reflect"
+	"strings"
+	"unicode"
 )
 
 // ParameterLayerImpl is a straight forward simple implementation of ParameterLayer
@@ -15,9 +20,12 @@ type ParameterLayerImpl struct {
 	Description string                            `yaml:"description"`
 	Prefix      string                            `yaml:"prefix"`
 	Flags       []*parameters.ParameterDefinition `yaml:"flags,omitempty"`
+	Relations   []*parameters.ParameterRelation   `yaml:"relations,omitempty"`
 	ChildLayers []ParameterLayer                  `yaml:"childLayers,omitempty"`
 }
 
+var _ ParameterRelationsLayer = (*ParameterLayerImpl)(nil)
+
 func (p *ParameterLayerImpl) GetName() string {
 	return p.Name
 }
@@ -40,6 +48,7 @@ func (p *ParameterLayerImpl) UnmarshalYAML(unmarshal func(interface{}) error) er
 		Slug        string                            `yaml:"slug"`
 		Description string                            `yaml:"description"`
 		Flags       []*parameters.ParameterDefinition `yaml:"flags,omitempty"`
+		Relations   []*parameters.ParameterRelation   `yaml:"relations,omitempty"`
 	}
 	if err := unmarshal(&raw); err != nil {
 		return err
@@ -48,9 +57,14 @@ func (p *ParameterLayerImpl) UnmarshalYAML(unmarshal func(interface{}) error) er
 	p.Slug = raw.Slug
 	p.Description = raw.Description
 	p.Flags = raw.Flags
+	p.Relations = raw.Relations
 	return nil
 }
 
+func (p *ParameterLayerImpl) GetParameterRelations() []*parameters.ParameterRelation {
+	return p.Relations
+}
+
 type ParameterLayerOptions func(*ParameterLayerImpl) error
 
 func NewParameterLayer(slug string, name string, options ...ParameterLayerOptions) (*ParameterLayerImpl, error) {
@@ -66,6 +80,13 @@ func NewParameterLayer(slug string, name string, options ...ParameterLayerOption
 		}
 	}
 
+	for _, p := range ret.Flags {
+		err := p.CheckParameterDefaultValueValidity()
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return ret, nil
 }
 
@@ -76,6 +97,13 @@ func WithPrefix(prefix string) ParameterLayerOptions {
 	}
 }
 
+func WithSlug(slug string) ParameterLayerOptions {
+	return func(p *ParameterLayerImpl) error {
+		p.Slug = slug
+		return nil
+	}
+}
+
 func WithName(name string) ParameterLayerOptions {
 	return func(p *ParameterLayerImpl) error {
 		p.Name = name
@@ -109,6 +137,13 @@ func WithFlags(flags ...*parameters.ParameterDefinition) ParameterLayerOptions {
 	}
 }
 
+func WithRelations(relations ...*parameters.ParameterRelation) ParameterLayerOptions {
+	return func(p *ParameterLayerImpl) error {
+		p.Relations = append(p.Relations, relations...)
+		return nil
+	}
+}
+
 func (p *ParameterLayerImpl) LoadFromYAML(s []byte) error {
 	err := yaml.Unmarshal(s, p)
 	if err != nil {
@@ -142,6 +177,57 @@ func NewParameterLayerFromYAML(s []byte, options ...ParameterLayerOptions) (*Par
 	return ret, nil
 }
 
+// NewParameterLayerFromStruct creates a layer whose flags are built from the fields of the
+// struct pointed to by s, see parameters.NewParameterDefinitionsFromStruct. The values of
+// the struct become the defaults of the flags, which makes the struct the single source of
+// truth for the settings of the layer, and which round-trips with InitializeStructFromParameterDefaults
+// and parameters.WithOptionalPointers.
+//
+// The slug and name of the layer are derived from the name of the struct type,
+// for example "sql-connection" and "Sql connection" for SqlConnectionSettings,
+// and can be overridden with WithSlug and WithName.
+func NewParameterLayerFromStruct(s interface{}, options ...ParameterLayerOptions) (*ParameterLayerImpl, error) {
+	flags, err := parameters.NewParameterDefinitionsFromStruct(s)
+	if err != nil {
+		return nil, err
+	}
+
+	words := splitTypeName(reflect.TypeOf(s).Elem().Name())
+	if len(words) > 1 && words[len(words)-1] == "settings" {
+		words = words[:len(words)-1]
+	}
+	name := strings.Join(words, " ")
+	if name != "" {
+		name = strings.ToUpper(name[:1]) + name[1:]
+	}
+
+	options_ := append([]ParameterLayerOptions{WithFlags(flags...)}, options...)
+	return NewParameterLayer(strings.Join(words, "-"), name, options_...)
+}
+
+// splitTypeName splits a CamelCase type name into its lowercase words,
+// keeping acronyms such as HTTP together.
+func splitTypeName(name string) []string {
+	ret := []string{}
+	runes := []rune(name)
+	start := 0
+	for i := 1; i <= len(runes); i++ {
+		if i < len(runes) {
+			prevUpper := unicode.IsUpper(runes[i-1])
+			upper := unicode.IsUpper(runes[i])
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if !upper || (prevUpper && !nextLower) {
+				continue
+			}
+		}
+		if i > start {
+			ret = append(ret, strings.ToLower(string(runes[start:i])))
+		}
+		start = i
+	}
+	return ret
+}
+
 func (p *ParameterLayerImpl) AddFlag(flag *parameters.ParameterDefinition) {
 	p.Flags = append(p.Flags, flag)
 }
@@ -183,12 +269,15 @@ func (p *ParameterLayerImpl) InitializeParameterDefaultsFromParameters(
 
 }
 
-func (p *ParameterLayerImpl) InitializeStructFromParameterDefaults(s interface{}) error {
+func (p *ParameterLayerImpl) InitializeStructFromParameterDefaults(
+	s interface{},
+	options ...parameters.InitializeStructOption,
+) error {
 	if s == nil {
 		return nil
 	}
 	ps := p.GetParameterDefinitions()
-	err := parameters.InitializeStructFromParameterDefinitions(s, ps)
+	err := parameters.InitializeStructFromParameterDefinitions(s, ps, options...)
 	return err
 }
 
@@ -208,6 +297,7 @@ func (p *ParameterLayerImpl) AddFlagsToCobraCommand(cmd *cobra.Command) error {
 	}
 
 	AddFlagGroupToCobraCommand(cmd, p.Slug, p.Name, p.Flags, p.Prefix)
+	AddParameterRelationsToCobraCommand(cmd, p.Relations, p.Prefix)
 
 	return nil
 }
@@ -225,3 +315,17 @@ func (p *ParameterLayerImpl) ParseFlagsFromCobraCommand(cmd *cobra.Command) (map
 func (p *ParameterLayerImpl) ParseFlagsFromJSON(m map[string]interface{}, onlyProvided bool) (map[string]interface{}, error) {
 	return parameters.GatherParametersFromMap(m, p.GetParameterDefinitions(), onlyProvided)
 }
+
+var _ QueryParameterLayer = (*ParameterLayerImpl)(nil)
+
+// ParseFlagsFromQuery parses the flags of the layer from the values of a query string.
+// Like with ParseFlagsFromJSON, the values are looked up by name, without the prefix.
+func (p *ParameterLayerImpl) ParseFlagsFromQuery(values url.Values, onlyProvided bool) (map[string]interface{}, error) {
+	return parameters.GatherParametersFromQuery(values, p.GetParameterDefinitions(), onlyProvided)
+}
+
+// ParseFlagsFromForm parses the flags of the layer from a multipart form, including the
+// files uploaded for them.
+func (p *ParameterLayerImpl) ParseFlagsFromForm(form *multipart.Form, onlyProvided bool) (map[string]interface{}, error) {
+	return parameters.GatherParametersFromForm(form, p.GetParameterDefinitions(), onlyProvided)
+}
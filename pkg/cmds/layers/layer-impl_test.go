@@ -68,3 +68,57 @@ func TestAddFlagsToCobraCommandPrefix(t *testing.T) {
 	flagUsage := flagUsages[0]
 	require.Equal(t, "test-flag1", flagUsage.Long)
 }
+
+type HTTPServerSettings struct {
+	Host    string   `glazed.parameter:"host" glazed.help:"Address to listen on"`
+	Port    int      `glazed.parameter:"port" glazed.short:"p"`
+	Origins []string `glazed.parameter:"origins"`
+}
+
+func TestNewParameterLayerFromStruct(t *testing.T) {
+	layer, err := NewParameterLayerFromStruct(&HTTPServerSettings{Host: "localhost", Port: 8080})
+	require.NoError(t, err)
+	require.Equal(t, "http-server", layer.GetSlug())
+	require.Equal(t, "Http server", layer.GetName())
+	require.Len(t, layer.Flags, 3)
+	require.Equal(t, parameters.ParameterTypeInteger, layer.Flags[1].Type)
+	require.Equal(t, "p", layer.Flags[1].ShortFlag)
+
+	s := &HTTPServerSettings{}
+	require.NoError(t, layer.InitializeStructFromParameterDefaults(s))
+	require.Equal(t, &HTTPServerSettings{Host: "localhost", Port: 8080, Origins: []string{}}, s)
+
+	cmd := &cobra.Command{Use: "test"}
+	require.NoError(t, layer.AddFlagsToCobraCommand(cmd))
+	require.NoError(t, cmd.ParseFlags([]string{"-p", "9000"}))
+	ps, err := layer.ParseFlagsFromCobraCommand(cmd)
+	require.NoError(t, err)
+	require.Equal(t, "localhost", ps["host"])
+	require.Equal(t, 9000, ps["port"])
+
+	layer, err = NewParameterLayerFromStruct(&HTTPServerSettings{}, WithSlug("server"), WithName("Server"))
+	require.NoError(t, err)
+	require.Equal(t, "server", layer.GetSlug())
+	require.Equal(t, "Server", layer.GetName())
+
+	_, err = NewParameterLayerFromStruct(HTTPServerSettings{})
+	require.Error(t, err)
+}
+
+func TestNewParameterLayerChecksDefaults(t *testing.T) {
+	_, err := NewParameterLayer("test", "Test",
+		WithFlags(
+			parameters.NewParameterDefinition("count", parameters.ParameterTypeInteger,
+				parameters.WithMin(1), parameters.WithDefault(0)),
+		),
+	)
+	require.ErrorContains(t, err, "count")
+
+	_, err = NewParameterLayer("test", "Test",
+		WithFlags(
+			parameters.NewParameterDefinition("count", parameters.ParameterTypeInteger,
+				parameters.WithMin(1), parameters.WithDefault(1)),
+		),
+	)
+	require.NoError(t, err)
+}
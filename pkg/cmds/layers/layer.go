@@ -3,6 +3,8 @@ package layers
 import (
 	"fmt"
 	"github.com/go-go-golems/glazed/pkg/cmds/parameters"
+	"mime/multipart"
+	"net/url"
 )
 
 type ErrInvalidParameterLayer struct {
@@ -33,6 +35,20 @@ type ParameterLayer interface {
 	GetPrefix() string
 }
 
+// ParameterRelationsLayer is implemented by layers that declare relations between their
+// parameters, such as mutually exclusive flags. These are checked once all the layers
+// of a command have been parsed.
+type ParameterRelationsLayer interface {
+	GetParameterRelations() []*parameters.ParameterRelation
+}
+
+// CompositeParameterLayer is implemented by layers that group several child layers under
+// a single slug, such as the glazed layers. Config files can set the parameters of each
+// child layer under its own slug.
+type CompositeParameterLayer interface {
+	GetChildLayers() []ParameterLayer
+}
+
 // ParsedParameterLayer is the result of "parsing" input data using a ParameterLayer
 // specification. For example, it could be the result of parsing cobra command flags,
 // or a JSON body, or HTTP query parameters.
@@ -45,6 +61,13 @@ type JSONParameterLayer interface {
 	ParseFlagsFromJSON(m map[string]interface{}, onlyProvided bool) (map[string]interface{}, error)
 }
 
+// QueryParameterLayer is implemented by layers that can be parsed from the query string or
+// the form of an HTTP request, see parameters.GatherParametersFromQuery.
+type QueryParameterLayer interface {
+	ParseFlagsFromQuery(values url.Values, onlyProvided bool) (map[string]interface{}, error)
+	ParseFlagsFromForm(form *multipart.Form, onlyProvided bool) (map[string]interface{}, error)
+}
+
 // Clone returns a copy of the parsedParameterLayer with a fresh Parameters map.
 // However, neither the Layer nor the Parameters are deep copied.
 func (ppl *ParsedParameterLayer) Clone() *ParsedParameterLayer {
@@ -4,6 +4,8 @@ import (
 	"github.com/go-go-golems/glazed/pkg/cmds/layers"
 	"github.com/go-go-golems/glazed/pkg/cmds/parameters"
 	"github.com/pkg/errors"
+	"mime/multipart"
+	"net/url"
 )
 
 func ParseCommandFromMap(description *CommandDescription, m map[string]interface{}) (
@@ -53,5 +55,95 @@ func ParseCommandFromMap(description *CommandDescription, m map[string]interface
 		ps[k] = v
 	}
 
+	provided := map[string]bool{}
+	for k := range m {
+		provided[k] = true
+	}
+	err = description.CheckParameterRelations(ps, provided)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	return parsedLayers, ps, nil
 }
+
+// ParseCommandFromQuery parses the layers, flags and arguments of a command from the values
+// of a query string, see parameters.GatherParametersFromQuery. All the layers of the command
+// have to implement layers.QueryParameterLayer.
+func ParseCommandFromQuery(description *CommandDescription, values url.Values) (
+	map[string]*layers.ParsedParameterLayer,
+	map[string]interface{},
+	error,
+) {
+	return ParseCommandFromForm(description, &multipart.Form{Value: values})
+}
+
+// ParseCommandFromForm parses the layers, flags and arguments of a command from a multipart
+// form, see parameters.GatherParametersFromForm.
+func ParseCommandFromForm(description *CommandDescription, form *multipart.Form) (
+	map[string]*layers.ParsedParameterLayer,
+	map[string]interface{},
+	error,
+) {
+	parsedLayers := map[string]*layers.ParsedParameterLayer{}
+	ps := map[string]interface{}{}
+
+	for _, layer := range description.Layers {
+		queryParameterLayer, ok := layer.(layers.QueryParameterLayer)
+		if !ok {
+			err := errors.Errorf("layer %s is not a QueryParameterLayer", layer.GetName())
+			return nil, nil, err
+		}
+
+		ps_, err := queryParameterLayer.ParseFlagsFromForm(form, false)
+		if err != nil {
+			return nil, nil, err
+		}
+		parsedLayers[layer.GetSlug()] = &layers.ParsedParameterLayer{
+			Layer:      layer,
+			Parameters: ps_,
+		}
+
+		for k, v := range ps_ {
+			ps[k] = v
+		}
+	}
+
+	ps_, err := parameters.GatherParametersFromForm(form, description.GetFlagMap(), false)
+	if err != nil {
+		return nil, nil, err
+	}
+	for k, v := range ps_ {
+		ps[k] = v
+	}
+
+	ps_, err = parameters.GatherParametersFromForm(form, description.GetArgumentMap(), false)
+	if err != nil {
+		return nil, nil, err
+	}
+	for k, v := range ps_ {
+		ps[k] = v
+	}
+
+	err = description.CheckParameterRelations(ps, getProvidedFormParameters(form))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return parsedLayers, ps, nil
+}
+
+// getProvidedFormParameters returns the names of the parameters given in the form,
+// including the keyValue parameters given as name[key].
+func getProvidedFormParameters(form *multipart.Form) map[string]bool {
+	ret := map[string]bool{}
+	for k := range form.Value {
+		name, _, _ := parameters.SplitQueryKey(k)
+		ret[name] = true
+	}
+	for k := range form.File {
+		name, _, _ := parameters.SplitQueryKey(k)
+		ret[name] = true
+	}
+	return ret
+}
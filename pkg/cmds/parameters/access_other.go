@@ -0,0 +1,41 @@
+//go:build !(aix || darwin || dragonfly || freebsd || linux || netbsd || openbsd || solaris || zos)
+
+package parameters
+
+import (
+	"github.com/pkg/errors"
+	"os"
+)
+
+// checkAccessMode checks a single access mode of checkAccess on platforms without access(2),
+// by opening files and, for directories and executables, looking at the mode bits.
+func checkAccessMode(path string, fi os.FileInfo, a rune) error {
+	switch a {
+	case 'r':
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		_ = f.Close()
+	case 'w':
+		// directories can't be opened for writing, so only their mode bits are checked
+		if fi.IsDir() {
+			if fi.Mode().Perm()&0222 == 0 {
+				return errors.Errorf("%s is not writable", path)
+			}
+			return nil
+		}
+		f, err := os.OpenFile(path, os.O_WRONLY, 0)
+		if err != nil {
+			return err
+		}
+		_ = f.Close()
+	case 'x':
+		if fi.Mode().Perm()&0111 == 0 {
+			return errors.Errorf("%s is not executable", path)
+		}
+	default:
+		return errors.Errorf("unknown access mode %c", a)
+	}
+	return nil
+}
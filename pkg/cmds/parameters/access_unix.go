@@ -0,0 +1,32 @@
+//go:build aix || darwin || dragonfly || freebsd || linux || netbsd || openbsd || solaris || zos
+
+package parameters
+
+import (
+	"github.com/pkg/errors"
+	"golang.org/x/sys/unix"
+	"os"
+)
+
+// checkAccessMode checks a single access mode of checkAccess with access(2), which takes
+// the user running the process, its groups and read-only mounts into account.
+func checkAccessMode(path string, fi os.FileInfo, a rune) error {
+	var mode uint32
+	var adjective string
+	switch a {
+	case 'r':
+		mode, adjective = unix.R_OK, "readable"
+	case 'w':
+		mode, adjective = unix.W_OK, "writable"
+	case 'x':
+		mode, adjective = unix.X_OK, "executable"
+	default:
+		return errors.Errorf("unknown access mode %c", a)
+	}
+
+	err := unix.Access(path, mode)
+	if err != nil {
+		return errors.Wrapf(err, "%s is not %s", path, adjective)
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+//go:build aix || darwin || dragonfly || freebsd || linux || netbsd || openbsd || solaris || zos
+
+package parameters
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+// nobody is the user the access checks are run as when the tests run as root, which
+// passes most of them.
+const nobody = 65534
+
+// The path and access mode checked by TestCheckAccessHelper are passed in the environment.
+const (
+	accessHelperPathEnv = "GLAZED_CHECK_ACCESS_PATH"
+	accessHelperModeEnv = "GLAZED_CHECK_ACCESS_MODE"
+)
+
+// TestCheckAccessHelper runs checkAccess in the process started by checkAccessAsNobody.
+func TestCheckAccessHelper(t *testing.T) {
+	path := os.Getenv(accessHelperPathEnv)
+	if path == "" {
+		return
+	}
+	err := checkAccess(path, os.Getenv(accessHelperModeEnv))
+	if err != nil {
+		fmt.Print("error: ", err)
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+// checkAccessAsNobody runs checkAccess as the nobody user, with a copy of the test binary
+// in dir, which must be accessible to nobody.
+func checkAccessAsNobody(t *testing.T, dir string, path string, access string) error {
+	binary := filepath.Join(dir, "parameters.test")
+	if _, err := os.Stat(binary); os.IsNotExist(err) {
+		src, err := os.Open(os.Args[0])
+		require.NoError(t, err)
+		defer src.Close()
+		dst, err := os.OpenFile(binary, os.O_CREATE|os.O_WRONLY, 0755)
+		require.NoError(t, err)
+		_, err = io.Copy(dst, src)
+		require.NoError(t, err)
+		require.NoError(t, dst.Close())
+	}
+
+	cmd := exec.Command(binary, "-test.run=^TestCheckAccessHelper$")
+	cmd.Env = append(os.Environ(), accessHelperPathEnv+"="+path, accessHelperModeEnv+"="+access)
+	cmd.SysProcAttr = &syscall.SysProcAttr{Credential: &syscall.Credential{Uid: nobody, Gid: nobody}}
+	out, err := cmd.CombinedOutput()
+	if err == nil {
+		return nil
+	}
+	if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 1 && strings.HasPrefix(string(out), "error: ") {
+		return fmt.Errorf("%s", strings.TrimPrefix(string(out), "error: "))
+	}
+	require.NoError(t, err, string(out))
+	return nil
+}
+
+func TestCheckAccessOwnerAndOthers(t *testing.T) {
+	dir := t.TempDir()
+
+	if os.Geteuid() != 0 {
+		// the files created by the test belong to the user running it
+		file := filepath.Join(dir, "file")
+		require.NoError(t, os.WriteFile(file, []byte("foo"), 0744))
+		readOnlyDir := filepath.Join(dir, "read-only")
+		require.NoError(t, os.Mkdir(readOnlyDir, 0555))
+
+		assert.NoError(t, checkAccess(dir, "rwx"))
+		assert.NoError(t, checkAccess(file, "rwx"))
+		assert.ErrorContains(t, checkAccess(readOnlyDir, "w"), "is not writable")
+		// the root directory belongs to root, and is only writable by root
+		assert.NoError(t, checkAccess("/", "rx"))
+		assert.ErrorContains(t, checkAccess("/", "w"), "is not writable")
+		return
+	}
+
+	// the checks are run as nobody, the owner of the owner directory
+	require.NoError(t, os.Chmod(filepath.Dir(dir), 0755))
+	require.NoError(t, os.Chmod(dir, 0755))
+	mkdir := func(path string, perm os.FileMode, uid int) {
+		require.NoError(t, os.Mkdir(path, perm))
+		require.NoError(t, os.Chmod(path, perm))
+		require.NoError(t, os.Chown(path, uid, uid))
+	}
+	writeFile := func(path string, perm os.FileMode, uid int) {
+		require.NoError(t, os.WriteFile(path, []byte("foo"), perm))
+		require.NoError(t, os.Chmod(path, perm))
+		require.NoError(t, os.Chown(path, uid, uid))
+	}
+
+	ownerDir := filepath.Join(dir, "owner")
+	mkdir(ownerDir, 0755, nobody)
+	ownerFile := filepath.Join(ownerDir, "file")
+	writeFile(ownerFile, 0744, nobody)
+	ownerReadOnlyDir := filepath.Join(ownerDir, "read-only")
+	mkdir(ownerReadOnlyDir, 0555, nobody)
+
+	otherDir := filepath.Join(dir, "other")
+	mkdir(otherDir, 0755, 0)
+	otherFile := filepath.Join(otherDir, "file")
+	writeFile(otherFile, 0744, 0)
+
+	for _, c := range []struct {
+		path   string
+		access string
+		err    string
+	}{
+		{ownerDir, "rwx", ""},
+		{ownerFile, "rwx", ""},
+		{ownerReadOnlyDir, "rx", ""},
+		{ownerReadOnlyDir, "w", "is not writable"},
+		{otherDir, "rx", ""},
+		{otherDir, "w", "is not writable"},
+		{otherFile, "r", ""},
+		{otherFile, "w", "is not writable"},
+		{otherFile, "x", "is not executable"},
+	} {
+		err := checkAccessAsNobody(t, dir, c.path, c.access)
+		if c.err == "" {
+			assert.NoError(t, err, "%s %s", c.path, c.access)
+		} else {
+			assert.ErrorContains(t, err, c.err, "%s %s", c.path, c.access)
+		}
+	}
+}
@@ -87,7 +87,7 @@ func GenerateUseString(cmd *cobra.Command, arguments []*ParameterDefinition) str
 	for _, arg := range arguments {
 		defaultValueStr = ""
 		if arg.Default != nil {
-			defaultValueStr = fmt.Sprintf(" (default: %v)", arg.Default)
+			defaultValueStr = fmt.Sprintf(" (default: %v)", arg.RedactValue(arg.Default))
 		}
 		left, right := "[", "]"
 		if arg.Required {
@@ -137,7 +137,11 @@ func GatherArguments(
 				return nil, fmt.Errorf("Argument %s not found", argument.Name)
 			} else {
 				if argument.Default != nil && !onlyProvided {
-					result.Set(argument.Name, argument.Default)
+					v, err := argument.resolveSecret(argument.Default)
+					if err != nil {
+						return nil, err
+					}
+					result.Set(argument.Name, v)
 				}
 				continue
 			}
@@ -155,6 +159,10 @@ func GatherArguments(
 		if err != nil {
 			return nil, err
 		}
+		err = argument.CheckValueConstraints(i2)
+		if err != nil {
+			return nil, err
+		}
 
 		result.Set(argument.Name, i2)
 	}
@@ -457,9 +465,98 @@ func AddFlagsToCobraCommand(
 				flagSet.String(flagName, defaultValue, fmt.Sprintf("%s (%s)", parameter.Help, choiceString))
 			}
 
+		case ParameterTypeInteger64:
+			var defaultValue int64
+
+			if parameter.Default != nil {
+				defaultValue, ok = cast.CastNumberInterfaceToInt[int64](parameter.Default)
+				if !ok {
+					return errors.Errorf("Default value for parameter %s is not an integer: %v", parameter.Name, parameter.Default)
+				}
+			}
+
+			if parameter.ShortFlag != "" {
+				flagSet.Int64P(flagName, shortFlag, defaultValue, parameter.Help)
+			} else {
+				flagSet.Int64(flagName, defaultValue, parameter.Help)
+			}
+
+		case ParameterTypeUint:
+			var defaultValue uint
+
+			if parameter.Default != nil {
+				defaultValue, err = toUint(parameter.Default)
+				if err != nil {
+					return errors.Wrapf(err, "Default value for parameter %s is not an unsigned integer", parameter.Name)
+				}
+			}
+
+			if parameter.ShortFlag != "" {
+				flagSet.UintP(flagName, shortFlag, defaultValue, parameter.Help)
+			} else {
+				flagSet.Uint(flagName, defaultValue, parameter.Help)
+			}
+
+		case ParameterTypeDuration:
+			var defaultValue time.Duration
+
+			if parameter.Default != nil {
+				defaultValue, err = toDuration(parameter.Default)
+				if err != nil {
+					return errors.Wrapf(err, "Default value for parameter %s is not a duration", parameter.Name)
+				}
+			}
+
+			if parameter.ShortFlag != "" {
+				flagSet.DurationP(flagName, shortFlag, defaultValue, parameter.Help)
+			} else {
+				flagSet.Duration(flagName, defaultValue, parameter.Help)
+			}
+
+		case ParameterTypeURL,
+			ParameterTypeRegexp,
+			ParameterTypeByteSize,
+			ParameterTypePath,
+			ParameterTypeDirectory:
+			defaultValue := ""
+
+			if parameter.Default != nil {
+				defaultValue, err = RenderValue(parameter.Type, parameter.Default)
+				if err != nil {
+					return errors.Wrapf(err, "Could not render default value for parameter %s", parameter.Name)
+				}
+			}
+
+			if parameter.ShortFlag != "" {
+				flagSet.StringP(flagName, shortFlag, defaultValue, parameter.Help)
+			} else {
+				flagSet.String(flagName, defaultValue, parameter.Help)
+			}
+
+		case ParameterTypeSecret:
+			// the default is resolved when gathering the flags, only references
+			// to environment variables and files are registered with cobra
+			defaultValue := ""
+			if s, ok := parameter.Default.(string); ok && IsSecretReference(s) {
+				defaultValue = s
+			}
+
+			if parameter.ShortFlag != "" {
+				flagSet.StringP(flagName, shortFlag, defaultValue, parameter.Help)
+			} else {
+				flagSet.String(flagName, defaultValue, parameter.Help)
+			}
+
 		default:
 			return errors.Errorf("Unknown parameter type for parameter %s: %s", parameter.Name, parameter.Type)
 		}
+
+		// mask the default value shown in the help
+		if parameter.IsSensitive() && parameter.Default != nil {
+			if s, ok := parameter.Default.(string); !ok || !IsSecretReference(s) {
+				flagSet.Lookup(flagName).DefValue = RedactedValue
+			}
+		}
 	}
 
 	return nil
@@ -479,7 +576,11 @@ func GatherFlagsFromViper(
 		}
 		if !onlyProvided && !viper.IsSet(flagName) {
 			if p.Default != nil {
-				ret[p.Name] = p.Default
+				v, err := p.resolveSecret(p.Default)
+				if err != nil {
+					return nil, err
+				}
+				ret[p.Name] = v
 			}
 			continue
 		}
@@ -511,9 +612,31 @@ func GatherFlagsFromViper(
 		case ParameterTypeObjectFromFile:
 			ret[p.Name] = viper.GetStringMap(flagName)
 			// TODO(manuel, 2023-09-19) Add more of the newer types here too
+		case ParameterTypeInteger64:
+			ret[p.Name] = viper.GetInt64(flagName)
+		case ParameterTypeUint:
+			ret[p.Name] = viper.GetUint(flagName)
+		case ParameterTypeDuration:
+			ret[p.Name] = viper.GetDuration(flagName)
+		case ParameterTypeURL,
+			ParameterTypeRegexp,
+			ParameterTypeByteSize,
+			ParameterTypePath,
+			ParameterTypeDirectory,
+			ParameterTypeSecret:
+			v, err := p.ParseParameter([]string{viper.GetString(flagName)})
+			if err != nil {
+				return nil, err
+			}
+			ret[p.Name] = v
 		default:
 			return nil, errors.Errorf("Unknown parameter type %s for flag %s", p.Type, p.Name)
 		}
+
+		err := p.CheckValueConstraints(ret[p.Name])
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	return ret, nil
@@ -572,7 +695,12 @@ func GatherFlagsFromCobraCommand(
 			ParameterTypeString,
 			ParameterTypeFile,
 			ParameterTypeDate,
-			ParameterTypeChoice:
+			ParameterTypeChoice,
+			ParameterTypeURL,
+			ParameterTypeRegexp,
+			ParameterTypeByteSize,
+			ParameterTypePath,
+			ParameterTypeDirectory:
 			v, err := cmd.Flags().GetString(flagName)
 			if err != nil {
 				return nil, err
@@ -597,6 +725,46 @@ func GatherFlagsFromCobraCommand(
 			}
 			ps[parameter.Name] = v
 
+		case ParameterTypeInteger64:
+			v, err := cmd.Flags().GetInt64(flagName)
+			if err != nil {
+				return nil, err
+			}
+			ps[parameter.Name] = v
+
+		case ParameterTypeUint:
+			v, err := cmd.Flags().GetUint(flagName)
+			if err != nil {
+				return nil, err
+			}
+			ps[parameter.Name] = v
+
+		case ParameterTypeDuration:
+			v, err := cmd.Flags().GetDuration(flagName)
+			if err != nil {
+				return nil, err
+			}
+			ps[parameter.Name] = v
+
+		case ParameterTypeSecret:
+			if !cmd.Flags().Changed(flagName) {
+				v, err := toSecret(parameter.Default)
+				if err != nil {
+					return nil, errors.Wrapf(err, "Invalid default value for parameter %s", parameter.Name)
+				}
+				ps[parameter.Name] = v
+				break
+			}
+			v, err := cmd.Flags().GetString(flagName)
+			if err != nil {
+				return nil, err
+			}
+			v2, err := parameter.ParseParameter([]string{v})
+			if err != nil {
+				return nil, err
+			}
+			ps[parameter.Name] = v2
+
 		case ParameterTypeBool:
 			v, err := cmd.Flags().GetBool(flagName)
 			if err != nil {
@@ -659,6 +827,14 @@ func GatherFlagsFromCobraCommand(
 			}
 			ps[parameter.Name] = v
 		}
+
+		// only values provided by the user are checked against the constraints
+		if v, ok := ps[parameter.Name]; ok && cmd.Flags().Changed(flagName) {
+			err := parameter.CheckValueConstraints(v)
+			if err != nil {
+				return nil, err
+			}
+		}
 	}
 	return ps, nil
 }
@@ -0,0 +1,150 @@
+package parameters
+
+import (
+	"github.com/spf13/cobra"
+	"strings"
+)
+
+// CompletionFunc returns the shell completions for the value of a parameter, see cobra's
+// ValidArgsFunction. toComplete is the whole value typed so far, including the previous
+// elements of comma separated lists.
+type CompletionFunc func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective)
+
+// WithCompletion sets a custom completion function for the values of the parameter,
+// replacing the completion derived from its type.
+func WithCompletion(f CompletionFunc) ParameterDefinitionOption {
+	return func(p *ParameterDefinition) {
+		p.Completion = f
+	}
+}
+
+// fileExtensions are the extensions of the files that can be loaded by the file parameter types.
+var fileExtensions = map[ParameterType][]string{
+	ParameterTypeObjectFromFile:      {"json", "yaml", "yml", "csv", "tsv"},
+	ParameterTypeObjectListFromFile:  {"json", "yaml", "yml", "csv", "tsv"},
+	ParameterTypeObjectListFromFiles: {"json", "yaml", "yml", "csv", "tsv"},
+}
+
+func completePrefix(values []string, toComplete string) []string {
+	ret := []string{}
+	for _, v := range values {
+		if strings.HasPrefix(v, toComplete) {
+			ret = append(ret, v)
+		}
+	}
+	return ret
+}
+
+// CompleteList completes the last element of a comma separated list with the given values.
+// The completions contain the previous elements of the list, and values that are already in
+// the list are not proposed again.
+func CompleteList(values []string, toComplete string) []string {
+	prefix := ""
+	current := toComplete
+	if i := strings.LastIndex(toComplete, ","); i >= 0 {
+		prefix, current = toComplete[:i+1], toComplete[i+1:]
+	}
+	used := map[string]bool{}
+	for _, v := range strings.Split(prefix, ",") {
+		used[v] = true
+	}
+
+	ret := []string{}
+	for _, v := range values {
+		if !used[v] && strings.HasPrefix(v, current) {
+			ret = append(ret, prefix+v)
+		}
+	}
+	return ret
+}
+
+// Complete returns the shell completions for the value of the parameter. Unless a custom
+// completion function is set with WithCompletion, completions are derived from the type:
+//   - choices (and each element of choice lists) complete to the declared choices
+//   - booleans complete to true and false
+//   - object file types complete to json, yaml and csv files, directories to directories
+//   - numbers, dates, durations, URLs, regexps and secrets are not completed
+//
+// Values of other types (strings, files, paths) use the default file completion of the shell.
+func (p *ParameterDefinition) Complete(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if p.Completion != nil {
+		return p.Completion(cmd, args, toComplete)
+	}
+
+	switch p.Type {
+	case ParameterTypeChoice:
+		return completePrefix(p.Choices, toComplete), cobra.ShellCompDirectiveNoFileComp
+
+	case ParameterTypeChoiceList:
+		return CompleteList(p.Choices, toComplete), cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveNoSpace
+
+	case ParameterTypeBool:
+		return completePrefix([]string{"true", "false"}, toComplete), cobra.ShellCompDirectiveNoFileComp
+
+	case ParameterTypeObjectFromFile,
+		ParameterTypeObjectListFromFile,
+		ParameterTypeObjectListFromFiles:
+		return fileExtensions[p.Type], cobra.ShellCompDirectiveFilterFileExt
+
+	case ParameterTypeDirectory:
+		return nil, cobra.ShellCompDirectiveFilterDirs
+
+	case ParameterTypeInteger,
+		ParameterTypeInteger64,
+		ParameterTypeUint,
+		ParameterTypeFloat,
+		ParameterTypeIntegerList,
+		ParameterTypeFloatList,
+		ParameterTypeDate,
+		ParameterTypeDuration,
+		ParameterTypeByteSize,
+		ParameterTypeURL,
+		ParameterTypeRegexp,
+		ParameterTypeSecret:
+		return nil, cobra.ShellCompDirectiveNoFileComp
+
+	default:
+		// strings are often file names, keep the default completion of the shell
+		return nil, cobra.ShellCompDirectiveDefault
+	}
+}
+
+// RegisterFlagCompletions registers the completion of the values of each parameter with the
+// flags of cmd. Prefix is prepended to all flag names.
+func RegisterFlagCompletions(cmd *cobra.Command, params []*ParameterDefinition, prefix string) error {
+	for _, p := range params {
+		flagName := strings.ReplaceAll(prefix+p.Name, "_", "-")
+		if cmd.Flag(flagName) == nil {
+			continue
+		}
+		err := cmd.RegisterFlagCompletionFunc(flagName, p.Complete)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CompleteArguments returns a cobra ValidArgsFunction completing the positional arguments
+// with the completions of their parameters. The last argument can be a list, in which case
+// it completes all the remaining arguments.
+func CompleteArguments(arguments []*ParameterDefinition) func(
+	cmd *cobra.Command,
+	args []string,
+	toComplete string,
+) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(arguments) == 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		i := len(args)
+		if i >= len(arguments) {
+			last := arguments[len(arguments)-1]
+			if !IsListParameter(last.Type) {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			i = len(arguments) - 1
+		}
+		return arguments[i].Complete(cmd, args, toComplete)
+	}
+}
@@ -0,0 +1,88 @@
+package parameters
+
+import (
+	"bytes"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+)
+
+func TestComplete(t *testing.T) {
+	choice := NewParameterDefinition("format", ParameterTypeChoice, WithChoices([]string{"json", "yaml", "csv"}))
+	completions, directive := choice.Complete(nil, nil, "")
+	assert.Equal(t, []string{"json", "yaml", "csv"}, completions)
+	assert.Equal(t, cobra.ShellCompDirectiveNoFileComp, directive)
+	completions, _ = choice.Complete(nil, nil, "y")
+	assert.Equal(t, []string{"yaml"}, completions)
+
+	choiceList := NewParameterDefinition("formats", ParameterTypeChoiceList, WithChoices([]string{"json", "yaml", "csv"}))
+	completions, _ = choiceList.Complete(nil, nil, "yaml,")
+	assert.Equal(t, []string{"yaml,json", "yaml,csv"}, completions)
+	completions, _ = choiceList.Complete(nil, nil, "yaml,c")
+	assert.Equal(t, []string{"yaml,csv"}, completions)
+
+	objects := NewParameterDefinition("input", ParameterTypeObjectListFromFile)
+	completions, directive = objects.Complete(nil, nil, "")
+	assert.Contains(t, completions, "csv")
+	assert.Equal(t, cobra.ShellCompDirectiveFilterFileExt, directive)
+
+	_, directive = NewParameterDefinition("count", ParameterTypeInteger).Complete(nil, nil, "")
+	assert.Equal(t, cobra.ShellCompDirectiveNoFileComp, directive)
+	_, directive = NewParameterDefinition("dir", ParameterTypeDirectory).Complete(nil, nil, "")
+	assert.Equal(t, cobra.ShellCompDirectiveFilterDirs, directive)
+	_, directive = NewParameterDefinition("name", ParameterTypeString).Complete(nil, nil, "")
+	assert.Equal(t, cobra.ShellCompDirectiveDefault, directive)
+
+	custom := NewParameterDefinition("host", ParameterTypeString,
+		WithCompletion(func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			return []string{toComplete + "example.com"}, cobra.ShellCompDirectiveNoFileComp
+		}),
+	)
+	completions, _ = custom.Complete(nil, nil, "db.")
+	assert.Equal(t, []string{"db.example.com"}, completions)
+	assert.NotNil(t, custom.Copy().Completion)
+}
+
+func TestCompletionRegistration(t *testing.T) {
+	params := []*ParameterDefinition{
+		NewParameterDefinition("format", ParameterTypeChoice, WithChoices([]string{"json", "yaml"})),
+	}
+	arguments := []*ParameterDefinition{
+		NewParameterDefinition("mode", ParameterTypeChoice, WithChoices([]string{"fast", "slow"})),
+		NewParameterDefinition("inputs", ParameterTypeObjectListFromFiles),
+	}
+
+	cmd := &cobra.Command{Use: "test"}
+	require.NoError(t, AddFlagsToCobraCommand(cmd.Flags(), params, "out-"))
+	require.NoError(t, RegisterFlagCompletions(cmd, params, "out-"))
+
+	completions, _, err := executeCompletion(cmd, "--out-format", "j")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"json"}, completions)
+
+	cmd.ValidArgsFunction = CompleteArguments(arguments)
+	completions, _, err = executeCompletion(cmd, "f")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"fast"}, completions)
+
+	_, directive := cmd.ValidArgsFunction(cmd, []string{"fast", "a.json"}, "")
+	assert.Equal(t, cobra.ShellCompDirectiveFilterFileExt, directive)
+}
+
+// executeCompletion runs cobra's hidden completion command and returns the completions it prints.
+func executeCompletion(cmd *cobra.Command, args ...string) ([]string, string, error) {
+	root := &cobra.Command{Use: "root"}
+	root.AddCommand(cmd)
+	out := &bytes.Buffer{}
+	root.SetOut(out)
+	root.SetArgs(append([]string{cobra.ShellCompRequestCmd, cmd.Name()}, args...))
+	err := root.Execute()
+	if err != nil {
+		return nil, "", err
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	// the last line is the directive
+	return lines[:len(lines)-1], lines[len(lines)-1], nil
+}
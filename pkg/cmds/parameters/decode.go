@@ -0,0 +1,248 @@
+package parameters
+
+import (
+	"fmt"
+	reflect2 "github.com/go-go-golems/glazed/pkg/helpers/reflect"
+	"github.com/pkg/errors"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// FieldError describes a parameter value that could not be decoded into a struct field.
+type FieldError struct {
+	// Layer is the slug of the layer the parameter belongs to, if decoded from a layer.
+	Layer     string
+	Parameter string
+	// Field is the path of the struct field, for example Database.Port, or Servers[0].Port
+	// for a field of an element of a list. Value is the value that couldn't be decoded at
+	// that path.
+	Field string
+	Value interface{}
+	Err   error
+}
+
+func (e *FieldError) Error() string {
+	if e.Parameter == "" {
+		return fmt.Sprintf("layer %s (field %s): %s", e.Layer, e.Field, e.Err)
+	}
+	parameter := e.Parameter
+	if e.Layer != "" {
+		parameter = e.Layer + "." + parameter
+	}
+	return fmt.Sprintf("parameter %s (field %s): %s", parameter, e.Field, e.Err)
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// DecodeError is returned by Decode and lists every parameter that could not be decoded.
+type DecodeError struct {
+	Errors []*FieldError
+}
+
+func (e *DecodeError) Error() string {
+	messages := []string{}
+	for _, err := range e.Errors {
+		messages = append(messages, err.Error())
+	}
+	return fmt.Sprintf("could not decode %d parameter(s): %s", len(e.Errors), strings.Join(messages, "; "))
+}
+
+// Decode fills the struct pointed to by s with the parameter values of ps.
+//
+// Fields are matched by their `glazed.parameter` tag, and fields without a tag are ignored,
+// except embedded structs whose fields are decoded from the same values. Parameters missing
+// from ps leave their field untouched.
+//
+// Values are converted to the type of their field where possible: numbers to any number type,
+// lists to slices, maps to maps, and objects (as loaded by the objectFromFile and objectList
+// parameter types) to structs, whose fields are matched by `glazed.parameter` or `json` tag,
+// or else by case-insensitive name. Pointer fields are allocated when a value is present,
+// which makes them usable for optional parameters.
+//
+// All the fields are decoded, and a *DecodeError lists every value that couldn't be converted,
+// including the fields of nested objects and the elements of lists and maps.
+func Decode(ps map[string]interface{}, s interface{}) error {
+	v := reflect.ValueOf(s)
+	if s == nil || v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return errors.Errorf("can only decode into a pointer to a struct, got %T", s)
+	}
+
+	ret := &DecodeError{}
+	decodeStruct(v.Elem(), ps, "", ret)
+	if len(ret.Errors) > 0 {
+		return ret
+	}
+	return nil
+}
+
+func decodeStruct(v reflect.Value, ps map[string]interface{}, path string, errs *DecodeError) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		// fields decoded from a whole layer, see layers.DecodeLayers
+		if _, ok := field.Tag.Lookup("glazed.layer"); ok {
+			continue
+		}
+		fieldPath := path + field.Name
+		name, ok := field.Tag.Lookup("glazed.parameter")
+		if !ok {
+			// the exported fields of embedded structs are decoded too, even if the
+			// embedded type itself is unexported, like encoding/json does
+			if field.Anonymous {
+				embedded := v.Field(i)
+				if embedded.Kind() == reflect.Ptr && embedded.Type().Elem().Kind() == reflect.Struct {
+					if embedded.IsNil() {
+						if !embedded.CanSet() {
+							continue
+						}
+						embedded.Set(reflect.New(embedded.Type().Elem()))
+					}
+					embedded = embedded.Elem()
+				}
+				if embedded.Kind() == reflect.Struct {
+					decodeStruct(embedded, ps, fieldPath+".", errs)
+				}
+			}
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+
+		value, ok := ps[name]
+		if !ok {
+			continue
+		}
+		decodeValue(v.Field(i), value, fieldPath, func(path string, v interface{}, err error) {
+			errs.Errors = append(errs.Errors, &FieldError{
+				Parameter: name,
+				Field:     path,
+				Value:     v,
+				Err:       err,
+			})
+		})
+	}
+}
+
+// reportFunc records that the value v at the field path could not be decoded.
+type reportFunc func(path string, v interface{}, err error)
+
+// decodeValue converts v to the type of dst and assigns it. Values that can't be converted
+// are reported with the path of the nested field, list element or map entry, and the other
+// values are still decoded.
+func decodeValue(dst reflect.Value, v interface{}, path string, report reportFunc) {
+	if v == nil {
+		dst.Set(reflect.Zero(dst.Type()))
+		return
+	}
+
+	src := reflect.ValueOf(v)
+	if src.Type().AssignableTo(dst.Type()) {
+		dst.Set(src)
+		return
+	}
+
+	//exhaustive:ignore
+	switch dst.Kind() {
+	case reflect.Ptr:
+		elem := reflect.New(dst.Type().Elem())
+		decodeValue(elem.Elem(), v, path, report)
+		dst.Set(elem)
+
+	case reflect.Struct:
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			// values such as time.Time are handled by SetReflectValue
+			if err := reflect2.SetReflectValue(dst, v); err != nil {
+				report(path, v, err)
+			}
+			return
+		}
+		decodeObject(dst, m, path, report)
+
+	case reflect.Slice:
+		if src.Kind() != reflect.Slice && src.Kind() != reflect.Array {
+			report(path, v, errors.Errorf("expected a list, got %T", v))
+			return
+		}
+		ret := reflect.MakeSlice(dst.Type(), src.Len(), src.Len())
+		for i := 0; i < src.Len(); i++ {
+			decodeValue(ret.Index(i), src.Index(i).Interface(), fmt.Sprintf("%s[%d]", path, i), report)
+		}
+		dst.Set(ret)
+
+	case reflect.Map:
+		if src.Kind() != reflect.Map || dst.Type().Key().Kind() != reflect.String {
+			report(path, v, errors.Errorf("expected a map, got %T", v))
+			return
+		}
+		// keys are sorted so that errors are reported in a stable order
+		keys := src.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return fmt.Sprint(keys[i].Interface()) < fmt.Sprint(keys[j].Interface())
+		})
+		ret := reflect.MakeMapWithSize(dst.Type(), src.Len())
+		for _, key := range keys {
+			elem := reflect.New(dst.Type().Elem()).Elem()
+			decodeValue(elem, src.MapIndex(key).Interface(), fmt.Sprintf("%s[%v]", path, key.Interface()), report)
+			ret.SetMapIndex(key.Convert(dst.Type().Key()), elem)
+		}
+		dst.Set(ret)
+
+	default:
+		if err := reflect2.SetReflectValue(dst, v); err != nil {
+			report(path, v, err)
+		}
+	}
+}
+
+// objectFieldName returns the key of a struct field in an object.
+func objectFieldName(field reflect.StructField) string {
+	if name, ok := field.Tag.Lookup("glazed.parameter"); ok {
+		return name
+	}
+	if tag, ok := field.Tag.Lookup("json"); ok {
+		name := strings.Split(tag, ",")[0]
+		if name != "" {
+			return name
+		}
+	}
+	return field.Name
+}
+
+// decodeObject decodes an object, as loaded from a file, into a struct.
+func decodeObject(dst reflect.Value, m map[string]interface{}, path string, report reportFunc) {
+	keys := map[string]string{}
+	for k := range m {
+		keys[strings.ToLower(k)] = k
+	}
+
+	t := dst.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			decodeObject(dst.Field(i), m, path, report)
+			continue
+		}
+
+		name := objectFieldName(field)
+		if name == "-" {
+			continue
+		}
+		v, ok := m[name]
+		if !ok {
+			key, ok := keys[strings.ToLower(name)]
+			if !ok {
+				continue
+			}
+			v = m[key]
+		}
+		decodeValue(dst.Field(i), v, path+"."+field.Name, report)
+	}
+}
@@ -0,0 +1,116 @@
+package parameters
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+type decodeTestServer struct {
+	Name    string `json:"name"`
+	Port    int
+	Enabled *bool `glazed.parameter:"enabled"`
+}
+
+type decodeTestCommon struct {
+	Verbose bool `glazed.parameter:"verbose"`
+}
+
+type decodeTestSettings struct {
+	decodeTestCommon
+	Count    int64              `glazed.parameter:"count"`
+	Ratio    float32            `glazed.parameter:"ratio"`
+	Limit    *int               `glazed.parameter:"limit"`
+	Timeout  time.Duration      `glazed.parameter:"timeout"`
+	Tags     []string           `glazed.parameter:"tags"`
+	IDs      []uint             `glazed.parameter:"ids"`
+	Token    string             `glazed.parameter:"token"`
+	Labels   map[string]string  `glazed.parameter:"labels"`
+	Servers  []decodeTestServer `glazed.parameter:"servers"`
+	Primary  *decodeTestServer  `glazed.parameter:"primary"`
+	Ignored  string
+	internal string
+}
+
+func TestDecode(t *testing.T) {
+	s := &decodeTestSettings{Limit: nil}
+	err := Decode(map[string]interface{}{
+		"verbose": true,
+		"count":   42,
+		"ratio":   0.5,
+		"limit":   10,
+		"timeout": 5 * time.Second,
+		"tags":    []interface{}{"a", "b"},
+		"ids":     []int{1, 2},
+		"token":   Secret("hunter2"),
+		"labels":  map[string]interface{}{"env": "prod"},
+		"servers": []map[string]interface{}{
+			{"name": "db1", "port": 5432, "enabled": true},
+			{"name": "db2", "PORT": 5433},
+		},
+		"primary": map[string]interface{}{"name": "db1"},
+		"unknown": "foo",
+	}, s)
+	require.NoError(t, err)
+
+	assert.True(t, s.Verbose)
+	assert.Equal(t, int64(42), s.Count)
+	assert.Equal(t, float32(0.5), s.Ratio)
+	require.NotNil(t, s.Limit)
+	assert.Equal(t, 10, *s.Limit)
+	assert.Equal(t, 5*time.Second, s.Timeout)
+	assert.Equal(t, []string{"a", "b"}, s.Tags)
+	assert.Equal(t, []uint{1, 2}, s.IDs)
+	assert.Equal(t, "hunter2", s.Token)
+	assert.Equal(t, map[string]string{"env": "prod"}, s.Labels)
+	require.Len(t, s.Servers, 2)
+	assert.Equal(t, "db1", s.Servers[0].Name)
+	assert.Equal(t, 5432, s.Servers[0].Port)
+	require.NotNil(t, s.Servers[0].Enabled)
+	assert.True(t, *s.Servers[0].Enabled)
+	assert.Equal(t, 5433, s.Servers[1].Port)
+	assert.Nil(t, s.Servers[1].Enabled)
+	assert.Equal(t, "db1", s.Primary.Name)
+
+	// missing parameters leave their field untouched
+	s = &decodeTestSettings{Count: 3}
+	require.NoError(t, Decode(map[string]interface{}{}, s))
+	assert.Equal(t, int64(3), s.Count)
+	assert.Nil(t, s.Limit)
+	assert.Nil(t, s.Primary)
+}
+
+func TestDecodeErrors(t *testing.T) {
+	err := Decode(map[string]interface{}{
+		"count": "not a number",
+		"tags":  "not a list",
+		"servers": []interface{}{
+			map[string]interface{}{"port": "x", "enabled": "maybe"},
+			map[string]interface{}{"port": 5432},
+			map[string]interface{}{"port": "y"},
+		},
+		"labels":  map[string]interface{}{"b": []string{"x"}, "a": "ok", "c": 3.5},
+		"verbose": true,
+	}, &decodeTestSettings{})
+	require.Error(t, err)
+
+	decodeError, ok := err.(*DecodeError)
+	require.True(t, ok)
+	fields := []string{}
+	for _, e := range decodeError.Errors {
+		fields = append(fields, e.Field)
+	}
+	// the errors of nested objects, lists and maps are all collected
+	assert.Equal(t, []string{
+		"Count", "Tags",
+		"Labels[b]", "Labels[c]",
+		"Servers[0].Port", "Servers[0].Enabled", "Servers[2].Port",
+	}, fields)
+	assert.Equal(t, "count", decodeError.Errors[0].Parameter)
+	assert.Equal(t, "not a number", decodeError.Errors[0].Value)
+	assert.Equal(t, "servers", decodeError.Errors[6].Parameter)
+	assert.Equal(t, "y", decodeError.Errors[6].Value)
+
+	assert.Error(t, Decode(map[string]interface{}{}, decodeTestSettings{}))
+}
@@ -0,0 +1,154 @@
+package parameters
+
+import (
+	"github.com/pkg/errors"
+	"github.com/spf13/pflag"
+	"os"
+	"strings"
+)
+
+const (
+	// EnvVarAnnotation is the pflag annotation recording the environment variable a flag
+	// can be read from, so that it can be shown in the help page.
+	EnvVarAnnotation = "glazed:env"
+	// EnvSourceAnnotation is the pflag annotation set on flags whose value was loaded from
+	// the environment by SetFlagsFromEnv, to tell them apart from flags passed by the user.
+	EnvSourceAnnotation = "glazed:env-source"
+)
+
+// WithEnvVar overrides the name of the environment variable the parameter is read from.
+// The name is used as is, without the application prefix.
+func WithEnvVar(name string) ParameterDefinitionOption {
+	return func(p *ParameterDefinition) {
+		p.EnvVar = name
+	}
+}
+
+// EnvVarName returns the name of the environment variable the parameter is read from.
+//
+// Unless overridden with EnvVar, this is the flag name of the parameter (the layer prefix
+// followed by the parameter name) prefixed with appPrefix, uppercased, with dashes and dots
+// replaced by underscores. For example, the parameter "max-rows" of a layer with prefix "sql-"
+// and the app prefix "app" is read from APP_SQL_MAX_ROWS.
+func (p *ParameterDefinition) EnvVarName(appPrefix string, prefix string) string {
+	if p.EnvVar != "" {
+		return p.EnvVar
+	}
+
+	name := prefix + p.Name
+	if appPrefix != "" {
+		name = strings.TrimRight(appPrefix, "_-") + "_" + name
+	}
+	name = strings.NewReplacer("-", "_", ".", "_").Replace(name)
+	return strings.ToUpper(name)
+}
+
+// lookupEnv returns the value of the environment variable of the parameter.
+// Variables that are set to the empty string are considered unset.
+func (p *ParameterDefinition) lookupEnv(appPrefix string, prefix string) (string, string, bool) {
+	name := p.EnvVarName(appPrefix, prefix)
+	v, ok := os.LookupEnv(name)
+	if !ok || v == "" {
+		return name, "", false
+	}
+	return name, v, true
+}
+
+// GatherParametersFromEnv parses the parameters that are set in the environment.
+// The name of the environment variable of each parameter is computed by EnvVarName.
+//
+// Values are parsed with ParseParameter, as if they had been passed on the command line.
+// The values of list parameters (including key-value parameters) are comma separated.
+// Only the parameters present in the environment are returned, defaults are left to the caller.
+func GatherParametersFromEnv(
+	params []*ParameterDefinition,
+	appPrefix string,
+	prefix string,
+) (map[string]interface{}, error) {
+	ret := map[string]interface{}{}
+
+	for _, p := range params {
+		name, v, ok := p.lookupEnv(appPrefix, prefix)
+		if !ok {
+			continue
+		}
+
+		args := []string{v}
+		if IsListParameter(p.Type) {
+			args = strings.Split(v, ",")
+		}
+
+		v_, err := p.ParseParameter(args)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid value for environment variable %s", name)
+		}
+
+		err = p.CheckValueConstraints(v_)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid value for environment variable %s", name)
+		}
+
+		ret[p.Name] = v_
+	}
+
+	return ret, nil
+}
+
+// AddEnvVarsToFlags records the environment variable of each parameter as an annotation
+// of its flag, which is shown next to the flag in the help page.
+func AddEnvVarsToFlags(
+	flagSet *pflag.FlagSet,
+	params []*ParameterDefinition,
+	appPrefix string,
+	prefix string,
+) error {
+	for _, p := range params {
+		flagName := strings.ReplaceAll(prefix+p.Name, "_", "-")
+		if flagSet.Lookup(flagName) == nil {
+			continue
+		}
+		err := flagSet.SetAnnotation(flagName, EnvVarAnnotation, []string{p.EnvVarName(appPrefix, prefix)})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SetFlagsFromEnv sets the flags that were not passed on the command line to the value
+// of their environment variable, if present.
+//
+// The flags are then parsed like any other flag by GatherFlagsFromCobraCommand, which means
+// that environment variables override defaults and config files, but not explicit flags.
+// Flags set from the environment are marked with the EnvSourceAnnotation.
+func SetFlagsFromEnv(
+	flagSet *pflag.FlagSet,
+	params []*ParameterDefinition,
+	appPrefix string,
+	prefix string,
+) error {
+	for _, p := range params {
+		flagName := strings.ReplaceAll(prefix+p.Name, "_", "-")
+		flag := flagSet.Lookup(flagName)
+		if flag == nil || flag.Changed {
+			continue
+		}
+
+		name, v, ok := p.lookupEnv(appPrefix, prefix)
+		if !ok {
+			continue
+		}
+
+		err := flagSet.Set(flagName, v)
+		if err != nil {
+			return errors.Wrapf(err, "invalid value for environment variable %s", name)
+		}
+		err = flagSet.SetAnnotation(flagName, EnvSourceAnnotation, []string{name})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,107 @@
+package parameters
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestEnvVarName(t *testing.T) {
+	p := NewParameterDefinition("max-rows", ParameterTypeInteger)
+	assert.Equal(t, "APP_MAX_ROWS", p.EnvVarName("app", ""))
+	assert.Equal(t, "APP_SQL_MAX_ROWS", p.EnvVarName("APP_", "sql-"))
+	assert.Equal(t, "SQL_MAX_ROWS", p.EnvVarName("", "sql-"))
+
+	p = NewParameterDefinition("max-rows", ParameterTypeInteger, WithEnvVar("ROW_LIMIT"))
+	assert.Equal(t, "ROW_LIMIT", p.EnvVarName("app", "sql-"))
+}
+
+func TestGatherParametersFromEnv(t *testing.T) {
+	params := []*ParameterDefinition{
+		NewParameterDefinition("name", ParameterTypeString, WithDefault("foo")),
+		NewParameterDefinition("count", ParameterTypeInteger, WithMax(10)),
+		NewParameterDefinition("timeout", ParameterTypeDuration),
+		NewParameterDefinition("fields", ParameterTypeStringList),
+		NewParameterDefinition("ids", ParameterTypeIntegerList),
+		NewParameterDefinition("labels", ParameterTypeKeyValue),
+		NewParameterDefinition("verbose", ParameterTypeBool),
+		NewParameterDefinition("unset", ParameterTypeString, WithDefault("bar")),
+		NewParameterDefinition("token", ParameterTypeString, WithEnvVar("MY_TOKEN")),
+	}
+
+	t.Setenv("APP_NAME", "")
+	t.Setenv("APP_COUNT", "3")
+	t.Setenv("APP_TIMEOUT", "2m")
+	t.Setenv("APP_FIELDS", "a,b,c")
+	t.Setenv("APP_IDS", "1,2")
+	t.Setenv("APP_LABELS", "env:prod,team:core")
+	t.Setenv("APP_VERBOSE", "true")
+	t.Setenv("MY_TOKEN", "secret")
+
+	ps, err := GatherParametersFromEnv(params, "app", "")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"count":   3,
+		"timeout": 2 * time.Minute,
+		"fields":  []string{"a", "b", "c"},
+		"ids":     []int{1, 2},
+		"labels":  map[string]interface{}{"env": "prod", "team": "core"},
+		"verbose": true,
+		"token":   "secret",
+	}, ps)
+
+	t.Setenv("APP_COUNT", "12")
+	_, err = GatherParametersFromEnv(params, "app", "")
+	assert.ErrorContains(t, err, "APP_COUNT")
+
+	t.Setenv("APP_COUNT", "twelve")
+	_, err = GatherParametersFromEnv(params, "app", "")
+	assert.ErrorContains(t, err, "APP_COUNT")
+}
+
+func TestSetFlagsFromEnv(t *testing.T) {
+	params := []*ParameterDefinition{
+		NewParameterDefinition("output", ParameterTypeString, WithDefault("table")),
+		NewParameterDefinition("fields", ParameterTypeStringList, WithDefault([]string{"id"})),
+		NewParameterDefinition("limit", ParameterTypeInteger, WithDefault(10)),
+		NewParameterDefinition("host", ParameterTypeString, WithRequired(true)),
+	}
+
+	cmd := &cobra.Command{Use: "test"}
+	require.NoError(t, AddFlagsToCobraCommand(cmd.Flags(), params, "db-"))
+	require.NoError(t, AddEnvVarsToFlags(cmd.Flags(), params, "app", "db-"))
+	assert.Equal(t, []string{"APP_DB_HOST"}, cmd.Flags().Lookup("db-host").Annotations[EnvVarAnnotation])
+
+	t.Setenv("APP_DB_OUTPUT", "json")
+	t.Setenv("APP_DB_FIELDS", "id,name")
+	t.Setenv("APP_DB_LIMIT", "20")
+	t.Setenv("APP_DB_HOST", "localhost")
+
+	// defaults < env < flags
+	require.NoError(t, cmd.ParseFlags([]string{"--db-limit", "30"}))
+	require.NoError(t, SetFlagsFromEnv(cmd.Flags(), params, "app", "db-"))
+	ps, err := GatherFlagsFromCobraCommand(cmd, params, false, false, "db-")
+	require.NoError(t, err)
+	assert.Equal(t, "json", ps["output"])
+	assert.Equal(t, []string{"id", "name"}, ps["fields"])
+	assert.Equal(t, 30, ps["limit"])
+	assert.Equal(t, "localhost", ps["host"])
+
+	assert.Contains(t, cmd.Flags().Lookup("db-output").Annotations, EnvSourceAnnotation)
+	assert.NotContains(t, cmd.Flags().Lookup("db-limit").Annotations, EnvSourceAnnotation)
+
+	// setting the flags again is a no-op
+	require.NoError(t, SetFlagsFromEnv(cmd.Flags(), params, "app", "db-"))
+	ps, err = GatherFlagsFromCobraCommand(cmd, params, false, false, "db-")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"id", "name"}, ps["fields"])
+
+	cmd = &cobra.Command{Use: "test"}
+	require.NoError(t, AddFlagsToCobraCommand(cmd.Flags(), params, "db-"))
+	t.Setenv("APP_DB_LIMIT", "lots")
+	require.NoError(t, cmd.ParseFlags([]string{}))
+	err = SetFlagsFromEnv(cmd.Flags(), params, "app", "db-")
+	assert.ErrorContains(t, err, "APP_DB_LIMIT")
+}
@@ -138,12 +138,37 @@ func GetFileData(filename string) (*FileData, error) {
 		}
 	}
 
-	var parsedContent interface{}
-	isList := false
-	isObject := false
-	var fileType FileType
-	var parseError error
+	parsedContent, isList, isObject, fileType, parseError := parseFileContent(contentBytes, extension)
 
+	return &FileData{
+		Content:          content,
+		ParsedContent:    parsedContent,
+		RawContent:       contentBytes,
+		StringContent:    content,
+		ParseError:       parseError,
+		IsList:           isList,
+		IsObject:         isObject,
+		BaseName:         baseName,
+		Extension:        extension,
+		FileType:         fileType,
+		Path:             filename,
+		RelativePath:     relativePath,
+		AbsolutePath:     absPath,
+		Size:             info.Size(),
+		LastModifiedTime: info.ModTime(),
+		Permissions:      info.Mode(),
+		IsDirectory:      info.IsDir(),
+	}, nil
+}
+
+// parseFileContent parses the content of a file according to its extension.
+func parseFileContent(contentBytes []byte, extension string) (
+	parsedContent interface{},
+	isList bool,
+	isObject bool,
+	fileType FileType,
+	parseError error,
+) {
 	switch extension {
 	case ".json":
 		fileType = JSON
@@ -175,7 +200,7 @@ func GetFileData(filename string) (*FileData, error) {
 
 	case ".csv":
 		fileType = CSV
-		reader := csv.NewReader(strings.NewReader(content))
+		reader := csv.NewReader(strings.NewReader(string(contentBytes)))
 		records, err := reader.ReadAll()
 		if err == nil {
 			isList = true
@@ -189,6 +214,22 @@ func GetFileData(filename string) (*FileData, error) {
 		parsedContent = nil
 	}
 
+	return parsedContent, isList, isObject, fileType, parseError
+}
+
+// GetFileDataFromReader reads a file that is not on the local filesystem, such as an
+// uploaded file, from r. Its type is derived from the extension of filename, and
+// the fields describing its location on disk are left empty.
+func GetFileDataFromReader(r io.Reader, filename string) (*FileData, error) {
+	contentBytes, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	content := string(contentBytes)
+	extension := strings.ToLower(filepath.Ext(filename))
+	parsedContent, isList, isObject, fileType, parseError := parseFileContent(contentBytes, extension)
+
 	return &FileData{
 		Content:          content,
 		ParsedContent:    parsedContent,
@@ -197,15 +238,11 @@ func GetFileData(filename string) (*FileData, error) {
 		ParseError:       parseError,
 		IsList:           isList,
 		IsObject:         isObject,
-		BaseName:         baseName,
+		BaseName:         filepath.Base(filename),
 		Extension:        extension,
 		FileType:         fileType,
 		Path:             filename,
-		RelativePath:     relativePath,
-		AbsolutePath:     absPath,
-		Size:             info.Size(),
-		LastModifiedTime: info.ModTime(),
-		Permissions:      info.Mode(),
-		IsDirectory:      info.IsDir(),
+		Size:             int64(len(contentBytes)),
+		LastModifiedTime: time.Now(),
 	}, nil
 }
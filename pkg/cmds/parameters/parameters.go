@@ -8,6 +8,7 @@ import (
 	"github.com/pkg/errors"
 	"gopkg.in/yaml.v3"
 	"reflect"
+	"regexp"
 	"strings"
 	"time"
 )
@@ -16,6 +17,9 @@ import (
 // A ParameterDefinition can be either a Flag or an Argument.
 // Along with metadata (Name, Help) that is useful for help,
 // it also specifies a Type, a Default value and if it is Required.
+//
+// Values provided by the user can further be restricted with constraints,
+// see CheckValueConstraints.
 type ParameterDefinition struct {
 	Name      string        `yaml:"name"`
 	ShortFlag string        `yaml:"shortFlag,omitempty"`
@@ -24,6 +28,33 @@ type ParameterDefinition struct {
 	Default   interface{}   `yaml:"default,omitempty"`
 	Choices   []string      `yaml:"choices,omitempty"`
 	Required  bool          `yaml:"required,omitempty"`
+
+	// Min and Max bound numbers (and each element of number lists) as well as dates.
+	Min interface{} `yaml:"min,omitempty"`
+	Max interface{} `yaml:"max,omitempty"`
+	// Pattern is a regular expression that strings (and each element of string lists) must match.
+	Pattern string `yaml:"pattern,omitempty"`
+	// MinLength and MaxLength bound the number of characters of strings and the number of elements of lists.
+	MinLength int `yaml:"minLength,omitempty"`
+	MaxLength int `yaml:"maxLength,omitempty"`
+	// FileExists requires strings (and each element of string lists) to be the path of an existing file.
+	FileExists bool `yaml:"fileExists,omitempty"`
+	// Access requires path and directory parameters to be accessible with the given
+	// permissions, a combination of r, w and x.
+	Access string `yaml:"access,omitempty"`
+	// Sensitive masks the value of the parameter wherever it is echoed back (aliases, captured
+	// programs, help defaults, ...), as is done for ParameterTypeSecret parameters.
+	Sensitive bool `yaml:"sensitive,omitempty"`
+	// EnvVar overrides the name of the environment variable the parameter is read from.
+	// See EnvVarName.
+	EnvVar string `yaml:"envVar,omitempty"`
+	// Validators are custom validation functions, which can only be set from Go.
+	Validators []ValidatorFunc `yaml:"-" json:"-"`
+	// Completion overrides the shell completion of the values of the parameter, see Complete.
+	Completion CompletionFunc `yaml:"-" json:"-"`
+
+	// patternRegexp is Pattern compiled by CheckConstraintsDefinition.
+	patternRegexp *regexp.Regexp
 }
 
 func (p *ParameterDefinition) String() string {
@@ -32,13 +63,26 @@ func (p *ParameterDefinition) String() string {
 
 func (p *ParameterDefinition) Copy() *ParameterDefinition {
 	return &ParameterDefinition{
-		Name:      p.Name,
-		ShortFlag: p.ShortFlag,
-		Type:      p.Type,
-		Help:      p.Help,
-		Default:   p.Default,
-		Choices:   p.Choices,
-		Required:  p.Required,
+		Name:       p.Name,
+		ShortFlag:  p.ShortFlag,
+		Type:       p.Type,
+		Help:       p.Help,
+		Default:    p.Default,
+		Choices:    p.Choices,
+		Required:   p.Required,
+		Min:        p.Min,
+		Max:        p.Max,
+		Pattern:    p.Pattern,
+		MinLength:  p.MinLength,
+		MaxLength:  p.MaxLength,
+		FileExists: p.FileExists,
+		Access:     p.Access,
+		Sensitive:  p.Sensitive,
+		EnvVar:     p.EnvVar,
+		Validators: p.Validators,
+		Completion: p.Completion,
+
+		patternRegexp: p.patternRegexp,
 	}
 }
 
@@ -93,11 +137,14 @@ func (p *ParameterDefinition) IsEqualToDefault(i interface{}) bool {
 
 func (p *ParameterDefinition) SetDefaultFromValue(value reflect.Value) error {
 	// check if value is pointer, do nothing if nil, otherwise dereference
+	// unless the pointer itself is a valid value (for example *url.URL)
 	if value.Kind() == reflect.Ptr {
 		if value.IsNil() {
 			return nil
 		}
-		value = value.Elem()
+		if p.CheckValueValidity(value.Interface()) != nil {
+			value = value.Elem()
+		}
 	}
 
 	if p.CheckValueValidity(value.Interface()) != nil {
@@ -153,6 +200,12 @@ func (p *ParameterDefinition) InitializeValueToEmptyValue(value reflect.Value) e
 		value.Set(reflect.ValueOf(nil))
 	case ParameterTypeFileList:
 		value.Set(reflect.ValueOf([]*FileData{}))
+	case ParameterTypeInteger64, ParameterTypeUint, ParameterTypeByteSize, ParameterTypeDuration:
+		return reflect2.SetReflectValue(value, 0)
+	case ParameterTypeURL, ParameterTypeRegexp, ParameterTypeSecret:
+		value.Set(reflect.Zero(value.Type()))
+	case ParameterTypePath, ParameterTypeDirectory:
+		value.SetString("")
 	default:
 		return errors.Errorf("unknown parameter type %s", p.Type)
 	}
@@ -192,6 +245,10 @@ func (p *ParameterDefinition) SetValueFromInterface(value reflect.Value, v inter
 		value.Set(reflect.ValueOf(list))
 
 	case ParameterTypeDate:
+		if t, ok := v.(time.Time); ok {
+			value.Set(reflect.ValueOf(t))
+			return nil
+		}
 		strVal, ok := v.(string)
 		if !ok {
 			return errors.Errorf("expected string value for parameter %s, got %T", p.Name, v)
@@ -240,21 +297,98 @@ func (p *ParameterDefinition) SetValueFromInterface(value reflect.Value, v inter
 		}
 		value.Set(reflect.ValueOf(mapStrVal))
 
+	case ParameterTypeInteger64:
+		return reflect2.SetReflectValue(value, v)
+
+	case ParameterTypeUint:
+		u, err := toUint(v)
+		if err != nil {
+			return errors.Wrapf(err, "error parsing value for parameter %s", p.Name)
+		}
+		return reflect2.SetReflectValue(value, u)
+
+	case ParameterTypeByteSize:
+		size, err := toByteSize(v)
+		if err != nil {
+			return errors.Wrapf(err, "error parsing value for parameter %s", p.Name)
+		}
+		return reflect2.SetReflectValue(value, size)
+
+	case ParameterTypeDuration:
+		d, err := toDuration(v)
+		if err != nil {
+			return errors.Wrapf(err, "error parsing value for parameter %s", p.Name)
+		}
+		return reflect2.SetReflectValue(value, d)
+
+	case ParameterTypeURL:
+		u, err := toURL(v)
+		if err != nil {
+			return errors.Wrapf(err, "error parsing value for parameter %s", p.Name)
+		}
+		return reflect2.SetReflectValue(value, u)
+
+	case ParameterTypeRegexp:
+		re, err := toRegexp(v)
+		if err != nil {
+			return errors.Wrapf(err, "error parsing value for parameter %s", p.Name)
+		}
+		return reflect2.SetReflectValue(value, re)
+
+	case ParameterTypePath, ParameterTypeDirectory:
+		strVal, ok := v.(string)
+		if !ok {
+			return errors.Errorf("expected string value for parameter %s, got %T", p.Name, v)
+		}
+		path, err := ExpandPath(strVal)
+		if err != nil {
+			return err
+		}
+		value.SetString(path)
+
+	case ParameterTypeSecret:
+		secret, err := toSecret(v)
+		if err != nil {
+			return errors.Wrapf(err, "error parsing value for parameter %s", p.Name)
+		}
+		value.SetString(secret.Reveal())
+
 	default:
 		return errors.Errorf("unknown parameter type %s", p.Type)
 	}
 	return nil
 }
 
+type initializeStructOptions struct {
+	optionalPointers bool
+}
+
+type InitializeStructOption func(*initializeStructOptions)
+
+// WithOptionalPointers leaves the pointer fields (other than pointers to structs) nil when
+// their parameter has no default, instead of allocating them. This keeps the struct equal
+// to the one the parameters were created from with NewParameterDefinitionsFromStruct.
+func WithOptionalPointers() InitializeStructOption {
+	return func(o *initializeStructOptions) {
+		o.optionalPointers = true
+	}
+}
+
 // InitializeStructFromParameterDefinitions initializes a struct from a map of parameter definitions.
 //
 // Each field in the struct annotated with tag `glazed.parameter` will be set to the default value of
 // the corresponding `ParameterDefinition`. If no `ParameterDefinition` is found for a field, an error
-// is returned.
+// is returned. Pointer fields are allocated, unless WithOptionalPointers is passed.
 func InitializeStructFromParameterDefinitions(
 	s interface{},
 	parameterDefinitions map[string]*ParameterDefinition,
+	options ...InitializeStructOption,
 ) error {
+	opts := &initializeStructOptions{}
+	for _, o := range options {
+		o(opts)
+	}
+
 	// check that s is indeed a pointer to a struct
 	if reflect.TypeOf(s).Kind() != reflect.Ptr {
 		return errors.Errorf("s is not a pointer")
@@ -276,12 +410,17 @@ func InitializeStructFromParameterDefinitions(
 		}
 		value := reflect.ValueOf(s).Elem().FieldByName(field.Name)
 
+		if opts.optionalPointers && field.Type.Kind() == reflect.Ptr &&
+			field.Type.Elem().Kind() != reflect.Struct && parameter.Default == nil {
+			continue
+		}
+
 		if field.Type.Kind() == reflect.Ptr {
 			if value.IsNil() {
 				value.Set(reflect.New(field.Type.Elem()))
 			}
 			if field.Type.Elem().Kind() == reflect.Struct {
-				err := InitializeStructFromParameterDefinitions(value.Interface(), parameterDefinitions)
+				err := InitializeStructFromParameterDefinitions(value.Interface(), parameterDefinitions, options...)
 				if err != nil {
 					return errors.Wrapf(err, "failed to initialize struct for %s", v)
 				}
@@ -290,6 +429,7 @@ func InitializeStructFromParameterDefinitions(
 				if err != nil {
 					return errors.Wrapf(err, "failed to set value for %s", v)
 				}
+				continue
 			}
 
 		}
@@ -413,6 +553,12 @@ func InitializeStructFromParameters(s interface{}, ps map[string]interface{}) er
 		}
 		value := reflect.ValueOf(s).Elem().FieldByName(field.Name)
 
+		// values such as *url.URL or *regexp.Regexp can be assigned directly
+		if v_ != nil && reflect.TypeOf(v_).AssignableTo(field.Type) {
+			value.Set(reflect.ValueOf(v_))
+			continue
+		}
+
 		if field.Type.Kind() == reflect.Ptr {
 			elem := field.Type.Elem()
 			if value.IsNil() {
@@ -484,6 +630,27 @@ const (
 	ParameterTypeFloatList   ParameterType = "floatList"
 	ParameterTypeChoice      ParameterType = "choice"
 	ParameterTypeChoiceList  ParameterType = "choiceList"
+
+	ParameterTypeInteger64 ParameterType = "int64"
+	ParameterTypeUint      ParameterType = "uint"
+	// ParameterTypeDuration is parsed with time.ParseDuration ("1h30m") into a time.Duration.
+	ParameterTypeDuration ParameterType = "duration"
+	// ParameterTypeURL is an absolute URL, parsed into a *url.URL.
+	ParameterTypeURL ParameterType = "url"
+	// ParameterTypeRegexp is a regular expression, compiled into a *regexp.Regexp.
+	ParameterTypeRegexp ParameterType = "regexp"
+	// ParameterTypeByteSize is a human-readable size ("10MB", "512KiB"), parsed into an int64 number of bytes.
+	// See ParseByteSize.
+	ParameterTypeByteSize ParameterType = "byteSize"
+	// ParameterTypePath is a filesystem path, with a leading ~ expanded to the home directory.
+	// Unlike ParameterTypeFile, the file is not loaded.
+	ParameterTypePath ParameterType = "path"
+	// ParameterTypeDirectory is a ParameterTypePath that must point to an existing directory.
+	ParameterTypeDirectory ParameterType = "directory"
+	// ParameterTypeSecret is a string that is masked wherever it is echoed back, parsed into a Secret.
+	// It can be passed directly, or read from an environment variable (env:NAME) or a file (@path).
+	// See ParseSecret.
+	ParameterTypeSecret ParameterType = "secret"
 )
 
 // IsFileLoadingParameter returns true if the parameter type is one that loads a file, when provided with the given
@@ -503,13 +670,51 @@ func IsFileLoadingParameter(p ParameterType, v string) bool {
 		ParameterTypeFileList:
 
 		return true
-	case ParameterTypeKeyValue:
+	case ParameterTypeKeyValue, ParameterTypeSecret:
 		return strings.HasPrefix(v, "@")
 	default:
 		return false
 	}
 }
 
+// isKnownParameterType returns true if t is one of the ParameterType constants.
+func isKnownParameterType(t ParameterType) bool {
+	switch t {
+	case ParameterTypeString,
+		ParameterTypeStringFromFile,
+		ParameterTypeStringFromFiles,
+		ParameterTypeFile,
+		ParameterTypeFileList,
+		ParameterTypeObjectListFromFile,
+		ParameterTypeObjectListFromFiles,
+		ParameterTypeObjectFromFile,
+		ParameterTypeStringListFromFile,
+		ParameterTypeStringListFromFiles,
+		ParameterTypeKeyValue,
+		ParameterTypeInteger,
+		ParameterTypeFloat,
+		ParameterTypeBool,
+		ParameterTypeDate,
+		ParameterTypeStringList,
+		ParameterTypeIntegerList,
+		ParameterTypeFloatList,
+		ParameterTypeChoice,
+		ParameterTypeChoiceList,
+		ParameterTypeInteger64,
+		ParameterTypeUint,
+		ParameterTypeDuration,
+		ParameterTypeURL,
+		ParameterTypeRegexp,
+		ParameterTypeByteSize,
+		ParameterTypePath,
+		ParameterTypeDirectory,
+		ParameterTypeSecret:
+		return true
+	default:
+		return false
+	}
+}
+
 // IsListParameter returns if the parameter has to be parsed from a list of strings,
 // not if its value is actually a string.
 func IsListParameter(p ParameterType) bool {
@@ -533,9 +738,19 @@ func IsListParameter(p ParameterType) bool {
 // CheckParameterDefaultValueValidity checks if the ParameterDefinition's Default is valid.
 // This is used when validating loading from a YAML file or setting up cobra flag definitions.
 func (p *ParameterDefinition) CheckParameterDefaultValueValidity() error {
+	err := p.CheckConstraintsDefinition()
+	if err != nil {
+		return err
+	}
+
 	// we can have no default
 	v := p.Default
-	return p.CheckValueValidity(v)
+	err = p.CheckValueValidity(v)
+	if err != nil {
+		return err
+	}
+
+	return p.checkDefaultConstraints()
 }
 
 // CheckValueValidity checks if the given value is valid for the ParameterDefinition.
@@ -691,6 +906,55 @@ func (p *ParameterDefinition) CheckValueValidity(v interface{}) error {
 				return errors.Errorf("Value for parameter %s is not a key value list: %v", p.Name, v)
 			}
 		}
+
+	case ParameterTypeInteger64:
+		_, ok := cast.CastNumberInterfaceToInt[int64](v)
+		if !ok {
+			return errors.Errorf("Value for parameter %s is not an integer: %v", p.Name, v)
+		}
+
+	case ParameterTypeUint:
+		_, err := toUint(v)
+		if err != nil {
+			return errors.Wrapf(err, "Value for parameter %s is not an unsigned integer", p.Name)
+		}
+
+	case ParameterTypeByteSize:
+		_, err := toByteSize(v)
+		if err != nil {
+			return errors.Wrapf(err, "Value for parameter %s is not a valid byte size", p.Name)
+		}
+
+	case ParameterTypeDuration:
+		_, err := toDuration(v)
+		if err != nil {
+			return errors.Wrapf(err, "Value for parameter %s is not a valid duration", p.Name)
+		}
+
+	case ParameterTypeURL:
+		_, err := toURL(v)
+		if err != nil {
+			return errors.Wrapf(err, "Value for parameter %s is not a valid URL", p.Name)
+		}
+
+	case ParameterTypeRegexp:
+		_, err := toRegexp(v)
+		if err != nil {
+			return errors.Wrapf(err, "Value for parameter %s is not a valid regular expression", p.Name)
+		}
+
+	case ParameterTypePath, ParameterTypeDirectory:
+		_, ok := v.(string)
+		if !ok {
+			return errors.Errorf("Value for parameter %s is not a path: %v", p.Name, v)
+		}
+
+	case ParameterTypeSecret:
+		switch v.(type) {
+		case string, Secret:
+		default:
+			return errors.Errorf("Value for parameter %s is not a secret: %T", p.Name, v)
+		}
 	}
 
 	return nil
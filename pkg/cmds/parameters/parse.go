@@ -11,6 +11,7 @@ import (
 	"gopkg.in/yaml.v3"
 	"io"
 	"os"
+	"regexp"
 	"strconv"
 	"strings"
 	"time"
@@ -39,6 +40,12 @@ import (
 //   - ParameterTypeObjectFromFile: deserialized a single object from a JSON/YAML file
 //   - ParameterTypeStringFromFile, ParameterTypeStringFromFiles: load file contents as strings
 //   - ParameterTypeStringListFromFile, ParameterTypeStringListFromFiles: load file lines as a string list
+//   - ParameterTypeInteger64, ParameterTypeUint: parsed into int64 and uint
+//   - ParameterTypeDuration: parsed into a time.Duration
+//   - ParameterTypeURL, ParameterTypeRegexp: parsed into a *url.URL and a compiled *regexp.Regexp
+//   - ParameterTypeByteSize: parsed from a human-readable size into an int64 number of bytes
+//   - ParameterTypePath, ParameterTypeDirectory: paths with a leading ~ expanded
+//   - ParameterTypeSecret: a Secret, read from the value itself, an environment variable (env:NAME) or a file (@path)
 //
 // The parsing logic depends on the Type in the ParameterDefinition.
 func (p *ParameterDefinition) ParseParameter(v []string) (interface{}, error) {
@@ -46,7 +53,7 @@ func (p *ParameterDefinition) ParseParameter(v []string) (interface{}, error) {
 		if p.Required {
 			return nil, errors.Errorf("Argument %s not found", p.Name)
 		} else {
-			return p.Default, nil
+			return p.resolveSecret(p.Default)
 		}
 	}
 
@@ -268,6 +275,82 @@ func (p *ParameterDefinition) ParseParameter(v []string) (interface{}, error) {
 			floats = append(floats, f)
 		}
 		return floats, nil
+
+	case ParameterTypeInteger64:
+		if len(v) > 1 {
+			return nil, errors.Errorf("Argument %s must be a single integer", p.Name)
+		}
+		i, err := strconv.ParseInt(v[0], 10, 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Could not parse argument %s as integer", p.Name)
+		}
+		return i, nil
+
+	case ParameterTypeUint:
+		if len(v) > 1 {
+			return nil, errors.Errorf("Argument %s must be a single unsigned integer", p.Name)
+		}
+		i, err := strconv.ParseUint(v[0], 10, 0)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Could not parse argument %s as unsigned integer", p.Name)
+		}
+		return uint(i), nil
+
+	case ParameterTypeDuration:
+		if len(v) > 1 {
+			return nil, errors.Errorf("Argument %s must be a single duration", p.Name)
+		}
+		d, err := time.ParseDuration(v[0])
+		if err != nil {
+			return nil, errors.Wrapf(err, "Could not parse argument %s as duration", p.Name)
+		}
+		return d, nil
+
+	case ParameterTypeURL:
+		if len(v) > 1 {
+			return nil, errors.Errorf("Argument %s must be a single URL", p.Name)
+		}
+		u, err := ParseURL(v[0])
+		if err != nil {
+			return nil, errors.Wrapf(err, "Could not parse argument %s as URL", p.Name)
+		}
+		return u, nil
+
+	case ParameterTypeRegexp:
+		if len(v) > 1 {
+			return nil, errors.Errorf("Argument %s must be a single regular expression", p.Name)
+		}
+		re, err := regexp.Compile(v[0])
+		if err != nil {
+			return nil, errors.Wrapf(err, "Could not parse argument %s as regular expression", p.Name)
+		}
+		return re, nil
+
+	case ParameterTypeByteSize:
+		if len(v) > 1 {
+			return nil, errors.Errorf("Argument %s must be a single byte size", p.Name)
+		}
+		size, err := ParseByteSize(v[0])
+		if err != nil {
+			return nil, errors.Wrapf(err, "Could not parse argument %s as byte size", p.Name)
+		}
+		return size, nil
+
+	case ParameterTypePath, ParameterTypeDirectory:
+		if len(v) > 1 {
+			return nil, errors.Errorf("Argument %s must be a single path", p.Name)
+		}
+		return ExpandPath(v[0])
+
+	case ParameterTypeSecret:
+		if len(v) > 1 {
+			return nil, errors.Errorf("Argument %s must be a single secret", p.Name)
+		}
+		secret, err := ParseSecret(v[0])
+		if err != nil {
+			return nil, errors.Wrapf(err, "Could not parse argument %s as secret", p.Name)
+		}
+		return secret, nil
 	}
 
 	return nil, errors.Errorf("Unknown parameter type %s", p.Type)
@@ -560,14 +643,27 @@ func GatherParametersFromMap(
 				continue
 			}
 			if !ok {
-				ret[name] = p.Default
+				v, err := p.resolveSecret(p.Default)
+				if err != nil {
+					return nil, err
+				}
+				ret[name] = v
 				continue
 			}
 		}
+		v = p.convertJSONIntegers(p.wrapScalar(v))
 		err := p.CheckValueValidity(v)
 		if err != nil {
 			return nil, errors.Wrapf(err, "Invalid value for parameter %s", name)
 		}
+		v, err = p.convertValue(v)
+		if err != nil {
+			return nil, err
+		}
+		err = p.CheckValueConstraints(v)
+		if err != nil {
+			return nil, err
+		}
 		ret[name] = v
 	}
 
@@ -1,6 +1,7 @@
 package parameters
 
 import (
+	"encoding/json"
 	"fmt"
 	"github.com/go-go-golems/glazed/pkg/helpers/cast"
 	"github.com/stretchr/testify/assert"
@@ -871,3 +872,28 @@ func TestParseObjectListFromFileRealFile(t *testing.T) {
 		},
 		v)
 }
+
+func TestGatherParametersFromMapJSONNumbers(t *testing.T) {
+	pds := map[string]*ParameterDefinition{}
+	for _, p := range []*ParameterDefinition{
+		NewParameterDefinition("count", ParameterTypeInteger),
+		NewParameterDefinition("size", ParameterTypeInteger64),
+		NewParameterDefinition("ids", ParameterTypeIntegerList),
+		NewParameterDefinition("ratio", ParameterTypeFloat),
+	} {
+		pds[p.Name] = p
+	}
+
+	m := map[string]interface{}{}
+	require.NoError(t, json.Unmarshal([]byte(`{"count": 3, "size": 4, "ids": [1, 2], "ratio": 1}`), &m))
+	ps, err := GatherParametersFromMap(m, pds, true)
+	require.NoError(t, err)
+	assert.Equal(t, 3, ps["count"])
+	assert.Equal(t, int64(4), ps["size"])
+	assert.Equal(t, []int{1, 2}, ps["ids"])
+	assert.Equal(t, 1.0, ps["ratio"])
+
+	m["count"] = 1.5
+	_, err = GatherParametersFromMap(m, pds, true)
+	assert.Error(t, err)
+}
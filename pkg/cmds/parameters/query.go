@@ -0,0 +1,319 @@
+package parameters
+
+import (
+	"github.com/pkg/errors"
+	"mime/multipart"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// GatherParametersFromQuery gathers the values of the parameters in pds from the values
+// of a query string or of a URL encoded form, looked up by parameter name.
+//
+// Values are parsed with ParseParameter, like command line flags:
+//
+//   - repeated keys are passed as multiple values, and the values of list parameters
+//     can also be comma separated, as in ?columns=a,b&columns=c
+//   - keyValue parameters are set with name[key]=value, or with key:value pairs
+//   - bool parameters are true when given without a value, as in ?verbose, and when
+//     repeated the last value wins, so that a checkbox can override a hidden input
+//   - empty values are ignored, as sent by the empty inputs of a form
+//
+// As the values come from a client, parameters that load files can't be set, and secrets
+// can't reference environment variables or files. Files can be uploaded with
+// GatherParametersFromForm instead.
+//
+// Keys that don't match a parameter are ignored. If onlyProvided is false, missing
+// parameters are set to their default, and a missing required parameter is an error.
+func GatherParametersFromQuery(
+	values url.Values,
+	pds map[string]*ParameterDefinition,
+	onlyProvided bool,
+) (map[string]interface{}, error) {
+	return gatherParametersFromForm(values, nil, pds, onlyProvided)
+}
+
+// GatherParametersFromForm gathers the values of the parameters in pds from a multipart
+// form. The values of the form are parsed like in GatherParametersFromQuery, and the
+// parameters that load files (as well as keyValue parameters) are set from the files
+// uploaded under their name, which are parsed like files given on the command line.
+func GatherParametersFromForm(
+	form *multipart.Form,
+	pds map[string]*ParameterDefinition,
+	onlyProvided bool,
+) (map[string]interface{}, error) {
+	return gatherParametersFromForm(form.Value, form.File, pds, onlyProvided)
+}
+
+// SplitQueryKey splits a query key of the form name[key], used to set keyValue parameters.
+func SplitQueryKey(k string) (name string, key string, ok bool) {
+	i := strings.Index(k, "[")
+	if i <= 0 || !strings.HasSuffix(k, "]") {
+		return k, "", false
+	}
+	return k[:i], k[i+1 : len(k)-1], true
+}
+
+func gatherParametersFromForm(
+	values url.Values,
+	files map[string][]*multipart.FileHeader,
+	pds map[string]*ParameterDefinition,
+	onlyProvided bool,
+) (map[string]interface{}, error) {
+	keys := map[string]map[string]string{}
+	for k, v := range values {
+		name, key, ok := SplitQueryKey(k)
+		if !ok || len(v) == 0 {
+			continue
+		}
+		if _, ok := keys[name]; !ok {
+			keys[name] = map[string]string{}
+		}
+		keys[name][key] = v[len(v)-1]
+	}
+
+	names := make([]string, 0, len(pds))
+	for name := range pds {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	ret := map[string]interface{}{}
+	for _, name := range names {
+		p := pds[name]
+		v, ok, err := p.parseFormValues(values[name], keys[name], files[name])
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			if onlyProvided {
+				continue
+			}
+			if p.Required {
+				return nil, errors.Errorf("Parameter %s is required", name)
+			}
+			v, err = p.resolveSecret(p.Default)
+			if err != nil {
+				return nil, err
+			}
+			ret[name] = v
+			continue
+		}
+
+		err = p.CheckValueConstraints(v)
+		if err != nil {
+			return nil, err
+		}
+		ret[name] = v
+	}
+
+	return ret, nil
+}
+
+// parseFormValues parses the values, the name[key] values and the uploaded files given for
+// the parameter. It returns false if none were given.
+func (p *ParameterDefinition) parseFormValues(
+	values []string,
+	keys map[string]string,
+	files []*multipart.FileHeader,
+) (interface{}, bool, error) {
+	if len(keys) > 0 && p.Type != ParameterTypeKeyValue {
+		return nil, false, errors.Errorf("Argument %s can't be set with keys", p.Name)
+	}
+
+	if p.Type == ParameterTypeBool {
+		if len(files) > 0 {
+			return nil, false, errors.Errorf("Argument %s can't be uploaded", p.Name)
+		}
+		if len(values) == 0 {
+			return nil, false, nil
+		}
+		last := values[len(values)-1]
+		if last == "" {
+			return true, true, nil
+		}
+		v, err := p.ParseParameter([]string{last})
+		return v, true, err
+	}
+
+	values_ := []string{}
+	for _, v := range values {
+		if IsListParameter(p.Type) && p.Type != ParameterTypeKeyValue {
+			for _, v_ := range strings.Split(v, ",") {
+				if v_ != "" {
+					values_ = append(values_, v_)
+				}
+			}
+		} else if v != "" {
+			values_ = append(values_, v)
+		}
+	}
+	values = values_
+
+	if IsFileLoadingParameter(p.Type, "") {
+		if len(values) > 0 {
+			return nil, false, errors.Errorf("Argument %s can't load files, they have to be uploaded", p.Name)
+		}
+		if len(files) == 0 {
+			return nil, false, nil
+		}
+		v, err := p.parseUploadedFiles(files)
+		return v, true, err
+	}
+
+	//exhaustive:ignore
+	switch p.Type {
+	case ParameterTypeKeyValue:
+		if len(values) == 0 && len(keys) == 0 && len(files) == 0 {
+			return nil, false, nil
+		}
+		ret := map[string]interface{}{}
+		for _, fh := range files {
+			v, err := p.parseUploadedFile(fh)
+			if err != nil {
+				return nil, false, err
+			}
+			m, ok := v.(map[string]interface{})
+			if !ok {
+				return nil, false, errors.Errorf("Uploaded file %s does not contain an object", fh.Filename)
+			}
+			for k, v_ := range m {
+				ret[k] = v_
+			}
+		}
+		if len(values) > 0 {
+			if IsFileLoadingParameter(p.Type, values[0]) {
+				return nil, false, errors.Errorf("Argument %s can't load files, they have to be uploaded", p.Name)
+			}
+			v, err := p.ParseParameter(values)
+			if err != nil {
+				return nil, false, err
+			}
+			for k, v_ := range v.(map[string]interface{}) {
+				ret[k] = v_
+			}
+		}
+		for k, v := range keys {
+			ret[k] = v
+		}
+		return ret, true, nil
+
+	case ParameterTypeSecret:
+		if len(files) > 0 {
+			return nil, false, errors.Errorf("Argument %s can't be uploaded", p.Name)
+		}
+		if len(values) == 0 {
+			return nil, false, nil
+		}
+		if len(values) > 1 {
+			return nil, false, errors.Errorf("Argument %s must be a single secret", p.Name)
+		}
+		if IsSecretReference(values[0]) {
+			return nil, false, errors.Errorf("Argument %s can't reference environment variables or files", p.Name)
+		}
+		return Secret(values[0]), true, nil
+	}
+
+	if len(files) > 0 {
+		return nil, false, errors.Errorf("Argument %s can't be uploaded", p.Name)
+	}
+	if len(values) == 0 {
+		return nil, false, nil
+	}
+	v, err := p.ParseParameter(values)
+	return v, true, err
+}
+
+// parseUploadedFiles parses the files uploaded for a parameter that loads files, merging
+// them like ParseParameter merges the files given on the command line.
+func (p *ParameterDefinition) parseUploadedFiles(files []*multipart.FileHeader) (interface{}, error) {
+	//exhaustive:ignore
+	switch p.Type {
+	case ParameterTypeFile, ParameterTypeObjectFromFile, ParameterTypeStringFromFile:
+		if len(files) > 1 {
+			return nil, errors.Errorf("Argument %s must be a single file", p.Name)
+		}
+		return p.parseUploadedFile(files[0])
+
+	case ParameterTypeFileList:
+		ret := []interface{}{}
+		for _, fh := range files {
+			v, err := p.parseUploadedFile(fh)
+			if err != nil {
+				return nil, err
+			}
+			ret = append(ret, v)
+		}
+		return ret, nil
+
+	case ParameterTypeStringFromFiles:
+		res := strings.Builder{}
+		for _, fh := range files {
+			s, err := p.parseUploadedFile(fh)
+			if err != nil {
+				return nil, err
+			}
+			sObj, ok := s.(string)
+			if !ok {
+				return nil, errors.Errorf("Could not parse uploaded file %s as string", fh.Filename)
+			}
+			res.WriteString(sObj)
+		}
+		return res.String(), nil
+
+	case ParameterTypeStringListFromFile, ParameterTypeStringListFromFiles:
+		res := []string{}
+		for _, fh := range files {
+			s, err := p.parseUploadedFile(fh)
+			if err != nil {
+				return nil, err
+			}
+			sObj, ok := s.([]string)
+			if !ok {
+				return nil, errors.Errorf("Could not parse uploaded file %s as string list", fh.Filename)
+			}
+			res = append(res, sObj...)
+		}
+		return res, nil
+
+	case ParameterTypeObjectListFromFile, ParameterTypeObjectListFromFiles:
+		ret := []interface{}{}
+		for _, fh := range files {
+			l, err := p.parseUploadedFile(fh)
+			if err != nil {
+				return nil, err
+			}
+			lObj, ok := l.([]interface{})
+			if !ok {
+				return nil, errors.Errorf("Could not parse uploaded file %s as list of objects", fh.Filename)
+			}
+			ret = append(ret, lObj...)
+		}
+		return ret, nil
+	}
+
+	return nil, errors.Errorf("Argument %s can't be uploaded", p.Name)
+}
+
+// parseUploadedFile parses a single uploaded file, see ParseFromReader.
+func (p *ParameterDefinition) parseUploadedFile(fh *multipart.FileHeader) (interface{}, error) {
+	f, err := fh.Open()
+	if err != nil {
+		return nil, errors.Wrapf(err, "Could not open uploaded file %s", fh.Filename)
+	}
+	defer func(f multipart.File) {
+		_ = f.Close()
+	}(f)
+
+	var ret interface{}
+	if p.Type == ParameterTypeFile || p.Type == ParameterTypeFileList {
+		ret, err = GetFileDataFromReader(f, fh.Filename)
+	} else {
+		ret, err = p.ParseFromReader(f, fh.Filename)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "Could not read uploaded file %s", fh.Filename)
+	}
+	return ret, nil
+}
@@ -0,0 +1,139 @@
+package parameters
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"mime/multipart"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func newQueryTestParameters() map[string]*ParameterDefinition {
+	pds := map[string]*ParameterDefinition{}
+	for _, p := range []*ParameterDefinition{
+		NewParameterDefinition("name", ParameterTypeString, WithDefault("foo")),
+		NewParameterDefinition("count", ParameterTypeInteger, WithMax(10)),
+		NewParameterDefinition("timeout", ParameterTypeDuration),
+		NewParameterDefinition("fields", ParameterTypeStringList),
+		NewParameterDefinition("ids", ParameterTypeIntegerList),
+		NewParameterDefinition("labels", ParameterTypeKeyValue),
+		NewParameterDefinition("verbose", ParameterTypeBool, WithDefault(false)),
+		NewParameterDefinition("token", ParameterTypeSecret),
+		NewParameterDefinition("config", ParameterTypeObjectFromFile),
+		NewParameterDefinition("inputs", ParameterTypeFileList),
+	} {
+		pds[p.Name] = p
+	}
+	return pds
+}
+
+func TestGatherParametersFromQuery(t *testing.T) {
+	pds := newQueryTestParameters()
+
+	values, err := url.ParseQuery("count=3&timeout=2m&fields=a,b&fields=c&ids=1&ids=2" +
+		"&labels=env:prod&labels[team]=core&verbose&token=s3cret&unknown=1")
+	require.NoError(t, err)
+
+	ps, err := GatherParametersFromQuery(values, pds, true)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"count":   3,
+		"timeout": 2 * time.Minute,
+		"fields":  []string{"a", "b", "c"},
+		"ids":     []int{1, 2},
+		"labels":  map[string]interface{}{"env": "prod", "team": "core"},
+		"verbose": true,
+		"token":   Secret("s3cret"),
+	}, ps)
+
+	ps, err = GatherParametersFromQuery(url.Values{"count": {"1"}}, pds, false)
+	require.NoError(t, err)
+	assert.Equal(t, 1, ps["count"])
+	assert.Equal(t, "foo", ps["name"])
+	assert.Equal(t, false, ps["verbose"])
+}
+
+func TestGatherParametersFromQueryFormInputs(t *testing.T) {
+	pds := newQueryTestParameters()
+
+	// a checkbox following a hidden input, and empty text inputs
+	values := url.Values{
+		"verbose": {"false", "on"},
+		"name":    {""},
+		"count":   {""},
+	}
+	ps, err := GatherParametersFromQuery(values, pds, false)
+	require.NoError(t, err)
+	assert.Equal(t, true, ps["verbose"])
+	assert.Equal(t, "foo", ps["name"])
+	assert.Nil(t, ps["count"])
+}
+
+func TestGatherParametersFromQueryErrors(t *testing.T) {
+	pds := newQueryTestParameters()
+	pds["required"] = NewParameterDefinition("required", ParameterTypeString, WithRequired(true))
+
+	for _, query := range []string{
+		"required=x&count=foo",
+		"required=x&count=11",
+		"required=x&config=/etc/passwd",
+		"required=x&labels=@labels.json",
+		"required=x&token=env:HOME",
+		"required=x&name[key]=value",
+		"count=1",
+	} {
+		values, err := url.ParseQuery(query)
+		require.NoError(t, err)
+		_, err = GatherParametersFromQuery(values, pds, false)
+		assert.Error(t, err, query)
+	}
+}
+
+func TestGatherParametersFromForm(t *testing.T) {
+	pds := newQueryTestParameters()
+
+	var b bytes.Buffer
+	w := multipart.NewWriter(&b)
+	require.NoError(t, w.WriteField("name", "bar"))
+	for name, content := range map[string]string{
+		"config": `{"host": "localhost"}`,
+		"labels": `{"env": "prod"}`,
+	} {
+		fw, err := w.CreateFormFile(name, name+".json")
+		require.NoError(t, err)
+		_, err = fw.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	for _, name := range []string{"a.txt", "b.txt"} {
+		fw, err := w.CreateFormFile("inputs", name)
+		require.NoError(t, err)
+		_, err = fw.Write([]byte("content of " + name))
+		require.NoError(t, err)
+	}
+	require.NoError(t, w.Close())
+
+	form, err := multipart.NewReader(&b, w.Boundary()).ReadForm(1 << 20)
+	require.NoError(t, err)
+	form.Value["labels[team]"] = []string{"core"}
+
+	ps, err := GatherParametersFromForm(form, pds, true)
+	require.NoError(t, err)
+	assert.Equal(t, "bar", ps["name"])
+	assert.Equal(t, map[string]interface{}{"host": "localhost"}, ps["config"])
+	assert.Equal(t, map[string]interface{}{"env": "prod", "team": "core"}, ps["labels"])
+
+	inputs, ok := ps["inputs"].([]interface{})
+	require.True(t, ok)
+	require.Len(t, inputs, 2)
+	fd := inputs[1].(*FileData)
+	assert.Equal(t, "b.txt", fd.BaseName)
+	assert.Equal(t, "content of b.txt", fd.Content)
+	assert.Equal(t, TEXT, fd.FileType)
+
+	// only parameters that load files can be uploaded
+	form.File["count"] = form.File["config"]
+	_, err = GatherParametersFromForm(form, pds, true)
+	assert.Error(t, err)
+}
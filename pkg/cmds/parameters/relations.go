@@ -0,0 +1,218 @@
+package parameters
+
+import (
+	"fmt"
+	"github.com/go-go-golems/glazed/pkg/helpers/cast"
+	"github.com/pkg/errors"
+	"reflect"
+	"strings"
+)
+
+// ParameterRelation declares a constraint between several parameters of a command.
+// Exactly one of OneOf, MutuallyExclusive, RequiredTogether or RequiredIf is expected to be set.
+//
+// In YAML, relations are declared as:
+//
+//	relations:
+//	  - oneOf: [file, url]
+//	  - mutuallyExclusive: [json, yaml]
+//	  - requiredTogether: [user, password]
+//	  - requiredIf: { flag: output, equals: excel }
+//	    parameters: [output-file]
+//
+// Relations are checked once all the layers of a command have been parsed and merged,
+// see CheckParameterRelations. A parameter is only considered set if it was explicitly
+// provided (on the command line, in the environment, a config file, ...), so that passing
+// the default value of a parameter still counts.
+type ParameterRelation struct {
+	// OneOf requires exactly one of the parameters to be set.
+	OneOf []string `yaml:"oneOf,omitempty"`
+	// MutuallyExclusive allows at most one of the parameters to be set.
+	MutuallyExclusive []string `yaml:"mutuallyExclusive,omitempty"`
+	// RequiredTogether requires either all or none of the parameters to be set.
+	RequiredTogether []string `yaml:"requiredTogether,omitempty"`
+	// RequiredIf requires Parameters to be set when the condition holds.
+	RequiredIf *ParameterCondition `yaml:"requiredIf,omitempty"`
+	Parameters []string            `yaml:"parameters,omitempty"`
+}
+
+// ParameterCondition holds when the parameter Flag is set, or if Equals is set, when its value
+// is equal to Equals (or contains it, for list parameters).
+type ParameterCondition struct {
+	Flag   string      `yaml:"flag"`
+	Equals interface{} `yaml:"equals,omitempty"`
+}
+
+func NewOneOf(names ...string) *ParameterRelation {
+	return &ParameterRelation{OneOf: names}
+}
+
+func NewMutuallyExclusive(names ...string) *ParameterRelation {
+	return &ParameterRelation{MutuallyExclusive: names}
+}
+
+func NewRequiredTogether(names ...string) *ParameterRelation {
+	return &ParameterRelation{RequiredTogether: names}
+}
+
+// NewRequiredIf requires the given parameters when flag has the value equals.
+// If equals is nil, the parameters are required as soon as flag is set.
+func NewRequiredIf(flag string, equals interface{}, names ...string) *ParameterRelation {
+	return &ParameterRelation{
+		RequiredIf: &ParameterCondition{Flag: flag, Equals: equals},
+		Parameters: names,
+	}
+}
+
+func formatNames(prefix string, names []string) string {
+	ret := []string{}
+	for _, name := range names {
+		ret = append(ret, "--"+prefix+name)
+	}
+	return strings.Join(ret, ", ")
+}
+
+func (c *ParameterCondition) description(prefix string) string {
+	if c.Equals == nil {
+		return fmt.Sprintf("--%s%s is set", prefix, c.Flag)
+	}
+	return fmt.Sprintf("--%s%s is %v", prefix, c.Flag, c.Equals)
+}
+
+// Description returns a human-readable description of the relation, used in help pages.
+// The prefix is prepended to the flag names, as done by layers with a prefix.
+func (r *ParameterRelation) Description(prefix string) string {
+	switch {
+	case len(r.OneOf) > 0:
+		return fmt.Sprintf("exactly one of %s is required", formatNames(prefix, r.OneOf))
+	case len(r.MutuallyExclusive) > 0:
+		return fmt.Sprintf("%s are mutually exclusive", formatNames(prefix, r.MutuallyExclusive))
+	case len(r.RequiredTogether) > 0:
+		return fmt.Sprintf("%s must be used together", formatNames(prefix, r.RequiredTogether))
+	case r.RequiredIf != nil:
+		verb := "is"
+		if len(r.Parameters) > 1 {
+			verb = "are"
+		}
+		return fmt.Sprintf("%s %s required when %s", formatNames(prefix, r.Parameters), verb, r.RequiredIf.description(prefix))
+	default:
+		return ""
+	}
+}
+
+func (r *ParameterRelation) String() string {
+	return r.Description("")
+}
+
+// isSet returns true if the parameter was explicitly provided, with a value that isn't
+// empty or false.
+func isSet(name string, ps map[string]interface{}, provided map[string]bool) bool {
+	if !provided[name] {
+		return false
+	}
+	v, ok := ps[name]
+	if !ok || v == nil {
+		return false
+	}
+
+	rv := reflect.ValueOf(v)
+	//exhaustive:ignore
+	switch rv.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map:
+		return rv.Len() > 0
+	case reflect.Bool:
+		return rv.Bool()
+	default:
+		return true
+	}
+}
+
+func (c *ParameterCondition) holds(ps map[string]interface{}, provided map[string]bool) bool {
+	if c.Equals == nil {
+		return isSet(c.Flag, ps, provided)
+	}
+
+	v, ok := ps[c.Flag]
+	if !ok || v == nil {
+		return false
+	}
+
+	equals := fmt.Sprintf("%v", c.Equals)
+	if _, isString := v.(string); !isString {
+		if l, err := cast.CastListToInterfaceList(v); err == nil {
+			for _, elm := range l {
+				if fmt.Sprintf("%v", elm) == equals {
+					return true
+				}
+			}
+			return false
+		}
+	}
+	return fmt.Sprintf("%v", v) == equals
+}
+
+// Check checks the relation against the parsed parameters. Provided holds the names of
+// the parameters that were explicitly provided, as opposed to set to their default value.
+func (r *ParameterRelation) Check(ps map[string]interface{}, provided map[string]bool) error {
+	countSet := func(names []string) []string {
+		ret := []string{}
+		for _, name := range names {
+			if isSet(name, ps, provided) {
+				ret = append(ret, name)
+			}
+		}
+		return ret
+	}
+
+	switch {
+	case len(r.OneOf) > 0:
+		set := countSet(r.OneOf)
+		if len(set) != 1 {
+			return errors.Errorf("exactly one of %s is required", formatNames("", r.OneOf))
+		}
+
+	case len(r.MutuallyExclusive) > 0:
+		set := countSet(r.MutuallyExclusive)
+		if len(set) > 1 {
+			return errors.Errorf("%s are mutually exclusive", formatNames("", set))
+		}
+
+	case len(r.RequiredTogether) > 0:
+		set := countSet(r.RequiredTogether)
+		if len(set) > 0 && len(set) < len(r.RequiredTogether) {
+			return errors.Errorf("%s must be used together", formatNames("", r.RequiredTogether))
+		}
+
+	case r.RequiredIf != nil:
+		if !r.RequiredIf.holds(ps, provided) {
+			return nil
+		}
+		for _, name := range r.Parameters {
+			if !isSet(name, ps, provided) {
+				return errors.Errorf("--%s is required when %s", name, r.RequiredIf.description(""))
+			}
+		}
+
+	default:
+		return errors.New("empty parameter relation")
+	}
+
+	return nil
+}
+
+// CheckParameterRelations checks all relations against the parsed parameters, and returns
+// the first violated relation as an error. Provided holds the names of the parameters that
+// were explicitly provided.
+func CheckParameterRelations(
+	relations []*ParameterRelation,
+	ps map[string]interface{},
+	provided map[string]bool,
+) error {
+	for _, r := range relations {
+		err := r.Check(ps, provided)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
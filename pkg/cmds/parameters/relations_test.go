@@ -0,0 +1,144 @@
+package parameters
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+	"testing"
+)
+
+func TestParameterRelationsFromYAML(t *testing.T) {
+	s := `
+- oneOf: [file, url]
+- mutuallyExclusive: [json, yaml]
+- requiredTogether: [user, password]
+- requiredIf: { flag: output, equals: excel }
+  parameters: [output-file]
+`
+	var relations []*ParameterRelation
+	require.NoError(t, yaml.Unmarshal([]byte(s), &relations))
+	require.Len(t, relations, 4)
+
+	assert.Equal(t, "exactly one of --file, --url is required", relations[0].String())
+	assert.Equal(t, "--json, --yaml are mutually exclusive", relations[1].String())
+	assert.Equal(t, "--user, --password must be used together", relations[2].String())
+	assert.Equal(t, "--output-file is required when --output is excel", relations[3].String())
+	assert.Equal(t, "--glazed-output-file is required when --glazed-output is excel", relations[3].Description("glazed-"))
+}
+
+func TestCheckParameterRelations(t *testing.T) {
+	testCases := []struct {
+		Name     string
+		Relation *ParameterRelation
+		Ps       map[string]interface{}
+		Provided []string
+		Error    string
+	}{
+		{
+			Name:     "oneOf none set",
+			Relation: NewOneOf("output-file", "verbose"),
+			Ps:       map[string]interface{}{"verbose": false},
+			Provided: []string{"verbose"},
+			Error:    "exactly one of --output-file, --verbose is required",
+		},
+		{
+			Name:     "oneOf one set",
+			Relation: NewOneOf("output-file", "verbose"),
+			Ps:       map[string]interface{}{"verbose": true},
+			Provided: []string{"verbose"},
+		},
+		{
+			Name:     "oneOf both set",
+			Relation: NewOneOf("output-file", "verbose"),
+			Ps:       map[string]interface{}{"verbose": true, "output-file": "foo"},
+			Provided: []string{"verbose", "output-file"},
+			Error:    "exactly one of --output-file, --verbose is required",
+		},
+		{
+			Name:     "oneOf set to the default value",
+			Relation: NewOneOf("limit", "verbose"),
+			Ps:       map[string]interface{}{"limit": 10, "verbose": false},
+			Provided: []string{"limit"},
+		},
+		{
+			Name:     "oneOf not provided",
+			Relation: NewOneOf("limit", "verbose"),
+			Ps:       map[string]interface{}{"limit": 10, "verbose": false},
+			Error:    "exactly one of --limit, --verbose is required",
+		},
+		{
+			Name:     "mutually exclusive with default value",
+			Relation: NewMutuallyExclusive("output", "limit"),
+			Ps:       map[string]interface{}{"output": []string{"table"}, "limit": 20},
+			Provided: []string{"limit"},
+		},
+		{
+			Name:     "mutually exclusive",
+			Relation: NewMutuallyExclusive("output", "limit"),
+			Ps:       map[string]interface{}{"output": []string{"json"}, "limit": 20},
+			Provided: []string{"output", "limit"},
+			Error:    "--output, --limit are mutually exclusive",
+		},
+		{
+			Name:     "required together, none set",
+			Relation: NewRequiredTogether("output-file", "verbose"),
+			Ps:       map[string]interface{}{"output-file": ""},
+			Provided: []string{"output-file"},
+		},
+		{
+			Name:     "required together, one set",
+			Relation: NewRequiredTogether("output-file", "verbose"),
+			Ps:       map[string]interface{}{"output-file": "foo"},
+			Provided: []string{"output-file"},
+			Error:    "--output-file, --verbose must be used together",
+		},
+		{
+			Name:     "required if, condition holds",
+			Relation: NewRequiredIf("output", "excel", "output-file"),
+			Ps:       map[string]interface{}{"output": []string{"excel"}},
+			Provided: []string{"output"},
+			Error:    "--output-file is required when --output is excel",
+		},
+		{
+			Name:     "required if, condition holds and parameter set",
+			Relation: NewRequiredIf("output", "excel", "output-file"),
+			Ps:       map[string]interface{}{"output": []string{"excel"}, "output-file": "out.xlsx"},
+			Provided: []string{"output", "output-file"},
+		},
+		{
+			Name:     "required if, condition doesn't hold",
+			Relation: NewRequiredIf("output", "excel", "output-file"),
+			Ps:       map[string]interface{}{"output": []string{"excel:out.xlsx"}},
+			Provided: []string{"output"},
+		},
+		{
+			Name:     "required if set",
+			Relation: NewRequiredIf("limit", nil, "verbose"),
+			Ps:       map[string]interface{}{"limit": 5},
+			Provided: []string{"limit"},
+			Error:    "--verbose is required when --limit is set",
+		},
+		{
+			Name:     "required if set to the default value",
+			Relation: NewRequiredIf("limit", nil, "verbose"),
+			Ps:       map[string]interface{}{"limit": 10},
+			Provided: []string{"limit"},
+			Error:    "--verbose is required when --limit is set",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			provided := map[string]bool{}
+			for _, name := range tc.Provided {
+				provided[name] = true
+			}
+			err := CheckParameterRelations([]*ParameterRelation{tc.Relation}, tc.Ps, provided)
+			if tc.Error == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.Error)
+			}
+		})
+	}
+}
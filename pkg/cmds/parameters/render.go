@@ -4,15 +4,38 @@ import (
 	"fmt"
 	"github.com/go-go-golems/glazed/pkg/helpers/cast"
 	"github.com/pkg/errors"
+	"net/url"
+	"regexp"
 	"strings"
 	"time"
 )
 
+type renderOptions struct {
+	revealSecrets bool
+}
+
+type RenderOption func(*renderOptions)
+
+// WithRevealSecrets renders the actual value of ParameterTypeSecret parameters
+// instead of RedactedValue.
+func WithRevealSecrets() RenderOption {
+	return func(o *renderOptions) {
+		o.revealSecrets = true
+	}
+}
+
 // RenderValue renders the given value to string so that it can be parsed as a cobra command line flag.
+//
+// Secrets are rendered as RedactedValue, unless WithRevealSecrets is passed.
 // TODO(manuel, 2023-09-09) Refactor rendering of values to strings that can be parsed.
 // This is only applicable to parsing using cobra, but really we now have many more ways of parsing
 // a flag out of a string, among which GET query and FORM input parameters.
-func RenderValue(type_ ParameterType, value interface{}) (string, error) {
+func RenderValue(type_ ParameterType, value interface{}, options ...RenderOption) (string, error) {
+	opts := &renderOptions{}
+	for _, o := range options {
+		o(opts)
+	}
+
 	switch type_ {
 	case ParameterTypeString,
 		ParameterTypeStringFromFile,
@@ -123,6 +146,86 @@ func RenderValue(type_ ParameterType, value interface{}) (string, error) {
 			s = append(s, fmt.Sprintf("%f", i))
 		}
 		return strings.Join(s, ","), nil
+
+	case ParameterTypeInteger64:
+		v, ok := cast.CastNumberInterfaceToInt[int64](value)
+		if !ok {
+			return "", errors.Errorf("expected int64, got %T", value)
+		}
+		return fmt.Sprintf("%d", v), nil
+
+	case ParameterTypeUint:
+		v, err := toUint(value)
+		if err != nil {
+			return "", errors.Errorf("expected uint, got %T", value)
+		}
+		return fmt.Sprintf("%d", v), nil
+
+	case ParameterTypeByteSize:
+		if s, ok := value.(string); ok {
+			return s, nil
+		}
+		v, err := toByteSize(value)
+		if err != nil {
+			return "", errors.Errorf("expected byte size, got %T", value)
+		}
+		return fmt.Sprintf("%d", v), nil
+
+	case ParameterTypeDuration:
+		switch v := value.(type) {
+		case string:
+			return v, nil
+		case time.Duration:
+			return v.String(), nil
+		default:
+			return "", errors.Errorf("expected string or time.Duration, got %T", value)
+		}
+
+	case ParameterTypeURL:
+		switch v := value.(type) {
+		case string:
+			return v, nil
+		case *url.URL:
+			return v.String(), nil
+		default:
+			return "", errors.Errorf("expected string or *url.URL, got %T", value)
+		}
+
+	case ParameterTypeRegexp:
+		switch v := value.(type) {
+		case string:
+			return v, nil
+		case *regexp.Regexp:
+			return v.String(), nil
+		default:
+			return "", errors.Errorf("expected string or *regexp.Regexp, got %T", value)
+		}
+
+	case ParameterTypePath, ParameterTypeDirectory:
+		s, ok := value.(string)
+		if !ok {
+			return "", errors.Errorf("expected string, got %T", value)
+		}
+		return s, nil
+
+	case ParameterTypeSecret:
+		switch v := value.(type) {
+		case Secret:
+			if opts.revealSecrets {
+				return v.Reveal(), nil
+			}
+			return RedactedValue, nil
+		case Revealed:
+			return RenderValue(type_, v.Value, WithRevealSecrets())
+		case string:
+			// references to environment variables and files can be rendered as is
+			if opts.revealSecrets || IsSecretReference(v) {
+				return v, nil
+			}
+			return RedactedValue, nil
+		default:
+			return "", errors.Errorf("expected string or Secret, got %T", value)
+		}
 	}
 
 	return "", errors.Errorf("unknown type %s", type_)
@@ -0,0 +1,194 @@
+package parameters
+
+import (
+	"reflect"
+)
+
+// JSONSchema is the subset of JSON Schema used to describe parameters.
+type JSONSchema struct {
+	Title       string      `json:"title,omitempty" yaml:"title,omitempty"`
+	Type        string      `json:"type,omitempty" yaml:"type,omitempty"`
+	Description string      `json:"description,omitempty" yaml:"description,omitempty"`
+	Default     interface{} `json:"default,omitempty" yaml:"default,omitempty"`
+	Enum        []string    `json:"enum,omitempty" yaml:"enum,omitempty"`
+	Format      string      `json:"format,omitempty" yaml:"format,omitempty"`
+	WriteOnly   bool        `json:"writeOnly,omitempty" yaml:"writeOnly,omitempty"`
+
+	Minimum   interface{} `json:"minimum,omitempty" yaml:"minimum,omitempty"`
+	Maximum   interface{} `json:"maximum,omitempty" yaml:"maximum,omitempty"`
+	Pattern   string      `json:"pattern,omitempty" yaml:"pattern,omitempty"`
+	MinLength int         `json:"minLength,omitempty" yaml:"minLength,omitempty"`
+	MaxLength int         `json:"maxLength,omitempty" yaml:"maxLength,omitempty"`
+	MinItems  int         `json:"minItems,omitempty" yaml:"minItems,omitempty"`
+	MaxItems  int         `json:"maxItems,omitempty" yaml:"maxItems,omitempty"`
+
+	Items                *JSONSchema            `json:"items,omitempty" yaml:"items,omitempty"`
+	Properties           map[string]*JSONSchema `json:"properties,omitempty" yaml:"properties,omitempty"`
+	Required             []string               `json:"required,omitempty" yaml:"required,omitempty"`
+	AdditionalProperties interface{}            `json:"additionalProperties,omitempty" yaml:"additionalProperties,omitempty"`
+}
+
+// ToJSONSchema returns the JSON Schema of the values of the parameter, as they are
+// passed in JSON, for example with --load-parameters-from-json.
+//
+// Choices become an enum, and the constraints of the parameter (see CheckValueConstraints)
+// are mapped to their JSON Schema counterparts. The default of secret and sensitive
+// parameters is left out, and they are marked as writeOnly.
+func (p *ParameterDefinition) ToJSONSchema() *JSONSchema {
+	ret := &JSONSchema{
+		Description: p.Help,
+	}
+
+	// item is the schema constrained by Pattern and Min/Max, the element of lists
+	item := ret
+	isList := false
+
+	switch p.Type {
+	case ParameterTypeString, ParameterTypeStringFromFile, ParameterTypeStringFromFiles,
+		ParameterTypePath, ParameterTypeDirectory, ParameterTypeFile:
+		ret.Type = "string"
+
+	case ParameterTypeSecret:
+		ret.Type = "string"
+		ret.Format = "password"
+
+	case ParameterTypeChoice:
+		ret.Type = "string"
+		ret.Enum = p.Choices
+
+	case ParameterTypeDate:
+		// dates are parsed leniently, and aren't restricted to the date-time format
+		ret.Type = "string"
+
+	case ParameterTypeDuration:
+		ret.Type = "string"
+		ret.Format = "duration"
+
+	case ParameterTypeByteSize:
+		ret.Type = "string"
+		ret.Format = "byte-size"
+
+	case ParameterTypeURL:
+		ret.Type = "string"
+		ret.Format = "uri"
+
+	case ParameterTypeRegexp:
+		ret.Type = "string"
+		ret.Format = "regex"
+
+	case ParameterTypeBool:
+		ret.Type = "boolean"
+
+	case ParameterTypeInteger, ParameterTypeInteger64:
+		ret.Type = "integer"
+
+	case ParameterTypeUint:
+		ret.Type = "integer"
+		if p.Min == nil {
+			ret.Minimum = 0
+		}
+
+	case ParameterTypeFloat:
+		ret.Type = "number"
+
+	case ParameterTypeStringList, ParameterTypeStringListFromFile, ParameterTypeStringListFromFiles,
+		ParameterTypeFileList:
+		item = &JSONSchema{Type: "string"}
+		isList = true
+
+	case ParameterTypeChoiceList:
+		item = &JSONSchema{Type: "string", Enum: p.Choices}
+		isList = true
+
+	case ParameterTypeIntegerList:
+		item = &JSONSchema{Type: "integer"}
+		isList = true
+
+	case ParameterTypeFloatList:
+		item = &JSONSchema{Type: "number"}
+		isList = true
+
+	case ParameterTypeObjectFromFile:
+		ret.Type = "object"
+
+	case ParameterTypeObjectListFromFile, ParameterTypeObjectListFromFiles:
+		item = &JSONSchema{Type: "object"}
+		isList = true
+
+	case ParameterTypeKeyValue:
+		ret.Type = "object"
+		ret.AdditionalProperties = &JSONSchema{Type: "string"}
+	}
+
+	if isList {
+		ret.Type = "array"
+		ret.Items = item
+		ret.MinItems = p.MinLength
+		ret.MaxItems = p.MaxLength
+	} else if item.Type == "string" {
+		item.MinLength = p.MinLength
+		item.MaxLength = p.MaxLength
+	}
+
+	item.Pattern = p.Pattern
+	if item.Type == "integer" || item.Type == "number" {
+		if p.Min != nil {
+			item.Minimum = p.Min
+		}
+		if p.Max != nil {
+			item.Maximum = p.Max
+		}
+	}
+
+	if p.Type == ParameterTypeSecret || p.Sensitive {
+		ret.WriteOnly = true
+	} else if p.Default != nil {
+		ret.Default = p.jsonDefault()
+	}
+
+	return ret
+}
+
+// jsonDefault returns the default of the parameter in the form used in JSON.
+func (p *ParameterDefinition) jsonDefault() interface{} {
+	//exhaustive:ignore
+	switch p.Type {
+	case ParameterTypeDate, ParameterTypeDuration, ParameterTypeByteSize, ParameterTypeURL, ParameterTypeRegexp:
+		s, err := RenderValue(p.Type, p.Default)
+		if err != nil {
+			return nil
+		}
+		return s
+	case ParameterTypeFile, ParameterTypeFileList:
+		return nil
+	}
+
+	// empty lists and maps are a common default, but don't add anything to the schema
+	v := reflect.ValueOf(p.Default)
+	if (v.Kind() == reflect.Slice || v.Kind() == reflect.Map) && v.Len() == 0 {
+		return nil
+	}
+	return p.Default
+}
+
+// ParameterDefinitionsToJSONSchema returns the JSON Schema of an object holding the
+// values of params, keyed by parameter name. If several parameters have the same name,
+// the last one is used.
+func ParameterDefinitionsToJSONSchema(params []*ParameterDefinition) *JSONSchema {
+	ret := &JSONSchema{
+		Type:       "object",
+		Properties: map[string]*JSONSchema{},
+	}
+	required := map[string]bool{}
+	for _, p := range params {
+		ret.Properties[p.Name] = p.ToJSONSchema()
+		required[p.Name] = p.Required
+	}
+	for _, p := range params {
+		if required[p.Name] {
+			ret.Required = append(ret.Required, p.Name)
+			required[p.Name] = false
+		}
+	}
+	return ret
+}
@@ -0,0 +1,68 @@
+package parameters
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestToJSONSchema(t *testing.T) {
+	schema := NewParameterDefinition("format", ParameterTypeChoice,
+		WithHelp("Output format"),
+		WithChoices([]string{"json", "yaml"}),
+		WithDefault("json"),
+	).ToJSONSchema()
+	assert.Equal(t, &JSONSchema{
+		Type:        "string",
+		Description: "Output format",
+		Default:     "json",
+		Enum:        []string{"json", "yaml"},
+	}, schema)
+
+	p := NewParameterDefinition("ids", ParameterTypeIntegerList, WithDefault([]int{}))
+	p.Min = 1
+	p.MaxLength = 3
+	schema = p.ToJSONSchema()
+	assert.Equal(t, &JSONSchema{
+		Type:     "array",
+		MaxItems: 3,
+		Items:    &JSONSchema{Type: "integer", Minimum: 1},
+	}, schema)
+
+	schema = NewParameterDefinition("timeout", ParameterTypeDuration, WithDefault(5*time.Second)).ToJSONSchema()
+	assert.Equal(t, "5s", schema.Default)
+	assert.Equal(t, "duration", schema.Format)
+
+	schema = NewParameterDefinition("token", ParameterTypeSecret, WithDefault("hunter2")).ToJSONSchema()
+	assert.Nil(t, schema.Default)
+	assert.True(t, schema.WriteOnly)
+
+	schema = NewParameterDefinition("labels", ParameterTypeKeyValue).ToJSONSchema()
+	assert.Equal(t, "object", schema.Type)
+	assert.Equal(t, &JSONSchema{Type: "string"}, schema.AdditionalProperties)
+
+	schema = NewParameterDefinition("input", ParameterTypeObjectListFromFile).ToJSONSchema()
+	assert.Equal(t, "array", schema.Type)
+	assert.Equal(t, "object", schema.Items.Type)
+}
+
+func TestParameterDefinitionsToJSONSchema(t *testing.T) {
+	schema := ParameterDefinitionsToJSONSchema([]*ParameterDefinition{
+		NewParameterDefinition("name", ParameterTypeString, WithRequired(true)),
+		NewParameterDefinition("verbose", ParameterTypeBool, WithDefault(false)),
+		NewParameterDefinition("name", ParameterTypeString, WithRequired(true)),
+	})
+
+	b, err := json.Marshal(schema)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"type": "object",
+		"properties": {
+			"name": {"type": "string"},
+			"verbose": {"type": "boolean", "default": false}
+		},
+		"required": ["name"]
+	}`, string(b))
+}
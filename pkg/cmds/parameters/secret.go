@@ -0,0 +1,197 @@
+package parameters
+
+import (
+	"encoding/json"
+	"github.com/pkg/errors"
+	"io"
+	"os"
+	"strings"
+)
+
+// RedactedValue replaces the value of secret and sensitive parameters when they are
+// printed, logged or serialized.
+const RedactedValue = "***"
+
+// Secret is the parsed value of a ParameterTypeSecret parameter. It is masked when printed
+// with fmt, logged, or serialized to YAML or JSON, so that it doesn't leak into aliases,
+// captured programs or debug output. Use Reveal to access the actual value.
+//
+// When initializing a struct with InitializeStructFromParameters, a Secret can be stored
+// in either a Secret or a plain string field.
+type Secret string
+
+func (s Secret) Reveal() string {
+	return string(s)
+}
+
+func (s Secret) String() string {
+	return RedactedValue
+}
+
+func (s Secret) GoString() string {
+	return RedactedValue
+}
+
+func (s Secret) MarshalYAML() (interface{}, error) {
+	return RedactedValue, nil
+}
+
+func (s Secret) MarshalJSON() ([]byte, error) {
+	return json.Marshal(RedactedValue)
+}
+
+// Revealed wraps the value of a secret or sensitive parameter that should be serialized as is.
+// It is the explicit opt-in to reveal secrets, used for example by --reveal-secrets when
+// creating aliases or commands. See RevealParameters.
+type Revealed struct {
+	Value interface{}
+}
+
+func (r Revealed) MarshalYAML() (interface{}, error) {
+	return RevealValue(r.Value), nil
+}
+
+func (r Revealed) MarshalJSON() ([]byte, error) {
+	return json.Marshal(RevealValue(r.Value))
+}
+
+// RevealValue returns the actual value behind a Secret or a Revealed value.
+// Other values are returned as is.
+func RevealValue(v interface{}) interface{} {
+	switch v_ := v.(type) {
+	case Secret:
+		return v_.Reveal()
+	case Revealed:
+		return RevealValue(v_.Value)
+	default:
+		return v
+	}
+}
+
+// WithSensitive marks the parameter as sensitive: its value and default are masked like
+// those of ParameterTypeSecret parameters, while keeping the type of the parameter.
+func WithSensitive(sensitive bool) ParameterDefinitionOption {
+	return func(p *ParameterDefinition) {
+		p.Sensitive = sensitive
+	}
+}
+
+// IsSensitive returns true if values of the parameter should be masked when echoed back.
+func (p *ParameterDefinition) IsSensitive() bool {
+	return p.Sensitive || p.Type == ParameterTypeSecret
+}
+
+// RedactValue returns RedactedValue if the parameter is sensitive, unless v has explicitly
+// been marked as Revealed, in which case the actual value is returned.
+func (p *ParameterDefinition) RedactValue(v interface{}) interface{} {
+	if !p.IsSensitive() || v == nil {
+		return v
+	}
+	if r, ok := v.(Revealed); ok {
+		return RevealValue(r.Value)
+	}
+	return RedactedValue
+}
+
+// RevealParameters returns a copy of ps where the values of sensitive parameters are
+// wrapped in Revealed, so that they are not masked when serialized.
+func RevealParameters(
+	definitions map[string]*ParameterDefinition,
+	ps map[string]interface{},
+) map[string]interface{} {
+	ret := map[string]interface{}{}
+	for k, v := range ps {
+		if p, ok := definitions[k]; ok && p.IsSensitive() && v != nil {
+			v = Revealed{Value: v}
+		}
+		ret[k] = v
+	}
+	return ret
+}
+
+// IsSecretReference returns true if s refers to an environment variable (env:NAME) or to a
+// file (@path) instead of containing the secret itself. References are not masked.
+func IsSecretReference(s string) bool {
+	return strings.HasPrefix(s, "env:") || strings.HasPrefix(s, "@")
+}
+
+// ParseSecret resolves the value of a secret parameter:
+//
+//   - env:NAME reads the environment variable NAME
+//   - @path reads the content of the file at path (@- reads stdin), without the trailing newline
+//   - any other string is the secret itself
+func ParseSecret(s string) (Secret, error) {
+	if strings.HasPrefix(s, "env:") {
+		name := strings.TrimPrefix(s, "env:")
+		v, ok := os.LookupEnv(name)
+		if !ok {
+			return "", errors.Errorf("environment variable %s is not set", name)
+		}
+		return Secret(v), nil
+	}
+
+	if strings.HasPrefix(s, "@") {
+		fileName := s[1:]
+		var b []byte
+		var err error
+		if fileName == "-" {
+			b, err = io.ReadAll(os.Stdin)
+		} else {
+			b, err = os.ReadFile(fileName)
+		}
+		if err != nil {
+			return "", errors.Wrapf(err, "could not read secret from %s", fileName)
+		}
+		return Secret(strings.TrimRight(string(b), "\r\n")), nil
+	}
+
+	return Secret(s), nil
+}
+
+func toSecret(v interface{}) (Secret, error) {
+	switch v_ := v.(type) {
+	case Secret:
+		return v_, nil
+	case string:
+		return ParseSecret(v_)
+	default:
+		return "", errors.Errorf("not a secret: %T", v)
+	}
+}
+
+type parameterDefinition ParameterDefinition
+
+// redacted returns a copy of the definition with the default value masked if the parameter
+// is sensitive. Defaults that are references to environment variables or files are kept.
+func (p *ParameterDefinition) redacted() *parameterDefinition {
+	ret := parameterDefinition(*p)
+	if !p.IsSensitive() || p.Default == nil {
+		return &ret
+	}
+	if s, ok := p.Default.(string); ok && IsSecretReference(s) {
+		return &ret
+	}
+	ret.Default = p.RedactValue(p.Default)
+	return &ret
+}
+
+func (p *ParameterDefinition) MarshalYAML() (interface{}, error) {
+	return p.redacted(), nil
+}
+
+func (p *ParameterDefinition) MarshalJSON() ([]byte, error) {
+	return json.Marshal(p.redacted())
+}
+
+// resolveSecret converts v to a Secret if the parameter is a secret parameter.
+// This is used for default values and for values that are not parsed from strings.
+func (p *ParameterDefinition) resolveSecret(v interface{}) (interface{}, error) {
+	if p.Type != ParameterTypeSecret || v == nil {
+		return v, nil
+	}
+	s, err := toSecret(v)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Invalid value for parameter %s", p.Name)
+	}
+	return s, nil
+}
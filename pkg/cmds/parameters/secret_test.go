@@ -0,0 +1,152 @@
+package parameters
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSecretIsMasked(t *testing.T) {
+	s := Secret("hunter2")
+	assert.Equal(t, "hunter2", s.Reveal())
+	assert.Equal(t, RedactedValue, fmt.Sprintf("%v", s))
+	assert.Equal(t, RedactedValue, fmt.Sprintf("%s", s))
+	assert.Equal(t, RedactedValue, fmt.Sprintf("%#v", s))
+
+	b, err := yaml.Marshal(map[string]interface{}{"token": s})
+	require.NoError(t, err)
+	assert.Equal(t, "token: '***'\n", string(b))
+
+	b, err = json.Marshal(map[string]interface{}{"token": s})
+	require.NoError(t, err)
+	assert.Equal(t, `{"token":"***"}`, string(b))
+
+	b, err = yaml.Marshal(map[string]interface{}{"token": Revealed{Value: s}})
+	require.NoError(t, err)
+	assert.Equal(t, "token: hunter2\n", string(b))
+}
+
+func TestParseSecret(t *testing.T) {
+	t.Setenv("GLAZED_TEST_TOKEN", "from-env")
+	fileName := filepath.Join(t.TempDir(), "token.txt")
+	require.NoError(t, os.WriteFile(fileName, []byte("from-file\n"), 0600))
+
+	p := NewParameterDefinition("token", ParameterTypeSecret)
+	for input, expected := range map[string]string{
+		"literal":               "literal",
+		"env:GLAZED_TEST_TOKEN": "from-env",
+		"@" + fileName:          "from-file",
+	} {
+		v, err := p.ParseParameter([]string{input})
+		require.NoError(t, err)
+		assert.Equal(t, Secret(expected), v)
+	}
+
+	_, err := p.ParseParameter([]string{"env:GLAZED_TEST_DOES_NOT_EXIST"})
+	assert.Error(t, err)
+	_, err = p.ParseParameter([]string{"@" + fileName + ".missing"})
+	assert.Error(t, err)
+}
+
+func TestRenderSecret(t *testing.T) {
+	v, err := RenderValue(ParameterTypeSecret, Secret("hunter2"))
+	require.NoError(t, err)
+	assert.Equal(t, RedactedValue, v)
+
+	v, err = RenderValue(ParameterTypeSecret, Secret("hunter2"), WithRevealSecrets())
+	require.NoError(t, err)
+	assert.Equal(t, "hunter2", v)
+
+	v, err = RenderValue(ParameterTypeSecret, "env:TOKEN")
+	require.NoError(t, err)
+	assert.Equal(t, "env:TOKEN", v)
+}
+
+func TestSensitiveDefaultsAreMasked(t *testing.T) {
+	definitions := []*ParameterDefinition{
+		NewParameterDefinition("token", ParameterTypeSecret, WithDefault("hunter2")),
+		NewParameterDefinition("api-key", ParameterTypeSecret, WithDefault("env:API_KEY")),
+		NewParameterDefinition("password", ParameterTypeString, WithSensitive(true), WithDefault("letmein")),
+		NewParameterDefinition("user", ParameterTypeString, WithDefault("manuel")),
+	}
+
+	b, err := yaml.Marshal(definitions)
+	require.NoError(t, err)
+	s := string(b)
+	assert.NotContains(t, s, "hunter2")
+	assert.NotContains(t, s, "letmein")
+	assert.Contains(t, s, "env:API_KEY")
+	assert.Contains(t, s, "manuel")
+	assert.Contains(t, s, "sensitive: true")
+
+	// the masking doesn't change the definitions themselves
+	var loaded []*ParameterDefinition
+	require.NoError(t, yaml.Unmarshal(b, &loaded))
+	assert.Equal(t, ParameterTypeSecret, loaded[0].Type)
+	assert.Equal(t, "hunter2", definitions[0].Default)
+
+	b, err = json.Marshal(definitions)
+	require.NoError(t, err)
+	assert.NotContains(t, string(b), "hunter2")
+	assert.NotContains(t, string(b), "letmein")
+
+	cmd := &cobra.Command{Use: "test"}
+	require.NoError(t, AddFlagsToCobraCommand(cmd.Flags(), definitions, ""))
+	usage := cmd.Flags().FlagUsages()
+	assert.NotContains(t, usage, "hunter2")
+	assert.NotContains(t, usage, "letmein")
+	assert.Contains(t, usage, "env:API_KEY")
+	assert.Contains(t, usage, "manuel")
+
+	// the actual default is still used when parsing
+	t.Setenv("API_KEY", "from-env")
+	require.NoError(t, cmd.ParseFlags([]string{}))
+	ps, err := GatherFlagsFromCobraCommand(cmd, definitions, false, false, "")
+	require.NoError(t, err)
+	assert.Equal(t, Secret("hunter2"), ps["token"])
+	assert.Equal(t, Secret("from-env"), ps["api-key"])
+	assert.Equal(t, "letmein", ps["password"])
+
+	require.NoError(t, cmd.ParseFlags([]string{"--token", "s3cr3t"}))
+	ps, err = GatherFlagsFromCobraCommand(cmd, definitions, false, false, "")
+	require.NoError(t, err)
+	assert.Equal(t, Secret("s3cr3t"), ps["token"])
+	assert.False(t, strings.Contains(fmt.Sprintf("%v", ps), "s3cr3t"))
+
+	useCmd := &cobra.Command{Use: "test"}
+	assert.Equal(t, "test [token (default: ***)]", GenerateUseString(useCmd, definitions[:1]))
+}
+
+func TestSecretFromMapAndStruct(t *testing.T) {
+	definitions := map[string]*ParameterDefinition{
+		"token": NewParameterDefinition("token", ParameterTypeSecret, WithMinLength(4)),
+	}
+
+	ps, err := GatherParametersFromMap(map[string]interface{}{"token": "hunter2"}, definitions, false)
+	require.NoError(t, err)
+	assert.Equal(t, Secret("hunter2"), ps["token"])
+
+	_, err = GatherParametersFromMap(map[string]interface{}{"token": "abc"}, definitions, false)
+	assert.Error(t, err)
+
+	type settings struct {
+		Token       string `glazed.parameter:"token"`
+		TokenSecret Secret `glazed.parameter:"token"`
+	}
+	s := &settings{}
+	require.NoError(t, InitializeStructFromParameters(s, ps))
+	assert.Equal(t, "hunter2", s.Token)
+	assert.Equal(t, Secret("hunter2"), s.TokenSecret)
+
+	revealed := RevealParameters(definitions, ps)
+	assert.Equal(t, Revealed{Value: Secret("hunter2")}, revealed["token"])
+	assert.Equal(t, "hunter2", definitions["token"].RedactValue(revealed["token"]))
+	assert.Equal(t, RedactedValue, definitions["token"].RedactValue(ps["token"]))
+}
@@ -147,6 +147,10 @@ func GatherFlagsFromStringList(
 		if err != nil {
 			return nil, nil, fmt.Errorf("invalid value for flag --%s: %v", paramName, err)
 		}
+		err = param.CheckValueConstraints(parsedValue)
+		if err != nil {
+			return nil, nil, err
+		}
 		result[param.Name] = parsedValue
 	}
 
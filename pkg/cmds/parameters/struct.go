@@ -0,0 +1,224 @@
+package parameters
+
+import (
+	"github.com/pkg/errors"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	durationType = reflect.TypeOf(time.Duration(0))
+	urlType      = reflect.TypeOf(&url.URL{})
+	regexpType   = reflect.TypeOf(&regexp.Regexp{})
+	secretType   = reflect.TypeOf(Secret(""))
+	fileDataType = reflect.TypeOf(&FileData{})
+)
+
+// ParameterTypeFromGoType returns the ParameterType used for struct fields of type t.
+//
+// Pointers to values are mapped to the type of the value, which makes them optional.
+// Slices and maps of structs are mapped to the objectListFromFile and objectFromFile types,
+// which can be decoded with Decode.
+func ParameterTypeFromGoType(t reflect.Type) (ParameterType, error) {
+	switch t {
+	case timeType:
+		return ParameterTypeDate, nil
+	case durationType:
+		return ParameterTypeDuration, nil
+	case urlType:
+		return ParameterTypeURL, nil
+	case regexpType:
+		return ParameterTypeRegexp, nil
+	case secretType:
+		return ParameterTypeSecret, nil
+	case fileDataType:
+		return ParameterTypeFile, nil
+	}
+
+	//exhaustive:ignore
+	switch t.Kind() {
+	case reflect.String:
+		return ParameterTypeString, nil
+	case reflect.Bool:
+		return ParameterTypeBool, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32:
+		return ParameterTypeInteger, nil
+	case reflect.Int64:
+		return ParameterTypeInteger64, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return ParameterTypeUint, nil
+	case reflect.Float32, reflect.Float64:
+		return ParameterTypeFloat, nil
+	case reflect.Ptr:
+		return ParameterTypeFromGoType(t.Elem())
+	case reflect.Struct:
+		return ParameterTypeObjectFromFile, nil
+
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			break
+		}
+		if t.Elem().Kind() == reflect.String {
+			return ParameterTypeKeyValue, nil
+		}
+		return ParameterTypeObjectFromFile, nil
+
+	case reflect.Slice:
+		elem := t.Elem()
+		if elem == fileDataType {
+			return ParameterTypeFileList, nil
+		}
+		if elem.Kind() == reflect.Ptr {
+			elem = elem.Elem()
+		}
+		//exhaustive:ignore
+		switch elem.Kind() {
+		case reflect.String:
+			return ParameterTypeStringList, nil
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			return ParameterTypeIntegerList, nil
+		case reflect.Float32, reflect.Float64:
+			return ParameterTypeFloatList, nil
+		case reflect.Struct, reflect.Map:
+			return ParameterTypeObjectListFromFile, nil
+		}
+	}
+
+	return "", errors.Errorf("no parameter type for go type %s", t)
+}
+
+// NewParameterDefinitionsFromStruct creates a ParameterDefinition for each field of the
+// struct pointed to by s that is annotated with a `glazed.parameter` tag.
+//
+// The type of the parameter is inferred from the type of the field (see ParameterTypeFromGoType)
+// and can be overridden with a `glazed.type` tag, for example to use a byteSize or path parameter.
+// Unknown types are an error.
+// The other attributes are read from these tags:
+//
+//   - `glazed.help`: the help string
+//   - `glazed.short`: the short flag
+//   - `glazed.choices`: a comma separated list of choices, which turns string fields into
+//     choice parameters and string list fields into choiceList parameters. It is an error
+//     on fields of other types.
+//   - `glazed.required:"true"`: makes the parameter required
+//
+// The value of each field that is not the zero value of its type becomes the default of
+// the parameter. Fields of embedded structs are added as well, while fields tagged
+// with `glazed.layer` are skipped.
+//
+// This is the inverse of InitializeStructFromParameterDefinitions.
+func NewParameterDefinitionsFromStruct(s interface{}) ([]*ParameterDefinition, error) {
+	v := reflect.ValueOf(s)
+	if s == nil || v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return nil, errors.Errorf("can only create parameters from a pointer to a struct, got %T", s)
+	}
+
+	return parameterDefinitionsFromStruct(v.Elem())
+}
+
+func parameterDefinitionsFromStruct(v reflect.Value) ([]*ParameterDefinition, error) {
+	ret := []*ParameterDefinition{}
+
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if _, ok := field.Tag.Lookup("glazed.layer"); ok {
+			continue
+		}
+		name, ok := field.Tag.Lookup("glazed.parameter")
+		if !ok {
+			if !field.Anonymous {
+				continue
+			}
+			embedded := v.Field(i)
+			if embedded.Kind() == reflect.Ptr && embedded.Type().Elem().Kind() == reflect.Struct {
+				if embedded.IsNil() {
+					embedded = reflect.New(embedded.Type().Elem())
+				}
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				pds, err := parameterDefinitionsFromStruct(embedded)
+				if err != nil {
+					return nil, err
+				}
+				ret = append(ret, pds...)
+			}
+			continue
+		}
+
+		p, err := parameterDefinitionFromField(name, field, v.Field(i))
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not create parameter for field %s", field.Name)
+		}
+		ret = append(ret, p)
+	}
+
+	return ret, nil
+}
+
+func parameterDefinitionFromField(name string, field reflect.StructField, value reflect.Value) (*ParameterDefinition, error) {
+	if name == "" {
+		return nil, errors.Errorf("empty parameter name")
+	}
+
+	p := NewParameterDefinition(name, "")
+	p.Help = field.Tag.Get("glazed.help")
+	p.ShortFlag = field.Tag.Get("glazed.short")
+
+	if choices, ok := field.Tag.Lookup("glazed.choices"); ok {
+		for _, choice := range strings.Split(choices, ",") {
+			p.Choices = append(p.Choices, strings.TrimSpace(choice))
+		}
+	}
+
+	if required, ok := field.Tag.Lookup("glazed.required"); ok {
+		switch required {
+		case "true":
+			p.Required = true
+		case "false":
+		default:
+			return nil, errors.Errorf("invalid glazed.required tag %s", required)
+		}
+	}
+
+	if type_, ok := field.Tag.Lookup("glazed.type"); ok {
+		p.Type = ParameterType(type_)
+		if !isKnownParameterType(p.Type) {
+			return nil, errors.Errorf("unknown glazed.type %s", type_)
+		}
+	} else {
+		type_, err := ParameterTypeFromGoType(field.Type)
+		if err != nil {
+			return nil, err
+		}
+		p.Type = type_
+		if len(p.Choices) > 0 {
+			//exhaustive:ignore
+			switch type_ {
+			case ParameterTypeString:
+				p.Type = ParameterTypeChoice
+			case ParameterTypeStringList:
+				p.Type = ParameterTypeChoiceList
+			}
+		}
+	}
+
+	if len(p.Choices) > 0 && p.Type != ParameterTypeChoice && p.Type != ParameterTypeChoiceList {
+		return nil, errors.Errorf("glazed.choices can't be used with a parameter of type %s", p.Type)
+	}
+
+	if !value.IsZero() {
+		err := p.SetDefaultFromValue(value)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return p, nil
+}
@@ -0,0 +1,134 @@
+package parameters
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type structTestCommon struct {
+	Verbose bool `glazed.parameter:"verbose" glazed.short:"v"`
+}
+
+type structTestSettings struct {
+	structTestCommon
+	Host     string            `glazed.parameter:"host" glazed.help:"Database host" glazed.required:"true"`
+	Port     int               `glazed.parameter:"port"`
+	Format   string            `glazed.parameter:"format" glazed.choices:"json, yaml"`
+	Columns  []string          `glazed.parameter:"columns" glazed.choices:"id,name"`
+	Limit    *int              `glazed.parameter:"limit"`
+	Timeout  time.Duration     `glazed.parameter:"timeout"`
+	Since    time.Time         `glazed.parameter:"since"`
+	Endpoint *url.URL          `glazed.parameter:"endpoint"`
+	Password Secret            `glazed.parameter:"password"`
+	MaxSize  int64             `glazed.parameter:"max-size" glazed.type:"byteSize"`
+	Labels   map[string]string `glazed.parameter:"labels"`
+	Ratios   []float64         `glazed.parameter:"ratios"`
+	Ignored  string
+}
+
+func TestNewParameterDefinitionsFromStruct(t *testing.T) {
+	since := time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC)
+	endpoint, _ := url.Parse("https://example.com")
+	s := &structTestSettings{
+		Port:     5432,
+		Format:   "json",
+		Timeout:  5 * time.Second,
+		Since:    since,
+		Endpoint: endpoint,
+		MaxSize:  1024,
+	}
+
+	pds, err := NewParameterDefinitionsFromStruct(s)
+	require.NoError(t, err)
+
+	types := map[string]ParameterType{}
+	defaults := map[string]interface{}{}
+	for _, p := range pds {
+		types[p.Name] = p.Type
+		defaults[p.Name] = p.Default
+	}
+	assert.Equal(t, map[string]ParameterType{
+		"verbose":  ParameterTypeBool,
+		"host":     ParameterTypeString,
+		"port":     ParameterTypeInteger,
+		"format":   ParameterTypeChoice,
+		"columns":  ParameterTypeChoiceList,
+		"limit":    ParameterTypeInteger,
+		"timeout":  ParameterTypeDuration,
+		"since":    ParameterTypeDate,
+		"endpoint": ParameterTypeURL,
+		"password": ParameterTypeSecret,
+		"max-size": ParameterTypeByteSize,
+		"labels":   ParameterTypeKeyValue,
+		"ratios":   ParameterTypeFloatList,
+	}, types)
+	assert.Equal(t, 5432, defaults["port"])
+	assert.Equal(t, "json", defaults["format"])
+	assert.Nil(t, defaults["host"])
+	assert.Nil(t, defaults["limit"])
+
+	assert.Equal(t, "v", pds[0].ShortFlag)
+	assert.Equal(t, "Database host", pds[1].Help)
+	assert.True(t, pds[1].Required)
+	assert.Equal(t, []string{"json", "yaml"}, pds[3].Choices)
+
+	// the defaults initialize a struct equal to the original one
+	m := map[string]*ParameterDefinition{}
+	for _, p := range pds {
+		m[p.Name] = p
+	}
+	s2 := &structTestSettings{}
+	require.NoError(t, InitializeStructFromParameterDefinitions(s2, m, WithOptionalPointers()))
+	assert.Nil(t, s2.Limit)
+	assert.Equal(t, s.Port, s2.Port)
+	assert.Equal(t, s.Format, s2.Format)
+	assert.Equal(t, s.Timeout, s2.Timeout)
+	assert.Equal(t, s.Since, s2.Since)
+	assert.Equal(t, s.Endpoint, s2.Endpoint)
+	assert.Equal(t, s.MaxSize, s2.MaxSize)
+
+	// pointer fields are allocated by default
+	s2 = &structTestSettings{}
+	require.NoError(t, InitializeStructFromParameterDefinitions(s2, m))
+	require.NotNil(t, s2.Limit)
+	assert.Equal(t, 0, *s2.Limit)
+	assert.Equal(t, s.Port, s2.Port)
+}
+
+func TestNewParameterDefinitionsFromStructErrors(t *testing.T) {
+	_, err := NewParameterDefinitionsFromStruct(structTestSettings{})
+	assert.Error(t, err)
+
+	_, err = NewParameterDefinitionsFromStruct(&struct {
+		C chan int `glazed.parameter:"c"`
+	}{})
+	assert.ErrorContains(t, err, "field C")
+
+	_, err = NewParameterDefinitionsFromStruct(&struct {
+		Format string `glazed.parameter:"format" glazed.choices:"json,yaml"`
+	}{Format: "csv"})
+	assert.Error(t, err)
+
+	_, err = NewParameterDefinitionsFromStruct(&struct {
+		Size int64 `glazed.parameter:"size" glazed.type:"bytesize"`
+	}{})
+	assert.ErrorContains(t, err, "unknown glazed.type bytesize")
+
+	_, err = NewParameterDefinitionsFromStruct(&struct {
+		Level int `glazed.parameter:"level" glazed.choices:"1,2,3"`
+	}{})
+	assert.ErrorContains(t, err, "glazed.choices can't be used with a parameter of type int")
+
+	_, err = NewParameterDefinitionsFromStruct(&struct {
+		Format string `glazed.parameter:"format" glazed.type:"choice" glazed.choices:"json,yaml"`
+	}{})
+	assert.NoError(t, err)
+
+	type_, err := ParameterTypeFromGoType(reflect.TypeOf([]map[string]interface{}{}))
+	require.NoError(t, err)
+	assert.Equal(t, ParameterTypeObjectListFromFile, type_)
+}
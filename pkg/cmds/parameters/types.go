@@ -0,0 +1,297 @@
+package parameters
+
+import (
+	"github.com/go-go-golems/glazed/pkg/helpers/cast"
+	"github.com/pkg/errors"
+	"math"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+var byteSizeUnits = map[string]int64{
+	"":    1,
+	"b":   1,
+	"k":   1 << 10,
+	"kb":  1000,
+	"kib": 1 << 10,
+	"m":   1 << 20,
+	"mb":  1000 * 1000,
+	"mib": 1 << 20,
+	"g":   1 << 30,
+	"gb":  1000 * 1000 * 1000,
+	"gib": 1 << 30,
+	"t":   1 << 40,
+	"tb":  1000 * 1000 * 1000 * 1000,
+	"tib": 1 << 40,
+}
+
+// ParseByteSize parses a human-readable byte size such as "512", "10KB", "1.5MiB" or "2g".
+//
+// Units are case-insensitive. KB, MB, GB and TB are powers of 1000, while KiB, MiB, GiB and TiB
+// are powers of 1024. Single-letter units (k, m, g, t) are powers of 1024, as is the convention
+// for most command line tools.
+func ParseByteSize(s string) (int64, error) {
+	s = strings.TrimSpace(s)
+	idx := strings.IndexFunc(s, func(r rune) bool {
+		return !unicode.IsDigit(r) && r != '.'
+	})
+	number, unit := s, ""
+	if idx >= 0 {
+		number, unit = s[:idx], strings.TrimSpace(s[idx:])
+	}
+	if number == "" {
+		return 0, errors.Errorf("invalid byte size %s", s)
+	}
+
+	multiplier, ok := byteSizeUnits[strings.ToLower(unit)]
+	if !ok {
+		return 0, errors.Errorf("invalid byte size unit %s in %s", unit, s)
+	}
+
+	if !strings.Contains(number, ".") {
+		i, err := strconv.ParseInt(number, 10, 64)
+		if err != nil {
+			return 0, errors.Wrapf(err, "invalid byte size %s", s)
+		}
+		if i > math.MaxInt64/multiplier {
+			return 0, errors.Errorf("byte size %s is too large", s)
+		}
+		return i * multiplier, nil
+	}
+
+	f, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		return 0, errors.Wrapf(err, "invalid byte size %s", s)
+	}
+	size := f * float64(multiplier)
+	if size >= math.MaxInt64 {
+		return 0, errors.Errorf("byte size %s is too large", s)
+	}
+	return int64(size), nil
+}
+
+// ParseURL parses an absolute URL. Unlike url.Parse, it requires the URL to have a scheme.
+func ParseURL(s string) (*url.URL, error) {
+	u, err := url.Parse(s)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme == "" {
+		return nil, errors.Errorf("URL %s has no scheme", s)
+	}
+	return u, nil
+}
+
+// ExpandPath replaces a leading ~ with the home directory of the user and cleans the path.
+// Relative paths are kept relative.
+func ExpandPath(path string) (string, error) {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", errors.Wrapf(err, "could not expand path %s", path)
+		}
+		path = filepath.Join(home, path[1:])
+	}
+	return filepath.Clean(path), nil
+}
+
+func toDuration(v interface{}) (time.Duration, error) {
+	switch v_ := v.(type) {
+	case time.Duration:
+		return v_, nil
+	case string:
+		return time.ParseDuration(v_)
+	default:
+		return 0, errors.Errorf("not a duration: %v", v)
+	}
+}
+
+func toURL(v interface{}) (*url.URL, error) {
+	switch v_ := v.(type) {
+	case *url.URL:
+		return v_, nil
+	case string:
+		return ParseURL(v_)
+	default:
+		return nil, errors.Errorf("not a URL: %v", v)
+	}
+}
+
+func toRegexp(v interface{}) (*regexp.Regexp, error) {
+	switch v_ := v.(type) {
+	case *regexp.Regexp:
+		return v_, nil
+	case string:
+		return regexp.Compile(v_)
+	default:
+		return nil, errors.Errorf("not a regular expression: %v", v)
+	}
+}
+
+func toByteSize(v interface{}) (int64, error) {
+	if s, ok := v.(string); ok {
+		return ParseByteSize(s)
+	}
+	if _, ok := v.(uint64); !ok {
+		if i, ok := cast.CastNumberInterfaceToInt[int64](v); ok {
+			return i, nil
+		}
+	}
+	return 0, errors.Errorf("not a byte size: %v", v)
+}
+
+// toUint casts any integer to uint, failing on negative values.
+func toUint(v interface{}) (uint, error) {
+	rv := reflect.ValueOf(v)
+	//exhaustive:ignore
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if rv.Int() < 0 {
+			return 0, errors.Errorf("negative value: %v", v)
+		}
+		return uint(rv.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return uint(rv.Uint()), nil
+	default:
+		return 0, errors.Errorf("not an unsigned integer: %v", v)
+	}
+}
+
+// checkAccess checks that path exists and can be accessed with the given permissions,
+// a combination of r (read), w (write) and x (execute, or traverse for directories).
+func checkAccess(path string, access string) error {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	for _, a := range access {
+		switch a {
+		case 'r', 'w', 'x':
+			err = checkAccessMode(path, fi, a)
+			if err != nil {
+				return err
+			}
+		default:
+			return errors.Errorf("unknown access mode %c", a)
+		}
+	}
+
+	return nil
+}
+
+// convertValue converts values that were not parsed from strings, for example values loaded
+// from JSON or config files, to the Go value that ParseParameter returns for the parameter type.
+// Values of other types are returned as is.
+func (p *ParameterDefinition) convertValue(v interface{}) (interface{}, error) {
+	if v == nil {
+		return nil, nil
+	}
+
+	var ret interface{}
+	var err error
+
+	//exhaustive:ignore
+	switch p.Type {
+	case ParameterTypeInteger64:
+		i, ok := cast.CastNumberInterfaceToInt[int64](v)
+		if !ok {
+			return nil, errors.Errorf("Value for parameter %s is not an integer: %v", p.Name, v)
+		}
+		ret = i
+	case ParameterTypeUint:
+		ret, err = toUint(v)
+	case ParameterTypeDuration:
+		ret, err = toDuration(v)
+	case ParameterTypeURL:
+		ret, err = toURL(v)
+	case ParameterTypeRegexp:
+		ret, err = toRegexp(v)
+	case ParameterTypeByteSize:
+		ret, err = toByteSize(v)
+	case ParameterTypePath, ParameterTypeDirectory:
+		s, ok := v.(string)
+		if !ok {
+			return nil, errors.Errorf("Value for parameter %s is not a path: %v", p.Name, v)
+		}
+		ret, err = ExpandPath(s)
+	case ParameterTypeSecret:
+		return p.resolveSecret(v)
+	case ParameterTypeStringList, ParameterTypeChoiceList:
+		l, ok := cast.CastList2[string, interface{}](v)
+		if !ok {
+			return v, nil
+		}
+		ret = l
+	case ParameterTypeIntegerList:
+		l, ok := cast.CastInterfaceToIntList[int](v)
+		if !ok {
+			return v, nil
+		}
+		ret = l
+	case ParameterTypeFloatList:
+		l, ok := cast.CastInterfaceToFloatList[float64](v)
+		if !ok {
+			return v, nil
+		}
+		ret = l
+	default:
+		return v, nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "Invalid value for parameter %s", p.Name)
+	}
+
+	return ret, nil
+}
+
+// wrapScalar wraps a single value passed to a list parameter in a list, so that
+// `fields: id` can be used instead of `fields: [id]` in JSON and config files.
+func (p *ParameterDefinition) wrapScalar(v interface{}) interface{} {
+	if v == nil || !IsListParameter(p.Type) || p.Type == ParameterTypeKeyValue {
+		return v
+	}
+	//exhaustive:ignore
+	switch reflect.ValueOf(v).Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return v
+	default:
+		return []interface{}{v}
+	}
+}
+
+// convertJSONIntegers converts the float64 values that JSON numbers are decoded to into
+// ints for integer parameters, as long as they are whole numbers.
+func (p *ParameterDefinition) convertJSONIntegers(v interface{}) interface{} {
+	toInt := func(v interface{}) interface{} {
+		f, ok := v.(float64)
+		if !ok || f != math.Trunc(f) || math.Abs(f) > math.MaxInt64 {
+			return v
+		}
+		return int(f)
+	}
+
+	//exhaustive:ignore
+	switch p.Type {
+	case ParameterTypeInteger, ParameterTypeInteger64, ParameterTypeUint:
+		return toInt(v)
+	case ParameterTypeIntegerList:
+		l, ok := v.([]interface{})
+		if !ok {
+			return v
+		}
+		ret := make([]interface{}, len(l))
+		for i, v_ := range l {
+			ret[i] = toInt(v_)
+		}
+		return ret
+	}
+	return v
+}
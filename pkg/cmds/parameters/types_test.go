@@ -0,0 +1,237 @@
+package parameters
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestParseByteSize(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+		wantErr  bool
+	}{
+		{"0", 0, false},
+		{"512", 512, false},
+		{"512B", 512, false},
+		{"10KB", 10000, false},
+		{"10kb", 10000, false},
+		{"10KiB", 10240, false},
+		{"10k", 10240, false},
+		{"1.5MiB", 1572864, false},
+		{"2 GB", 2000000000, false},
+		{"1TiB", 1 << 40, false},
+		{"", 0, true},
+		{"MB", 0, true},
+		{"10 parsecs", 0, true},
+		{"-10MB", 0, true},
+		{"10000000000000TB", 0, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			v, err := ParseByteSize(test.input)
+			if test.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, v)
+		})
+	}
+}
+
+func TestParseNewParameterTypes(t *testing.T) {
+	home, err := os.UserHomeDir()
+	require.NoError(t, err)
+
+	tests := []struct {
+		type_    ParameterType
+		input    string
+		expected interface{}
+	}{
+		{ParameterTypeInteger64, "12345678901", int64(12345678901)},
+		{ParameterTypeUint, "42", uint(42)},
+		{ParameterTypeDuration, "1h30m", 90 * time.Minute},
+		{ParameterTypeURL, "https://example.com/foo?bar=baz", &url.URL{Scheme: "https", Host: "example.com", Path: "/foo", RawQuery: "bar=baz"}},
+		{ParameterTypeRegexp, "^foo.*$", regexp.MustCompile("^foo.*$")},
+		{ParameterTypeByteSize, "10MiB", int64(10 << 20)},
+		{ParameterTypePath, "~/foo/../bar.txt", filepath.Join(home, "bar.txt")},
+		{ParameterTypePath, "foo/bar.txt", "foo/bar.txt"},
+		{ParameterTypeDirectory, ".", "."},
+	}
+
+	for _, test := range tests {
+		t.Run(string(test.type_)+"-"+test.input, func(t *testing.T) {
+			p := NewParameterDefinition("test", test.type_)
+			v, err := p.ParseParameter([]string{test.input})
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, v)
+			require.NoError(t, p.CheckValueValidity(v))
+
+			// rendering the parsed value should give back a parsable string
+			s, err := RenderValue(test.type_, v)
+			require.NoError(t, err)
+			v2, err := p.ParseParameter([]string{s})
+			require.NoError(t, err)
+			assert.Equal(t, v, v2)
+		})
+	}
+
+	invalid := []struct {
+		type_ ParameterType
+		input string
+	}{
+		{ParameterTypeInteger64, "foo"},
+		{ParameterTypeUint, "-1"},
+		{ParameterTypeDuration, "10"},
+		{ParameterTypeURL, "example.com"},
+		{ParameterTypeRegexp, "foo("},
+		{ParameterTypeByteSize, "10XB"},
+	}
+	for _, test := range invalid {
+		t.Run(string(test.type_)+"-"+test.input, func(t *testing.T) {
+			p := NewParameterDefinition("test", test.type_)
+			_, err := p.ParseParameter([]string{test.input})
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestNewParameterTypesCobra(t *testing.T) {
+	params := []*ParameterDefinition{
+		NewParameterDefinition("timeout", ParameterTypeDuration, WithDefault("5s")),
+		NewParameterDefinition("offset", ParameterTypeInteger64, WithDefault(10)),
+		NewParameterDefinition("workers", ParameterTypeUint, WithDefault(4)),
+		NewParameterDefinition("endpoint", ParameterTypeURL, WithDefault("http://localhost:8080")),
+		NewParameterDefinition("match", ParameterTypeRegexp),
+		NewParameterDefinition("max-size", ParameterTypeByteSize, WithDefault("1MB")),
+		NewParameterDefinition("output-dir", ParameterTypeDirectory),
+	}
+
+	cmd := &cobra.Command{Use: "test"}
+	require.NoError(t, AddFlagsToCobraCommand(cmd.Flags(), params, ""))
+	require.NoError(t, cmd.ParseFlags([]string{}))
+	ps, err := GatherFlagsFromCobraCommand(cmd, params, false, false, "")
+	require.NoError(t, err)
+	assert.Equal(t, 5*time.Second, ps["timeout"])
+	assert.Equal(t, int64(10), ps["offset"])
+	assert.Equal(t, uint(4), ps["workers"])
+	assert.Equal(t, "http://localhost:8080", ps["endpoint"].(*url.URL).String())
+	assert.Equal(t, int64(1000000), ps["max-size"])
+	assert.NotContains(t, ps, "match")
+
+	dir := t.TempDir()
+	require.NoError(t, cmd.ParseFlags([]string{
+		"--timeout", "2m",
+		"--offset", "-12345678901",
+		"--workers", "16",
+		"--endpoint", "https://example.com/api",
+		"--match", "^a+$",
+		"--max-size", "2KiB",
+		"--output-dir", dir,
+	}))
+	ps, err = GatherFlagsFromCobraCommand(cmd, params, false, false, "")
+	require.NoError(t, err)
+	assert.Equal(t, 2*time.Minute, ps["timeout"])
+	assert.Equal(t, int64(-12345678901), ps["offset"])
+	assert.Equal(t, uint(16), ps["workers"])
+	assert.Equal(t, "example.com", ps["endpoint"].(*url.URL).Host)
+	assert.True(t, ps["match"].(*regexp.Regexp).MatchString("aaa"))
+	assert.Equal(t, int64(2048), ps["max-size"])
+	assert.Equal(t, dir, ps["output-dir"])
+
+	require.NoError(t, cmd.ParseFlags([]string{"--output-dir", filepath.Join(dir, "missing")}))
+	_, err = GatherFlagsFromCobraCommand(cmd, params, false, false, "")
+	assert.ErrorContains(t, err, "not an existing directory")
+
+	type settings struct {
+		Timeout   time.Duration  `glazed.parameter:"timeout"`
+		Offset    int64          `glazed.parameter:"offset"`
+		Workers   uint           `glazed.parameter:"workers"`
+		Endpoint  *url.URL       `glazed.parameter:"endpoint"`
+		Match     *regexp.Regexp `glazed.parameter:"match"`
+		MaxSize   int64          `glazed.parameter:"max-size"`
+		OutputDir string         `glazed.parameter:"output-dir"`
+	}
+
+	require.NoError(t, cmd.ParseFlags([]string{"--output-dir", dir}))
+	ps, err = GatherFlagsFromCobraCommand(cmd, params, false, false, "")
+	require.NoError(t, err)
+	s := &settings{}
+	require.NoError(t, InitializeStructFromParameters(s, ps))
+	assert.Equal(t, 2*time.Minute, s.Timeout)
+	assert.Equal(t, int64(-12345678901), s.Offset)
+	assert.Equal(t, uint(16), s.Workers)
+	assert.Equal(t, "https://example.com/api", s.Endpoint.String())
+	assert.Equal(t, "^a+$", s.Match.String())
+	assert.Equal(t, int64(2048), s.MaxSize)
+	assert.Equal(t, dir, s.OutputDir)
+}
+
+func TestSetValueFromDefaultNewParameterTypes(t *testing.T) {
+	definitions := map[string]*ParameterDefinition{
+		"timeout":  NewParameterDefinition("timeout", ParameterTypeDuration, WithDefault("1m")),
+		"endpoint": NewParameterDefinition("endpoint", ParameterTypeURL, WithDefault("https://example.com")),
+		"match":    NewParameterDefinition("match", ParameterTypeRegexp, WithDefault("foo")),
+		"max-size": NewParameterDefinition("max-size", ParameterTypeByteSize, WithDefault("1KiB")),
+		"workers":  NewParameterDefinition("workers", ParameterTypeUint, WithDefault(3)),
+	}
+
+	type settings struct {
+		Timeout  time.Duration  `glazed.parameter:"timeout"`
+		Endpoint *url.URL       `glazed.parameter:"endpoint"`
+		Match    *regexp.Regexp `glazed.parameter:"match"`
+		MaxSize  int64          `glazed.parameter:"max-size"`
+		Workers  uint           `glazed.parameter:"workers"`
+	}
+
+	s := &settings{}
+	require.NoError(t, InitializeStructFromParameterDefinitions(s, definitions))
+	assert.Equal(t, time.Minute, s.Timeout)
+	assert.Equal(t, "https://example.com", s.Endpoint.String())
+	assert.Equal(t, "foo", s.Match.String())
+	assert.Equal(t, int64(1024), s.MaxSize)
+	assert.Equal(t, uint(3), s.Workers)
+
+	s.Timeout = time.Hour
+	s.Endpoint, _ = url.Parse("https://example.org")
+	require.NoError(t, InitializeParameterDefinitionsFromStruct(definitions, s))
+	assert.Equal(t, time.Hour, definitions["timeout"].Default)
+	assert.Equal(t, s.Endpoint, definitions["endpoint"].Default)
+}
+
+func TestPathAccess(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "file.txt")
+	require.NoError(t, os.WriteFile(file, []byte("foo"), 0444))
+
+	p := NewParameterDefinition("path", ParameterTypePath, WithAccess("r"))
+	require.NoError(t, p.CheckConstraintsDefinition())
+	require.NoError(t, p.CheckValueConstraints(file))
+	assert.Error(t, p.CheckValueConstraints(filepath.Join(dir, "missing.txt")))
+
+	p = NewParameterDefinition("dir", ParameterTypeDirectory, WithAccess("rw"))
+	require.NoError(t, p.CheckValueConstraints(dir))
+	assert.ErrorContains(t, p.CheckValueConstraints(file), "not an existing directory")
+	// checking the access doesn't write to the directory
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+
+	p = NewParameterDefinition("path", ParameterTypePath, WithAccess("x"))
+	assert.Error(t, p.CheckValueConstraints(file))
+
+	p = NewParameterDefinition("path", ParameterTypePath, WithAccess("rz"))
+	assert.Error(t, p.CheckConstraintsDefinition())
+
+	p = NewParameterDefinition("name", ParameterTypeString, WithAccess("r"))
+	assert.Error(t, p.CheckConstraintsDefinition())
+}
@@ -0,0 +1,432 @@
+package parameters
+
+import (
+	"github.com/go-go-golems/glazed/pkg/helpers/cast"
+	"github.com/pkg/errors"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ValidatorFunc is a custom validation function that can be attached to a ParameterDefinition
+// with WithValidator. It is called with the parsed value of the parameter.
+type ValidatorFunc func(v interface{}) error
+
+func WithMin(min interface{}) ParameterDefinitionOption {
+	return func(p *ParameterDefinition) {
+		p.Min = min
+	}
+}
+
+func WithMax(max interface{}) ParameterDefinitionOption {
+	return func(p *ParameterDefinition) {
+		p.Max = max
+	}
+}
+
+func WithPattern(pattern string) ParameterDefinitionOption {
+	return func(p *ParameterDefinition) {
+		p.Pattern = pattern
+	}
+}
+
+func WithMinLength(minLength int) ParameterDefinitionOption {
+	return func(p *ParameterDefinition) {
+		p.MinLength = minLength
+	}
+}
+
+func WithMaxLength(maxLength int) ParameterDefinitionOption {
+	return func(p *ParameterDefinition) {
+		p.MaxLength = maxLength
+	}
+}
+
+func WithFileExists(fileExists bool) ParameterDefinitionOption {
+	return func(p *ParameterDefinition) {
+		p.FileExists = fileExists
+	}
+}
+
+// WithAccess requires path and directory parameters to be accessible with the given
+// permissions, for example "r" or "rw".
+func WithAccess(access string) ParameterDefinitionOption {
+	return func(p *ParameterDefinition) {
+		p.Access = access
+	}
+}
+
+func WithValidator(validator ValidatorFunc) ParameterDefinitionOption {
+	return func(p *ParameterDefinition) {
+		p.Validators = append(p.Validators, validator)
+	}
+}
+
+// HasConstraints returns true if any constraint is set on the ParameterDefinition.
+func (p *ParameterDefinition) HasConstraints() bool {
+	return p.Min != nil || p.Max != nil || p.Pattern != "" ||
+		p.MinLength > 0 || p.MaxLength > 0 || p.FileExists || p.Access != "" || len(p.Validators) > 0
+}
+
+// CheckConstraintsDefinition checks that the constraints can be applied to the type of the
+// ParameterDefinition, for example that Min is a number for numeric parameters, or that
+// Pattern is a valid regular expression.
+func (p *ParameterDefinition) CheckConstraintsDefinition() error {
+	for _, bound := range []interface{}{p.Min, p.Max} {
+		if bound == nil {
+			continue
+		}
+		switch {
+		case isNumericParameter(p.Type):
+			if _, err := p.toNumber(bound); err != nil {
+				return errors.Errorf("min/max of parameter %s must be a number: %v", p.Name, bound)
+			}
+		case p.Type == ParameterTypeDate:
+			if _, err := toDate(bound); err != nil {
+				return errors.Wrapf(err, "min/max of parameter %s must be a date", p.Name)
+			}
+		default:
+			return errors.Errorf("min/max can't be used with parameter %s of type %s", p.Name, p.Type)
+		}
+	}
+
+	if p.Pattern != "" {
+		re, err := regexp.Compile(p.Pattern)
+		if err != nil {
+			return errors.Wrapf(err, "invalid pattern for parameter %s", p.Name)
+		}
+		p.patternRegexp = re
+	}
+
+	if p.MinLength < 0 || p.MaxLength < 0 {
+		return errors.Errorf("minLength/maxLength of parameter %s can't be negative", p.Name)
+	}
+
+	if p.Access != "" {
+		if p.Type != ParameterTypePath && p.Type != ParameterTypeDirectory {
+			return errors.Errorf("access can't be used with parameter %s of type %s", p.Name, p.Type)
+		}
+		if strings.Trim(p.Access, "rwx") != "" {
+			return errors.Errorf("access of parameter %s must be a combination of r, w and x: %s", p.Name, p.Access)
+		}
+	}
+
+	return nil
+}
+
+// CheckValueConstraints checks the given value against the constraints of the ParameterDefinition:
+// Min and Max for numbers and dates, Pattern for strings, MinLength and MaxLength for strings
+// and lists, FileExists and Access for paths and the custom Validators.
+//
+// Values of ParameterTypeDirectory must always point to an existing directory.
+//
+// Constraints are applied to each element of list values, except for MinLength and MaxLength,
+// which are applied to the length of the list.
+func (p *ParameterDefinition) CheckValueConstraints(v interface{}) error {
+	if v == nil {
+		return nil
+	}
+
+	if err := p.checkPath(v); err != nil {
+		return err
+	}
+
+	if !p.HasConstraints() {
+		return nil
+	}
+
+	// validators get the Secret itself, the other constraints apply to the actual value
+	validated := v
+	if s, ok := v.(Secret); ok {
+		v = s.Reveal()
+	}
+
+	if err := p.checkBounds(v); err != nil {
+		return err
+	}
+	if err := p.checkLength(v); err != nil {
+		return err
+	}
+	if err := p.checkStrings(v); err != nil {
+		return err
+	}
+
+	for _, validator := range p.Validators {
+		if err := validator(validated); err != nil {
+			return errors.Wrapf(err, "Value for parameter %s is invalid", p.Name)
+		}
+	}
+
+	return nil
+}
+
+// checkDefaultConstraints checks the Default of the ParameterDefinition against its constraints.
+//
+// Paths and FileExists are only checked when the parameter is parsed, since the default
+// might only exist on the machine the command runs on, and secrets that reference
+// environment variables or files are not resolved.
+func (p *ParameterDefinition) checkDefaultConstraints() error {
+	if p.Default == nil || p.Type == ParameterTypePath || p.Type == ParameterTypeDirectory {
+		return nil
+	}
+
+	v := p.Default
+	if p.Type == ParameterTypeSecret {
+		s, ok := v.(string)
+		if !ok || IsSecretReference(s) {
+			return nil
+		}
+		v = Secret(s)
+	} else {
+		var err error
+		v, err = p.convertValue(v)
+		if err != nil {
+			return err
+		}
+	}
+
+	p_ := *p
+	p_.FileExists = false
+	err := p_.CheckValueConstraints(v)
+	if err != nil {
+		return errors.Wrap(err, "invalid default")
+	}
+	return nil
+}
+
+func isNumericParameter(t ParameterType) bool {
+	//exhaustive:ignore
+	switch t {
+	case ParameterTypeInteger, ParameterTypeFloat, ParameterTypeIntegerList, ParameterTypeFloatList,
+		ParameterTypeInteger64, ParameterTypeUint, ParameterTypeByteSize, ParameterTypeDuration:
+		return true
+	default:
+		return false
+	}
+}
+
+// toNumber converts a value or a min/max bound of a numeric parameter to a float64.
+// Byte sizes and durations can also be given as strings, such as "10MB" or "1m30s".
+func (p *ParameterDefinition) toNumber(v interface{}) (float64, error) {
+	//exhaustive:ignore
+	switch p.Type {
+	case ParameterTypeByteSize:
+		if s, ok := v.(string); ok {
+			size, err := ParseByteSize(s)
+			if err != nil {
+				return 0, err
+			}
+			v = size
+		}
+	case ParameterTypeDuration:
+		switch v.(type) {
+		case string, time.Duration:
+			d, err := toDuration(v)
+			if err != nil {
+				return 0, err
+			}
+			v = int64(d)
+		}
+	}
+
+	f, ok := cast.CastNumberInterfaceToFloat[float64](v)
+	if !ok {
+		return 0, errors.Errorf("not a number: %v", v)
+	}
+	return f, nil
+}
+
+func toDate(v interface{}) (time.Time, error) {
+	switch v_ := v.(type) {
+	case time.Time:
+		return v_, nil
+	case string:
+		return ParseDate(v_)
+	default:
+		return time.Time{}, errors.Errorf("not a date: %v", v)
+	}
+}
+
+func (p *ParameterDefinition) checkBounds(v interface{}) error {
+	if p.Min == nil && p.Max == nil {
+		return nil
+	}
+
+	if p.Type == ParameterTypeDate {
+		d, err := toDate(v)
+		if err != nil {
+			return errors.Wrapf(err, "Value for parameter %s is not a valid date", p.Name)
+		}
+		if p.Min != nil {
+			min, err := toDate(p.Min)
+			if err != nil {
+				return errors.Wrapf(err, "invalid min for parameter %s", p.Name)
+			}
+			if d.Before(min) {
+				return errors.Errorf("Value for parameter %s must not be before %v: %v", p.Name, p.Min, v)
+			}
+		}
+		if p.Max != nil {
+			max, err := toDate(p.Max)
+			if err != nil {
+				return errors.Wrapf(err, "invalid max for parameter %s", p.Name)
+			}
+			if d.After(max) {
+				return errors.Errorf("Value for parameter %s must not be after %v: %v", p.Name, p.Max, v)
+			}
+		}
+		return nil
+	}
+
+	if !isNumericParameter(p.Type) {
+		return nil
+	}
+
+	values := []interface{}{v}
+	if l, err := cast.CastListToInterfaceList(v); err == nil {
+		values = l
+	}
+
+	for _, value := range values {
+		f, err := p.toNumber(value)
+		if err != nil {
+			return errors.Wrapf(err, "Value for parameter %s is not valid", p.Name)
+		}
+		if p.Min != nil {
+			min, err := p.toNumber(p.Min)
+			if err != nil {
+				return errors.Errorf("invalid min for parameter %s: %v", p.Name, p.Min)
+			}
+			if f < min {
+				return errors.Errorf("Value for parameter %s must be at least %v: %v", p.Name, p.Min, value)
+			}
+		}
+		if p.Max != nil {
+			max, err := p.toNumber(p.Max)
+			if err != nil {
+				return errors.Errorf("invalid max for parameter %s: %v", p.Name, p.Max)
+			}
+			if f > max {
+				return errors.Errorf("Value for parameter %s must be at most %v: %v", p.Name, p.Max, value)
+			}
+		}
+	}
+
+	return nil
+}
+
+// length returns the number of characters of a string value, or the number of elements of a list.
+func length(v interface{}) (int, bool) {
+	if s, ok := v.(string); ok {
+		return utf8.RuneCountInString(s), true
+	}
+	if l, err := cast.CastListToInterfaceList(v); err == nil {
+		return len(l), true
+	}
+	return 0, false
+}
+
+func (p *ParameterDefinition) checkLength(v interface{}) error {
+	if p.MinLength == 0 && p.MaxLength == 0 {
+		return nil
+	}
+
+	l, ok := length(v)
+	if !ok {
+		return nil
+	}
+
+	unit := "characters"
+	if _, ok := v.(string); !ok {
+		unit = "elements"
+	}
+
+	if p.MinLength > 0 && l < p.MinLength {
+		return errors.Errorf("Value for parameter %s must have at least %d %s: %v", p.Name, p.MinLength, unit, v)
+	}
+	if p.MaxLength > 0 && l > p.MaxLength {
+		return errors.Errorf("Value for parameter %s must have at most %d %s: %v", p.Name, p.MaxLength, unit, v)
+	}
+
+	return nil
+}
+
+// checkStrings checks the Pattern and FileExists constraints against a string value,
+// or each element of a string list.
+func (p *ParameterDefinition) checkStrings(v interface{}) error {
+	if p.Pattern == "" && !p.FileExists {
+		return nil
+	}
+
+	var values []string
+	switch v_ := v.(type) {
+	case string:
+		values = []string{v_}
+	default:
+		l, ok := cast.CastList2[string, interface{}](v)
+		if !ok {
+			return nil
+		}
+		values = l
+	}
+
+	var re *regexp.Regexp
+	if p.Pattern != "" {
+		re = p.patternRegexp
+		// the pattern is compiled by CheckConstraintsDefinition, unless the definition
+		// wasn't checked or has been modified since
+		if re == nil || re.String() != p.Pattern {
+			var err error
+			re, err = regexp.Compile(p.Pattern)
+			if err != nil {
+				return errors.Wrapf(err, "invalid pattern for parameter %s", p.Name)
+			}
+		}
+	}
+
+	for _, s := range values {
+		if re != nil && !re.MatchString(s) {
+			return errors.Errorf("Value for parameter %s does not match pattern %s: %s", p.Name, p.Pattern, s)
+		}
+		if p.FileExists {
+			if _, err := os.Stat(s); err != nil {
+				return errors.Errorf("Value for parameter %s is not an existing file: %s", p.Name, s)
+			}
+		}
+	}
+
+	return nil
+}
+
+// checkPath checks that directory parameters point to an existing directory, and that
+// path and directory parameters have the permissions required by Access.
+func (p *ParameterDefinition) checkPath(v interface{}) error {
+	if p.Type != ParameterTypePath && p.Type != ParameterTypeDirectory {
+		return nil
+	}
+	path, ok := v.(string)
+	if !ok {
+		return errors.Errorf("Value for parameter %s is not a path: %v", p.Name, v)
+	}
+	path, err := ExpandPath(path)
+	if err != nil {
+		return err
+	}
+
+	if p.Type == ParameterTypeDirectory {
+		fi, err := os.Stat(path)
+		if err != nil || !fi.IsDir() {
+			return errors.Errorf("Value for parameter %s is not an existing directory: %s", p.Name, path)
+		}
+	}
+
+	if p.Access != "" {
+		if err := checkAccess(path, p.Access); err != nil {
+			return errors.Wrapf(err, "Value for parameter %s is not accessible with permissions %s", p.Name, p.Access)
+		}
+	}
+
+	return nil
+}
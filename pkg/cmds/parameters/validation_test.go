@@ -0,0 +1,168 @@
+package parameters
+
+import (
+	_ "embed"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+	"testing"
+	"time"
+)
+
+//go:embed "test-data/parameters_constraints_test.yaml"
+var constraintsTestYaml []byte
+
+type ConstraintsTest struct {
+	Name      string               `yaml:"name"`
+	Parameter *ParameterDefinition `yaml:"parameter"`
+	Value     interface{}          `yaml:"value"`
+	Valid     bool                 `yaml:"valid"`
+}
+
+func TestValueConstraints(t *testing.T) {
+	var tests []*ConstraintsTest
+	err := yaml.Unmarshal(constraintsTestYaml, &tests)
+	require.NoError(t, err)
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			require.NoError(t, test.Parameter.CheckConstraintsDefinition())
+
+			err := test.Parameter.CheckValueConstraints(test.Value)
+			if test.Valid {
+				assert.NoError(t, err)
+			} else {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.Parameter.Name)
+			}
+		})
+	}
+}
+
+func TestInvalidConstraintsDefinition(t *testing.T) {
+	p := NewParameterDefinition("name", ParameterTypeString, WithMin(1))
+	assert.Error(t, p.CheckConstraintsDefinition())
+
+	p = NewParameterDefinition("count", ParameterTypeInteger, WithMin("foo"))
+	assert.Error(t, p.CheckConstraintsDefinition())
+
+	p = NewParameterDefinition("slug", ParameterTypeString, WithPattern("[a-"))
+	assert.Error(t, p.CheckParameterDefaultValueValidity())
+}
+
+func TestDurationBounds(t *testing.T) {
+	p := NewParameterDefinition("timeout", ParameterTypeDuration, WithMin("1s"), WithMax(time.Minute))
+	require.NoError(t, p.CheckConstraintsDefinition())
+
+	assert.NoError(t, p.CheckValueConstraints(30*time.Second))
+	assert.EqualError(t, p.CheckValueConstraints(500*time.Millisecond),
+		"Value for parameter timeout must be at least 1s: 500ms")
+	assert.EqualError(t, p.CheckValueConstraints(2*time.Minute),
+		"Value for parameter timeout must be at most 1m0s: 2m0s")
+
+	p = NewParameterDefinition("timeout", ParameterTypeDuration, WithMin("soon"))
+	assert.Error(t, p.CheckConstraintsDefinition())
+}
+
+func TestPatternIsCompiledOnce(t *testing.T) {
+	p := NewParameterDefinition("slug", ParameterTypeString, WithPattern("^[a-z]+$"))
+	require.NoError(t, p.CheckConstraintsDefinition())
+	re := p.patternRegexp
+	require.NotNil(t, re)
+
+	assert.NoError(t, p.CheckValueConstraints("abc"))
+	assert.Error(t, p.CheckValueConstraints("ABC"))
+	assert.Same(t, re, p.patternRegexp)
+
+	// a pattern modified after checking the definition is still applied
+	p.Pattern = "^[A-Z]+$"
+	assert.NoError(t, p.CheckValueConstraints("ABC"))
+	assert.Error(t, p.CheckValueConstraints("abc"))
+}
+
+func TestCustomValidator(t *testing.T) {
+	p := NewParameterDefinition("even", ParameterTypeInteger,
+		WithValidator(func(v interface{}) error {
+			if v.(int)%2 != 0 {
+				return errors.New("must be even")
+			}
+			return nil
+		}))
+
+	assert.NoError(t, p.CheckValueConstraints(2))
+	err := p.CheckValueConstraints(3)
+	require.Error(t, err)
+	assert.Equal(t, "Value for parameter even is invalid: must be even", err.Error())
+}
+
+func TestConstraintsAreCheckedWhenParsing(t *testing.T) {
+	count := NewParameterDefinition("count", ParameterTypeInteger, WithMin(1), WithDefault(3))
+	params := []*ParameterDefinition{count}
+
+	ps, _, err := GatherFlagsFromStringList([]string{}, params, false, false, "")
+	require.NoError(t, err)
+	assert.Equal(t, 3, ps["count"])
+
+	_, _, err = GatherFlagsFromStringList([]string{"--count", "0"}, params, false, false, "")
+	assert.ErrorContains(t, err, "count")
+
+	_, err = GatherParametersFromMap(map[string]interface{}{"count": 0}, map[string]*ParameterDefinition{"count": count}, false)
+	assert.ErrorContains(t, err, "count")
+
+	_, err = GatherArguments([]string{"0"}, params, false, false)
+	assert.ErrorContains(t, err, "count")
+
+	cmd := &cobra.Command{Use: "test"}
+	require.NoError(t, AddFlagsToCobraCommand(cmd.Flags(), params, ""))
+	require.NoError(t, cmd.ParseFlags([]string{}))
+	ps, err = GatherFlagsFromCobraCommand(cmd, params, false, false, "")
+	require.NoError(t, err)
+	assert.Equal(t, 3, ps["count"])
+
+	require.NoError(t, cmd.ParseFlags([]string{"--count", "0"}))
+	_, err = GatherFlagsFromCobraCommand(cmd, params, false, false, "")
+	assert.ErrorContains(t, err, "count")
+
+	require.NoError(t, cmd.ParseFlags([]string{"--count", "2"}))
+	ps, err = GatherFlagsFromCobraCommand(cmd, params, false, false, "")
+	require.NoError(t, err)
+	assert.Equal(t, 2, ps["count"])
+}
+
+func TestDefaultIsCheckedAgainstConstraints(t *testing.T) {
+	invalid := []*ParameterDefinition{
+		NewParameterDefinition("count", ParameterTypeInteger, WithMin(1), WithDefault(0)),
+		NewParameterDefinition("ratio", ParameterTypeFloat, WithMax(1.0), WithDefault(1.5)),
+		NewParameterDefinition("slug", ParameterTypeString, WithPattern("^[a-z]+$"), WithDefault("Foo Bar")),
+		NewParameterDefinition("name", ParameterTypeString, WithMinLength(3), WithDefault("ab")),
+		NewParameterDefinition("tags", ParameterTypeStringList, WithMaxLength(1), WithDefault([]string{"a", "b"})),
+		NewParameterDefinition("timeout", ParameterTypeDuration, WithMin("1s"), WithDefault("10ms")),
+		NewParameterDefinition("password", ParameterTypeSecret, WithMinLength(8), WithDefault("short")),
+	}
+	for _, p := range invalid {
+		t.Run(p.Name, func(t *testing.T) {
+			err := p.CheckParameterDefaultValueValidity()
+			assert.ErrorContains(t, err, "invalid default")
+			assert.ErrorContains(t, err, p.Name)
+
+			cmd := &cobra.Command{Use: "test"}
+			assert.Error(t, AddFlagsToCobraCommand(cmd.Flags(), []*ParameterDefinition{p}, ""))
+		})
+	}
+
+	valid := []*ParameterDefinition{
+		NewParameterDefinition("count", ParameterTypeInteger, WithMin(1), WithDefault(1)),
+		NewParameterDefinition("timeout", ParameterTypeDuration, WithMin("1s"), WithDefault("1m")),
+		// secrets referencing the environment or a file are only resolved when parsing
+		NewParameterDefinition("password", ParameterTypeSecret, WithMinLength(8), WithDefault("env:PASSWORD")),
+		// files only need to exist on the machine the command runs on
+		NewParameterDefinition("config", ParameterTypeString, WithFileExists(true), WithDefault("does-not-exist.yaml")),
+	}
+	for _, p := range valid {
+		t.Run(p.Name, func(t *testing.T) {
+			assert.NoError(t, p.CheckParameterDefaultValueValidity())
+		})
+	}
+}
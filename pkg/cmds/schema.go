@@ -0,0 +1,141 @@
+package cmds
+
+import (
+	"github.com/go-go-golems/glazed/pkg/cmds/parameters"
+	"sort"
+	"strings"
+)
+
+// ToJSONSchema returns the JSON Schema of the parameters of the command, which
+// describes the JSON object accepted by --load-parameters-from-json and ParseCommandFromMap.
+//
+// The parameters of all layers, flags and arguments are properties of the same object,
+// keyed by their name without layer prefix.
+func (cd *CommandDescription) ToJSONSchema() *parameters.JSONSchema {
+	params := []*parameters.ParameterDefinition{}
+	for _, layer := range cd.Layers {
+		params = append(params, getLayerParameters(layer.GetParameterDefinitions())...)
+	}
+	params = append(params, cd.Flags...)
+	params = append(params, cd.Arguments...)
+
+	ret := parameters.ParameterDefinitionsToJSONSchema(params)
+	ret.Title = cd.Name
+	ret.Description = cd.Short
+	return ret
+}
+
+// getLayerParameters returns the parameter definitions of a layer sorted by name,
+// so that schemas are stable.
+func getLayerParameters(pds map[string]*parameters.ParameterDefinition) []*parameters.ParameterDefinition {
+	names := make([]string, 0, len(pds))
+	for name := range pds {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	ret := make([]*parameters.ParameterDefinition, 0, len(names))
+	for _, name := range names {
+		ret = append(ret, pds[name])
+	}
+	return ret
+}
+
+// OpenAPIDocument is an OpenAPI 3.1 document describing a set of commands, see NewOpenAPIDocument.
+type OpenAPIDocument struct {
+	OpenAPI string                      `json:"openapi" yaml:"openapi"`
+	Info    OpenAPIInfo                 `json:"info" yaml:"info"`
+	Paths   map[string]*OpenAPIPathItem `json:"paths" yaml:"paths"`
+}
+
+type OpenAPIInfo struct {
+	Title   string `json:"title" yaml:"title"`
+	Version string `json:"version" yaml:"version"`
+}
+
+type OpenAPIPathItem struct {
+	Post *OpenAPIOperation `json:"post,omitempty" yaml:"post,omitempty"`
+}
+
+type OpenAPIOperation struct {
+	OperationID string                      `json:"operationId" yaml:"operationId"`
+	Summary     string                      `json:"summary,omitempty" yaml:"summary,omitempty"`
+	Description string                      `json:"description,omitempty" yaml:"description,omitempty"`
+	Tags        []string                    `json:"tags,omitempty" yaml:"tags,omitempty"`
+	RequestBody *OpenAPIRequestBody         `json:"requestBody,omitempty" yaml:"requestBody,omitempty"`
+	Responses   map[string]*OpenAPIResponse `json:"responses" yaml:"responses"`
+}
+
+type OpenAPIRequestBody struct {
+	Required bool                         `json:"required,omitempty" yaml:"required,omitempty"`
+	Content  map[string]*OpenAPIMediaType `json:"content" yaml:"content"`
+}
+
+type OpenAPIResponse struct {
+	Description string                       `json:"description" yaml:"description"`
+	Content     map[string]*OpenAPIMediaType `json:"content,omitempty" yaml:"content,omitempty"`
+}
+
+type OpenAPIMediaType struct {
+	Schema *parameters.JSONSchema `json:"schema" yaml:"schema"`
+}
+
+// GetCommandPath returns the URL path of a command, built from its parents and its name,
+// for example /db/query.
+func GetCommandPath(cd *CommandDescription) string {
+	return "/" + strings.Join(append(append([]string{}, cd.Parents...), cd.Name), "/")
+}
+
+// GetCommandOperationID returns the OpenAPI operation id of a command, for example db-query.
+func GetCommandOperationID(cd *CommandDescription) string {
+	return strings.Join(append(append([]string{}, cd.Parents...), cd.Name), "-")
+}
+
+// NewOpenAPIDocument returns an OpenAPI document with an operation for each command.
+//
+// Each command is a POST operation on the path returned by GetCommandPath, whose JSON request
+// body holds the parameters of the command (see CommandDescription.ToJSONSchema), and which
+// responds with the rows output by the command.
+func NewOpenAPIDocument(title string, version string, commands ...*CommandDescription) *OpenAPIDocument {
+	ret := &OpenAPIDocument{
+		OpenAPI: "3.1.0",
+		Info: OpenAPIInfo{
+			Title:   title,
+			Version: version,
+		},
+		Paths: map[string]*OpenAPIPathItem{},
+	}
+
+	for _, cd := range commands {
+		schema := cd.ToJSONSchema()
+		ret.Paths[GetCommandPath(cd)] = &OpenAPIPathItem{
+			Post: &OpenAPIOperation{
+				OperationID: GetCommandOperationID(cd),
+				Summary:     cd.Short,
+				Description: cd.Long,
+				Tags:        cd.Parents,
+				RequestBody: &OpenAPIRequestBody{
+					Required: len(schema.Required) > 0,
+					Content: map[string]*OpenAPIMediaType{
+						"application/json": {Schema: schema},
+					},
+				},
+				Responses: map[string]*OpenAPIResponse{
+					"200": {
+						Description: "The rows output by the command",
+						Content: map[string]*OpenAPIMediaType{
+							"application/json": {
+								Schema: &parameters.JSONSchema{
+									Type:  "array",
+									Items: &parameters.JSONSchema{Type: "object"},
+								},
+							},
+						},
+					},
+					"400": {Description: "The parameters are invalid"},
+				},
+			},
+		}
+	}
+
+	return ret
+}
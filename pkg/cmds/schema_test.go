@@ -0,0 +1,53 @@
+package cmds
+
+import (
+	"github.com/go-go-golems/glazed/pkg/cmds/layers"
+	"github.com/go-go-golems/glazed/pkg/cmds/parameters"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func newSchemaTestCommand(t *testing.T) *CommandDescription {
+	layer, err := layers.NewParameterLayer("output", "Output",
+		layers.WithFlags(
+			parameters.NewParameterDefinition("output", parameters.ParameterTypeChoice,
+				parameters.WithChoices([]string{"table", "json"}),
+				parameters.WithDefault("table"),
+			),
+		),
+	)
+	require.NoError(t, err)
+
+	return NewCommandDescription("query",
+		WithShort("Run a query"),
+		WithParents("db"),
+		WithLayers(layer),
+		WithFlags(parameters.NewParameterDefinition("limit", parameters.ParameterTypeInteger)),
+		WithArguments(parameters.NewParameterDefinition("sql", parameters.ParameterTypeString, parameters.WithRequired(true))),
+	)
+}
+
+func TestCommandDescriptionToJSONSchema(t *testing.T) {
+	schema := newSchemaTestCommand(t).ToJSONSchema()
+	assert.Equal(t, "query", schema.Title)
+	assert.Equal(t, "Run a query", schema.Description)
+	assert.Equal(t, []string{"sql"}, schema.Required)
+	require.Len(t, schema.Properties, 3)
+	assert.Equal(t, []string{"table", "json"}, schema.Properties["output"].Enum)
+	assert.Equal(t, "integer", schema.Properties["limit"].Type)
+}
+
+func TestNewOpenAPIDocument(t *testing.T) {
+	cd := newSchemaTestCommand(t)
+	doc := NewOpenAPIDocument("db", "1.0.0", cd)
+	assert.Equal(t, "3.1.0", doc.OpenAPI)
+
+	require.Contains(t, doc.Paths, "/db/query")
+	op := doc.Paths["/db/query"].Post
+	assert.Equal(t, "db-query", op.OperationID)
+	assert.Equal(t, []string{"db"}, op.Tags)
+	assert.True(t, op.RequestBody.Required)
+	assert.Equal(t, cd.ToJSONSchema(), op.RequestBody.Content["application/json"].Schema)
+	assert.Equal(t, "array", op.Responses["200"].Content["application/json"].Schema.Type)
+}
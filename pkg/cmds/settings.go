@@ -0,0 +1,27 @@
+package cmds
+
+import (
+	"github.com/go-go-golems/glazed/pkg/cmds/layers"
+)
+
+// Settings decodes the parsed layers and parameters of a command into a new T.
+// Fields tagged with `glazed.layer` are decoded from the layer with that slug,
+// and fields tagged with `glazed.parameter` from ps, see layers.DecodeLayers.
+//
+//	type QuerySettings struct {
+//		Limit    int              `glazed.parameter:"limit"`
+//		Database DatabaseSettings `glazed.layer:"database"`
+//	}
+//
+//	s, err := cmds.Settings[QuerySettings](parsedLayers, ps)
+func Settings[T any](
+	parsedLayers map[string]*layers.ParsedParameterLayer,
+	ps map[string]interface{},
+) (*T, error) {
+	ret := new(T)
+	err := layers.DecodeLayers(parsedLayers, ps, ret)
+	if err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
@@ -26,6 +26,7 @@ type TemplateCommandDescription struct {
 	Flags     []*parameters.ParameterDefinition `yaml:"flags,omitempty"`
 	Arguments []*parameters.ParameterDefinition `yaml:"arguments,omitempty"`
 	Layers    []layers.ParameterLayer           `yaml:"layers,omitempty"`
+	Relations []*parameters.ParameterRelation   `yaml:"relations,omitempty"`
 	Template  string                            `yaml:"template"`
 }
 
@@ -83,6 +84,7 @@ func (tcl *TemplateCommandLoader) LoadCommandFromYAML(
 		WithFlags(tcd.Flags...),
 		WithArguments(tcd.Arguments...),
 		WithLayers(tcd.Layers...),
+		WithRelations(tcd.Relations...),
 		WithLayout(&layout.Layout{
 			Sections: tcd.Layout,
 		}),
@@ -0,0 +1,154 @@
+package completion
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/go-go-golems/glazed/pkg/cmds/parameters"
+	"github.com/go-go-golems/glazed/pkg/middlewares"
+	"github.com/go-go-golems/glazed/pkg/types"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// SampleSize is the number of rows whose columns are recorded by the ColumnCacheMiddleware.
+var SampleSize = 100
+
+// ColumnCache stores the names of the columns output by the last run of each command,
+// so that they can be completed for the flags that take column names (--fields, --sort-by, ...).
+type ColumnCache struct {
+	Dir string
+}
+
+// NewColumnCache returns the column cache of the application appName, which is stored
+// in the user cache directory ($XDG_CACHE_HOME, ~/.cache by default).
+func NewColumnCache(appName string) (*ColumnCache, error) {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return nil, errors.Wrap(err, "could not find the user cache directory")
+	}
+	return &ColumnCache{Dir: filepath.Join(cacheDir, appName, "columns")}, nil
+}
+
+// NewColumnCacheForCommand returns the column cache of the application cmd belongs to,
+// named after its root command.
+func NewColumnCacheForCommand(cmd *cobra.Command) (*ColumnCache, error) {
+	return NewColumnCache(cmd.Root().Name())
+}
+
+// GetCommandPath returns the names of the parent commands of cmd, excluding the root command,
+// followed by the name of cmd. It is used as the key of the column cache.
+func GetCommandPath(cmd *cobra.Command) []string {
+	ret := []string{}
+	for ; cmd != nil && cmd.HasParent(); cmd = cmd.Parent() {
+		ret = append([]string{cmd.Name()}, ret...)
+	}
+	return ret
+}
+
+func (c *ColumnCache) path(commandPath []string) string {
+	return filepath.Join(c.Dir, strings.Join(commandPath, "-")+".json")
+}
+
+// Get returns the cached columns of the command, or nil if the command hasn't been run yet.
+func (c *ColumnCache) Get(commandPath []string) ([]string, error) {
+	b, err := os.ReadFile(c.path(commandPath))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	ret := []string{}
+	err = json.Unmarshal(b, &ret)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not parse column cache %s", c.path(commandPath))
+	}
+	return ret, nil
+}
+
+// Set stores the columns of the command.
+func (c *ColumnCache) Set(commandPath []string, columns []string) error {
+	err := os.MkdirAll(c.Dir, 0755)
+	if err != nil {
+		return err
+	}
+	b, err := json.Marshal(columns)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(c.path(commandPath), b, 0644)
+}
+
+// CompleteColumns is a parameters.CompletionFunc completing comma separated lists of the
+// columns cached for the command being completed. Columns can be prefixed with - (as used
+// by --sort-by for descending order).
+func CompleteColumns(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	directive := cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveNoSpace
+	cache, err := NewColumnCacheForCommand(cmd)
+	if err != nil {
+		return nil, directive
+	}
+	columns, err := cache.Get(GetCommandPath(cmd))
+	if err != nil {
+		return nil, directive
+	}
+
+	current := toComplete[strings.LastIndex(toComplete, ",")+1:]
+	if strings.HasPrefix(current, "-") {
+		for _, c := range columns {
+			columns = append(columns, "-"+c)
+		}
+	}
+	return parameters.CompleteList(columns, toComplete), directive
+}
+
+var _ parameters.CompletionFunc = CompleteColumns
+
+// ColumnCacheMiddleware records the columns of the first SampleSize rows it processes,
+// and stores them in the column cache when closed. Rows are passed through unchanged.
+type ColumnCacheMiddleware struct {
+	cache       *ColumnCache
+	commandPath []string
+	columns     []string
+	seen        map[string]bool
+	rows        int
+}
+
+var _ middlewares.RowMiddleware = (*ColumnCacheMiddleware)(nil)
+
+func NewColumnCacheMiddleware(cache *ColumnCache, commandPath []string) *ColumnCacheMiddleware {
+	return &ColumnCacheMiddleware{
+		cache:       cache,
+		commandPath: commandPath,
+		seen:        map[string]bool{},
+	}
+}
+
+func (c *ColumnCacheMiddleware) Process(ctx context.Context, row types.Row) ([]types.Row, error) {
+	if c.rows < SampleSize {
+		c.rows++
+		for _, field := range types.GetFields(row) {
+			if !c.seen[field] {
+				c.seen[field] = true
+				c.columns = append(c.columns, field)
+			}
+		}
+	}
+	return []types.Row{row}, nil
+}
+
+// Close stores the recorded columns. Failing to write the cache doesn't fail the command.
+func (c *ColumnCacheMiddleware) Close(ctx context.Context) error {
+	if len(c.columns) == 0 {
+		return nil
+	}
+	err := c.cache.Set(c.commandPath, c.columns)
+	if err != nil {
+		log.Debug().Err(err).Str("dir", c.cache.Dir).Msg("could not write column cache")
+	}
+	return nil
+}
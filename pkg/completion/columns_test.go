@@ -0,0 +1,50 @@
+package completion
+
+import (
+	"context"
+	"github.com/go-go-golems/glazed/pkg/types"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestColumnCache(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	root := &cobra.Command{Use: "app"}
+	parent := &cobra.Command{Use: "db"}
+	cmd := &cobra.Command{Use: "query"}
+	root.AddCommand(parent)
+	parent.AddCommand(cmd)
+	assert.Equal(t, []string{"db", "query"}, GetCommandPath(cmd))
+
+	cache, err := NewColumnCacheForCommand(cmd)
+	require.NoError(t, err)
+	columns, err := cache.Get(GetCommandPath(cmd))
+	require.NoError(t, err)
+	assert.Nil(t, columns)
+
+	ctx := context.Background()
+	mw := NewColumnCacheMiddleware(cache, GetCommandPath(cmd))
+	for _, row := range []types.Row{
+		types.NewRow(types.MRP("id", 1), types.MRP("name", "foo")),
+		types.NewRow(types.MRP("id", 2), types.MRP("email", "bar@example.com")),
+	} {
+		rows, err := mw.Process(ctx, row)
+		require.NoError(t, err)
+		assert.Equal(t, []types.Row{row}, rows)
+	}
+	require.NoError(t, mw.Close(ctx))
+
+	columns, err = cache.Get([]string{"db", "query"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"id", "name", "email"}, columns)
+
+	completions, _ := CompleteColumns(cmd, nil, "id,")
+	assert.Equal(t, []string{"id,name", "id,email"}, completions)
+	completions, _ = CompleteColumns(cmd, nil, "-n")
+	assert.Equal(t, []string{"-name"}, completions)
+	completions, _ = CompleteColumns(parent, nil, "")
+	assert.Empty(t, completions)
+}
@@ -0,0 +1,345 @@
+package config
+
+import (
+	"encoding/json"
+	"github.com/go-go-golems/glazed/pkg/cmds"
+	"github.com/go-go-golems/glazed/pkg/cmds/layers"
+	"github.com/go-go-golems/glazed/pkg/cmds/parameters"
+	"github.com/pelletier/go-toml/v2"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// CommandsKey is the top-level key of a config file containing the per-command sections.
+const CommandsKey = "commands"
+
+// ProfilesKey is the top-level key of a config file containing the named profiles.
+const ProfilesKey = "profiles"
+
+// Config contains the parameter values of a list of config files, which can be used
+// to parse the layers and flags of any command.
+//
+// The top-level keys of a config file are layer slugs, and map to the values of the
+// parameters of that layer. The commands key maps command paths (the names of the parent
+// commands and of the command, separated by spaces) to sections that override the
+// top-level values for that command. A command section also contains layer slugs,
+// and any other key is a flag of the command itself.
+//
+//	glazed-output:
+//	  output: json
+//	my-db:
+//	  host: localhost
+//	commands:
+//	  "db query":
+//	    my-db:
+//	      host: replica
+//	    limit: 10
+//	profiles:
+//	  ci:
+//	    glazed-output:
+//	      output: json
+//
+// Later files override earlier files, and the command sections of all files override
+// the top-level sections. The profiles key contains named sets of layer sections, which
+// are applied on top of everything else when selected, see WithProfile.
+type Config struct {
+	Files    []string
+	sections []map[string]interface{}
+}
+
+// NewConfigFromFiles loads the given config files, in increasing order of precedence.
+func NewConfigFromFiles(files ...string) (*Config, error) {
+	ret := &Config{}
+	for _, file := range files {
+		section, err := LoadConfigFile(file)
+		if err != nil {
+			return nil, err
+		}
+		ret.Files = append(ret.Files, file)
+		ret.sections = append(ret.sections, section)
+	}
+	return ret, nil
+}
+
+// LoadConfigFile loads a YAML, JSON or TOML config file, depending on its extension.
+// Files with an unknown extension are parsed as YAML.
+func LoadConfigFile(path string) (map[string]interface{}, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not read config file %s", path)
+	}
+
+	ret := map[string]interface{}{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		err = json.Unmarshal(b, &ret)
+		if err == nil {
+			ret = convertJSONNumbers(ret).(map[string]interface{})
+		}
+	case ".toml":
+		err = toml.Unmarshal(b, &ret)
+	default:
+		err = yaml.Unmarshal(b, &ret)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not parse config file %s", path)
+	}
+
+	return ret, nil
+}
+
+// convertJSONNumbers converts the whole numbers decoded by encoding/json as float64 to int,
+// so that they can be used for integer parameters.
+func convertJSONNumbers(v interface{}) interface{} {
+	switch v_ := v.(type) {
+	case float64:
+		if v_ == math.Trunc(v_) && math.Abs(v_) < math.MaxInt32 {
+			return int(v_)
+		}
+		return v_
+	case []interface{}:
+		for i, e := range v_ {
+			v_[i] = convertJSONNumbers(e)
+		}
+		return v_
+	case map[string]interface{}:
+		for k, e := range v_ {
+			v_[k] = convertJSONNumbers(e)
+		}
+		return v_
+	default:
+		return v
+	}
+}
+
+func getSection(m map[string]interface{}, key string, file string) (map[string]interface{}, error) {
+	v, ok := m[key]
+	if !ok || v == nil {
+		return nil, nil
+	}
+	ret, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, errors.Errorf("section %s of config file %s is not a map", key, file)
+	}
+	return ret, nil
+}
+
+// getCommandSections returns the per-command section of each file for the given command path.
+func (c *Config) getCommandSections(path string) ([]map[string]interface{}, error) {
+	ret := []map[string]interface{}{}
+	for i, section := range c.sections {
+		commands, err := getSection(section, CommandsKey, c.Files[i])
+		if err != nil {
+			return nil, err
+		}
+		command, err := getSection(commands, path, c.Files[i])
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, command)
+	}
+	return ret, nil
+}
+
+// getLayerValues merges the values of the sections with the given slugs, first from the top level
+// of each file, then from the given command sections, and finally from the profile, if any.
+func (c *Config) getLayerValues(
+	commandSections []map[string]interface{},
+	profile *Profile,
+	slugs []string,
+) (map[string]interface{}, error) {
+	ret := map[string]interface{}{}
+	for _, sections := range [][]map[string]interface{}{c.sections, commandSections} {
+		for i, section := range sections {
+			for _, slug := range slugs {
+				values, err := getSection(section, slug, c.Files[i])
+				if err != nil {
+					return nil, err
+				}
+				for k, v := range values {
+					ret[k] = v
+				}
+			}
+		}
+	}
+	if profile != nil {
+		for _, slug := range slugs {
+			for k, v := range profile.Layers[slug] {
+				ret[k] = v
+			}
+		}
+	}
+	return ret, nil
+}
+
+// Profile is a named set of parameter values, keyed by layer slug, that is stored in the
+// profiles section of config files.
+type Profile struct {
+	Name   string
+	Layers map[string]map[string]interface{}
+	// Files are the config files that define the profile.
+	Files []string
+}
+
+// GetProfiles returns the profiles of all the config files, sorted by name.
+// A profile defined in several files is merged, later files overriding the values of earlier ones.
+func (c *Config) GetProfiles() ([]*Profile, error) {
+	profiles := map[string]*Profile{}
+	for i, section := range c.sections {
+		profileSections, err := getSection(section, ProfilesKey, c.Files[i])
+		if err != nil {
+			return nil, err
+		}
+		for name := range profileSections {
+			layerSections, err := getSection(profileSections, name, c.Files[i])
+			if err != nil {
+				return nil, err
+			}
+
+			profile, ok := profiles[name]
+			if !ok {
+				profile = &Profile{
+					Name:   name,
+					Layers: map[string]map[string]interface{}{},
+				}
+				profiles[name] = profile
+			}
+			profile.Files = append(profile.Files, c.Files[i])
+
+			for slug := range layerSections {
+				values, err := getSection(layerSections, slug, c.Files[i])
+				if err != nil {
+					return nil, err
+				}
+				if _, ok := profile.Layers[slug]; !ok {
+					profile.Layers[slug] = map[string]interface{}{}
+				}
+				for k, v := range values {
+					profile.Layers[slug][k] = v
+				}
+			}
+		}
+	}
+
+	ret := []*Profile{}
+	for _, profile := range profiles {
+		ret = append(ret, profile)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].Name < ret[j].Name
+	})
+	return ret, nil
+}
+
+// GetProfile returns the profile with the given name, or an error if no config file defines it.
+func (c *Config) GetProfile(name string) (*Profile, error) {
+	profiles, err := c.GetProfiles()
+	if err != nil {
+		return nil, err
+	}
+	for _, profile := range profiles {
+		if profile.Name == name {
+			return profile, nil
+		}
+	}
+	return nil, errors.Errorf("profile %s not found in config files", name)
+}
+
+type ParseOption func(*parseOptions)
+
+type parseOptions struct {
+	profile string
+}
+
+// WithProfile applies the named profile on top of the top-level and command sections.
+func WithProfile(name string) ParseOption {
+	return func(o *parseOptions) {
+		o.profile = name
+	}
+}
+
+// ParseCommand parses the layers and flags of the command from the config files.
+//
+// Only the parameters present in the config files are returned, and the values are parsed
+// using the ParseFlagsFromJSON method of each layer. The parameters of a layer that implements
+// layers.CompositeParameterLayer can also be set under the slugs of its child layers.
+func (c *Config) ParseCommand(description *cmds.CommandDescription, options ...ParseOption) (
+	map[string]*layers.ParsedParameterLayer,
+	map[string]interface{},
+	error,
+) {
+	options_ := &parseOptions{}
+	for _, o := range options {
+		o(options_)
+	}
+
+	path := strings.Join(append(append([]string{}, description.Parents...), description.Name), " ")
+	commandSections, err := c.getCommandSections(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var profile *Profile
+	if options_.profile != "" {
+		profile, err = c.GetProfile(options_.profile)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	parsedLayers := map[string]*layers.ParsedParameterLayer{}
+	slugs := map[string]bool{CommandsKey: true}
+
+	for _, layer := range description.Layers {
+		layerSlugs := []string{layer.GetSlug()}
+		if composite, ok := layer.(layers.CompositeParameterLayer); ok {
+			for _, child := range composite.GetChildLayers() {
+				layerSlugs = append(layerSlugs, child.GetSlug())
+			}
+		}
+		for _, slug := range layerSlugs {
+			slugs[slug] = true
+		}
+
+		values, err := c.getLayerValues(commandSections, profile, layerSlugs)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(values) == 0 {
+			continue
+		}
+
+		jsonParameterLayer, ok := layer.(layers.JSONParameterLayer)
+		if !ok {
+			return nil, nil, errors.Errorf("layer %s is not a JSONParameterLayer", layer.GetName())
+		}
+		ps, err := jsonParameterLayer.ParseFlagsFromJSON(values, true)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "invalid config for layer %s", layer.GetSlug())
+		}
+		parsedLayers[layer.GetSlug()] = &layers.ParsedParameterLayer{
+			Layer:      layer,
+			Parameters: ps,
+		}
+	}
+
+	flags := map[string]interface{}{}
+	for _, section := range commandSections {
+		for k, v := range section {
+			if !slugs[k] {
+				flags[k] = v
+			}
+		}
+	}
+	ps, err := parameters.GatherParametersFromMap(flags, description.GetFlagMap(), true)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "invalid config for command %s", path)
+	}
+
+	return parsedLayers, ps, nil
+}
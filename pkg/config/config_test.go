@@ -0,0 +1,221 @@
+package config
+
+import (
+	"github.com/go-go-golems/glazed/pkg/cmds"
+	"github.com/go-go-golems/glazed/pkg/cmds/layers"
+	"github.com/go-go-golems/glazed/pkg/cmds/parameters"
+	"github.com/go-go-golems/glazed/pkg/settings"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeFile(t *testing.T, path string, content string) string {
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	return path
+}
+
+func newTestCommand(t *testing.T) *cmds.CommandDescription {
+	dbLayer, err := layers.NewParameterLayer("my-db", "Database",
+		layers.WithPrefix("db-"),
+		layers.WithFlags(
+			parameters.NewParameterDefinition("host", parameters.ParameterTypeString, parameters.WithDefault("localhost")),
+			parameters.NewParameterDefinition("port", parameters.ParameterTypeInteger, parameters.WithDefault(5432)),
+			parameters.NewParameterDefinition("timeout", parameters.ParameterTypeDuration),
+		),
+	)
+	require.NoError(t, err)
+	glazedLayer, err := settings.NewGlazedParameterLayers()
+	require.NoError(t, err)
+
+	return cmds.NewCommandDescription("query",
+		cmds.WithParents("db"),
+		cmds.WithLayers(dbLayer, glazedLayer),
+		cmds.WithFlags(
+			parameters.NewParameterDefinition("limit", parameters.ParameterTypeInteger, parameters.WithDefault(100)),
+		),
+	)
+}
+
+func TestLoadConfigFile(t *testing.T) {
+	dir := t.TempDir()
+	expected := map[string]interface{}{
+		"my-db": map[string]interface{}{"host": "example.com", "port": 1234},
+	}
+
+	for name, content := range map[string]string{
+		"config.yaml": "my-db:\n  host: example.com\n  port: 1234\n",
+		"config.json": `{"my-db": {"host": "example.com", "port": 1234}}`,
+	} {
+		m, err := LoadConfigFile(writeFile(t, filepath.Join(dir, name), content))
+		require.NoError(t, err)
+		assert.Equal(t, expected, m, name)
+	}
+
+	m, err := LoadConfigFile(writeFile(t, filepath.Join(dir, "config.toml"), "[my-db]\nhost = \"example.com\"\nport = 1234\n"))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"my-db": map[string]interface{}{"host": "example.com", "port": int64(1234)},
+	}, m)
+
+	_, err = LoadConfigFile(writeFile(t, filepath.Join(dir, "broken.json"), `{"my-db": `))
+	assert.Error(t, err)
+}
+
+func TestParseCommand(t *testing.T) {
+	dir := t.TempDir()
+	global := writeFile(t, filepath.Join(dir, "global.yaml"), `
+my-db:
+  host: db.example.com
+  port: 6543
+glazed-output:
+  output: json
+glazed-fields-filters:
+  fields: [id, name]
+other-layer:
+  foo: bar
+commands:
+  "db query":
+    my-db:
+      host: replica.example.com
+    limit: 10
+  "db other":
+    limit: 20
+`)
+	local := writeFile(t, filepath.Join(dir, "local.json"), `{
+  "my-db": {"port": 7654, "timeout": "5s"},
+  "commands": {"db query": {"glazed-output": {"output": "yaml"}}}
+}`)
+
+	c, err := NewConfigFromFiles(global, local)
+	require.NoError(t, err)
+
+	parsedLayers, ps, err := c.ParseCommand(newTestCommand(t))
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]interface{}{
+		"host":    "replica.example.com",
+		"port":    7654,
+		"timeout": 5 * time.Second,
+	}, parsedLayers["my-db"].Parameters)
+
+	glazed := parsedLayers["glazed"].Parameters
+	assert.Equal(t, []string{"yaml"}, glazed["output"])
+	assert.Equal(t, []string{"id", "name"}, glazed["fields"])
+	assert.NotContains(t, glazed, "table-format")
+
+	assert.Equal(t, map[string]interface{}{"limit": 10}, ps)
+}
+
+func TestParseCommandInvalidValue(t *testing.T) {
+	file := writeFile(t, filepath.Join(t.TempDir(), "config.yaml"), "my-db:\n  port: not-a-port\n")
+	c, err := NewConfigFromFiles(file)
+	require.NoError(t, err)
+
+	_, _, err = c.ParseCommand(newTestCommand(t))
+	assert.ErrorContains(t, err, "my-db")
+
+	file = writeFile(t, filepath.Join(t.TempDir(), "config.yaml"), "my-db: localhost\n")
+	c, err = NewConfigFromFiles(file)
+	require.NoError(t, err)
+
+	_, _, err = c.ParseCommand(newTestCommand(t))
+	assert.ErrorContains(t, err, "not a map")
+}
+
+func TestFindConfigFiles(t *testing.T) {
+	root := t.TempDir()
+
+	systemDir := filepath.Join(root, "etc")
+	homeDir := filepath.Join(root, "home")
+	t.Setenv("XDG_CONFIG_DIRS", systemDir)
+	t.Setenv("XDG_CONFIG_HOME", homeDir)
+	systemConfig := writeFile(t, filepath.Join(systemDir, "app", "config.yaml"), "")
+	homeConfig := writeFile(t, filepath.Join(homeDir, "app", "config.toml"), "")
+
+	repo := filepath.Join(root, "src", "repo")
+	require.NoError(t, os.MkdirAll(filepath.Join(repo, ".git"), 0755))
+	writeFile(t, filepath.Join(root, "src", ".app.yaml"), "")
+	repoConfig := writeFile(t, filepath.Join(repo, ".app.yaml"), "")
+	subConfig := writeFile(t, filepath.Join(repo, "sub", ".app.json"), "")
+	require.NoError(t, os.MkdirAll(filepath.Join(repo, "sub", "dir"), 0755))
+
+	files, err := FindConfigFiles("app", filepath.Join(repo, "sub", "dir"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{systemConfig, homeConfig, repoConfig, subConfig}, files)
+
+	// outside of a git repository, the parent directories are not searched
+	outside := filepath.Join(root, "src", "other")
+	otherConfig := writeFile(t, filepath.Join(outside, ".app.yaml"), "")
+	files, err = FindConfigFiles("app", outside)
+	require.NoError(t, err)
+	assert.Equal(t, []string{systemConfig, homeConfig, otherConfig}, files)
+
+	files, err = FindConfigFiles("app", filepath.Join(outside, "sub"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{systemConfig, homeConfig}, files)
+}
+
+func TestProfiles(t *testing.T) {
+	dir := t.TempDir()
+	global := writeFile(t, filepath.Join(dir, "global.yaml"), `
+glazed-output:
+  output: csv
+commands:
+  "db query":
+    my-db:
+      host: replica.example.com
+profiles:
+  ci:
+    glazed-output:
+      output: json
+  wide:
+    glazed-output:
+      table-style: bold
+    glazed-fields-filters:
+      fields: [id, name, description]
+    my-db:
+      host: wide.example.com
+`)
+	local := writeFile(t, filepath.Join(dir, "local.yaml"), `
+profiles:
+  wide:
+    glazed-output:
+      output: table
+`)
+
+	c, err := NewConfigFromFiles(global, local)
+	require.NoError(t, err)
+
+	profiles, err := c.GetProfiles()
+	require.NoError(t, err)
+	require.Len(t, profiles, 2)
+	assert.Equal(t, "ci", profiles[0].Name)
+	assert.Equal(t, "wide", profiles[1].Name)
+	assert.Equal(t, []string{global, local}, profiles[1].Files)
+	assert.Equal(t, map[string]interface{}{
+		"table-style": "bold",
+		"output":      "table",
+	}, profiles[1].Layers["glazed-output"])
+
+	parsedLayers, _, err := c.ParseCommand(newTestCommand(t), WithProfile("wide"))
+	require.NoError(t, err)
+	glazed := parsedLayers["glazed"].Parameters
+	assert.Equal(t, []string{"table"}, glazed["output"])
+	assert.Equal(t, "bold", glazed["table-style"])
+	assert.Equal(t, []string{"id", "name", "description"}, glazed["fields"])
+	// profiles override the command sections
+	assert.Equal(t, "wide.example.com", parsedLayers["my-db"].Parameters["host"])
+
+	parsedLayers, _, err = c.ParseCommand(newTestCommand(t), WithProfile("ci"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"json"}, parsedLayers["glazed"].Parameters["output"])
+	assert.Equal(t, "replica.example.com", parsedLayers["my-db"].Parameters["host"])
+
+	_, _, err = c.ParseCommand(newTestCommand(t), WithProfile("missing"))
+	assert.ErrorContains(t, err, "profile missing not found")
+}
@@ -0,0 +1,120 @@
+package config
+
+import (
+	"github.com/pkg/errors"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Extensions are the extensions of the config files that are looked for, in order.
+var Extensions = []string{".yaml", ".yml", ".json", ".toml"}
+
+func findFile(dir string, name string) (string, bool) {
+	for _, ext := range Extensions {
+		path := filepath.Join(dir, name+ext)
+		if fi, err := os.Stat(path); err == nil && !fi.IsDir() {
+			return path, true
+		}
+	}
+	return "", false
+}
+
+// getXDGConfigDirs returns the XDG config directories, in increasing order of precedence:
+// the entries of $XDG_CONFIG_DIRS (/etc/xdg by default) followed by the user config directory
+// ($XDG_CONFIG_HOME, ~/.config by default).
+func getXDGConfigDirs() []string {
+	ret := []string{}
+
+	configDirs := os.Getenv("XDG_CONFIG_DIRS")
+	if configDirs == "" {
+		configDirs = "/etc/xdg"
+	}
+	dirs := filepath.SplitList(configDirs)
+	// XDG_CONFIG_DIRS is ordered by decreasing importance
+	for i := len(dirs) - 1; i >= 0; i-- {
+		if dirs[i] != "" {
+			ret = append(ret, dirs[i])
+		}
+	}
+
+	if userConfigDir, err := os.UserConfigDir(); err == nil {
+		ret = append(ret, userConfigDir)
+	}
+
+	return ret
+}
+
+// findGitRoot returns the root of the git repository dir is in.
+func findGitRoot(dir string) (string, bool) {
+	for {
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return dir, true
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
+
+// FindConfigFiles returns the config files of the application appName, in increasing
+// order of precedence:
+//
+//   - config.yaml (or .yml, .json, .toml) in the <appName> directory of the XDG config directories
+//   - .<appName>.yaml (or .yml, .json, .toml) in dir and its parent directories, up to the root
+//     of the git repository dir is in. Files closer to dir take precedence. If dir is not in a
+//     git repository, only dir is searched.
+func FindConfigFiles(appName string, dir string) ([]string, error) {
+	ret := []string{}
+
+	for _, configDir := range getXDGConfigDirs() {
+		if path, ok := findFile(filepath.Join(configDir, appName), "config"); ok {
+			ret = append(ret, path)
+		}
+	}
+
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not find config files in %s", dir)
+	}
+	root, ok := findGitRoot(dir)
+	if !ok {
+		root = dir
+	}
+
+	projectFiles := []string{}
+	name := "." + strings.TrimPrefix(appName, ".")
+	for {
+		if path, ok := findFile(dir, name); ok {
+			projectFiles = append(projectFiles, path)
+		}
+		if dir == root {
+			break
+		}
+		dir = filepath.Dir(dir)
+	}
+
+	for i := len(projectFiles) - 1; i >= 0; i-- {
+		ret = append(ret, projectFiles[i])
+	}
+
+	return ret, nil
+}
+
+// LoadAppConfig loads the config files of the application appName found from the current
+// directory (see FindConfigFiles), followed by the given files. Files given explicitly override
+// the discovered ones.
+func LoadAppConfig(appName string, files ...string) (*Config, error) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	found, err := FindConfigFiles(appName, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewConfigFromFiles(append(found, files...)...)
+}
@@ -0,0 +1,250 @@
+package chart
+
+import (
+	"context"
+	"fmt"
+	"github.com/go-go-golems/glazed/pkg/formatters"
+	"github.com/go-go-golems/glazed/pkg/helpers/cast"
+	"github.com/go-go-golems/glazed/pkg/middlewares"
+	"github.com/go-go-golems/glazed/pkg/types"
+	"github.com/pkg/errors"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// OutputFormatter renders the values of a column as a chart made of unicode block characters.
+//
+// The X column provides the labels (bar and hbar charts) and the Y column the values.
+// If X is not set, the first column that isn't the Y column is used, and if Y is not set,
+// the first column containing numbers is used.
+//
+// Since the chart is scaled to the whole data set, it can only be rendered once the table is complete.
+type OutputFormatter struct {
+	OutputFile string
+	ChartType  string
+	X          types.FieldName
+	Y          types.FieldName
+	// Width is the total width of the chart, including labels and axes.
+	Width int
+	// Height is the number of lines of vertical bar charts and line charts.
+	Height int
+	// Bins is the number of buckets of histograms.
+	Bins int
+}
+
+var _ formatters.TableOutputFormatter = (*OutputFormatter)(nil)
+
+var ChartTypes = []string{"bar", "hbar", "histogram", "sparkline", "line"}
+
+func (f *OutputFormatter) Close(ctx context.Context, w io.Writer) error {
+	return nil
+}
+
+func (f *OutputFormatter) RegisterTableMiddlewares(mw *middlewares.TableProcessor) error {
+	return nil
+}
+
+func (f *OutputFormatter) RegisterRowMiddlewares(mw *middlewares.TableProcessor) error {
+	return nil
+}
+
+func (f *OutputFormatter) ContentType() string {
+	return "text/plain"
+}
+
+func (f *OutputFormatter) OutputTable(ctx context.Context, table_ *types.Table, w io.Writer) error {
+	if f.OutputFile != "" {
+		f_, err := os.Create(f.OutputFile)
+		if err != nil {
+			return err
+		}
+		defer func(f_ *os.File) {
+			_ = f_.Close()
+		}(f_)
+		w = f_
+	}
+
+	if len(table_.Rows) == 0 {
+		return nil
+	}
+
+	y, labels, values, err := f.extractSeries(table_)
+	if err != nil {
+		return err
+	}
+
+	var lines []string
+	switch f.ChartType {
+	case "", "hbar":
+		lines = renderHBar(labels, values, f.Width)
+	case "bar":
+		lines, err = renderBar(labels, values, f.Width, f.Height)
+	case "histogram":
+		labels, values = computeHistogram(values, f.Bins)
+		lines = renderHBar(labels, values, f.Width)
+	case "sparkline":
+		lines = renderSparkline(y, values, f.Width)
+	case "line":
+		lines = renderLine(values, f.Width, f.Height)
+	default:
+		return errors.Errorf("unknown chart type %s (expected one of %s)", f.ChartType, strings.Join(ChartTypes, ", "))
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, line := range lines {
+		_, err = fmt.Fprintln(w, strings.TrimRight(line, " "))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// extractSeries returns the column to chart, as well as its labels and values.
+// Rows whose value is not a number are skipped, and NaN or infinite values are an error.
+func (f *OutputFormatter) extractSeries(table_ *types.Table) (types.FieldName, []string, []float64, error) {
+	y := f.Y
+	if y == "" {
+		for _, column := range table_.Columns {
+			if v, ok := table_.Rows[0].Get(column); ok {
+				if _, ok := toFloat(v); ok {
+					y = column
+					break
+				}
+			}
+		}
+		if y == "" {
+			return "", nil, nil, errors.New("could not find a numeric column to chart, use --y to select one")
+		}
+	}
+
+	x := f.X
+	if x == "" {
+		for _, column := range table_.Columns {
+			if column != y {
+				x = column
+				break
+			}
+		}
+	}
+
+	labels := []string{}
+	values := []float64{}
+	for i, row := range table_.Rows {
+		v, ok := row.Get(y)
+		if !ok {
+			continue
+		}
+		n, ok := toFloat(v)
+		if !ok {
+			continue
+		}
+		if math.IsNaN(n) || math.IsInf(n, 0) {
+			return "", nil, nil, errors.Errorf("column %s contains %v in row %d, which can't be charted", y, v, i)
+		}
+
+		label := strconv.Itoa(i)
+		if x != "" {
+			if l, ok := row.Get(x); ok && l != nil {
+				label = fmt.Sprintf("%v", l)
+			}
+		}
+
+		labels = append(labels, label)
+		values = append(values, n)
+	}
+
+	if len(values) == 0 {
+		return "", nil, nil, errors.Errorf("column %s doesn't contain any numbers", y)
+	}
+
+	return y, labels, values, nil
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	if f, ok := cast.CastNumberInterfaceToFloat[float64](v); ok {
+		return f, true
+	}
+	switch v_ := v.(type) {
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(v_), 64)
+		return f, err == nil
+	case fmt.Stringer:
+		f, err := strconv.ParseFloat(v_.String(), 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+type OutputFormatterOption func(*OutputFormatter)
+
+func WithOutputFile(file string) OutputFormatterOption {
+	return func(f *OutputFormatter) {
+		f.OutputFile = file
+	}
+}
+
+func WithChartType(chartType string) OutputFormatterOption {
+	return func(f *OutputFormatter) {
+		if chartType != "" {
+			f.ChartType = chartType
+		}
+	}
+}
+
+func WithX(x types.FieldName) OutputFormatterOption {
+	return func(f *OutputFormatter) {
+		f.X = x
+	}
+}
+
+func WithY(y types.FieldName) OutputFormatterOption {
+	return func(f *OutputFormatter) {
+		f.Y = y
+	}
+}
+
+func WithWidth(width int) OutputFormatterOption {
+	return func(f *OutputFormatter) {
+		if width > 0 {
+			f.Width = width
+		}
+	}
+}
+
+func WithHeight(height int) OutputFormatterOption {
+	return func(f *OutputFormatter) {
+		if height > 0 {
+			f.Height = height
+		}
+	}
+}
+
+func WithBins(bins int) OutputFormatterOption {
+	return func(f *OutputFormatter) {
+		if bins > 0 {
+			f.Bins = bins
+		}
+	}
+}
+
+func NewOutputFormatter(options ...OutputFormatterOption) *OutputFormatter {
+	ret := &OutputFormatter{
+		ChartType: "hbar",
+		Width:     80,
+		Height:    10,
+		Bins:      10,
+	}
+
+	for _, option := range options {
+		option(ret)
+	}
+
+	return ret
+}
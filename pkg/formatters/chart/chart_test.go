@@ -0,0 +1,126 @@
+package chart
+
+import (
+	"bytes"
+	"context"
+	"github.com/go-go-golems/glazed/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"math"
+	"testing"
+)
+
+func createHostsTable() *types.Table {
+	table_ := types.NewTable()
+	table_.Columns = []types.FieldName{"host", "requests"}
+	table_.AddRows(
+		types.NewRow(types.MRP("host", "web-1"), types.MRP("requests", 100)),
+		types.NewRow(types.MRP("host", "web-2"), types.MRP("requests", 50)),
+		types.NewRow(types.MRP("host", "db-1"), types.MRP("requests", "n/a")),
+		types.NewRow(types.MRP("host", "db-2"), types.MRP("requests", 0)),
+	)
+	return table_
+}
+
+func TestHBarChart(t *testing.T) {
+	of := NewOutputFormatter(WithChartType("hbar"), WithWidth(20))
+
+	buf := &bytes.Buffer{}
+	err := of.OutputTable(context.Background(), createHostsTable(), buf)
+	require.NoError(t, err)
+
+	// rows without a number are skipped
+	expected := `web-1 │█████████ 100
+web-2 │████▌ 50
+db-2  │ 0
+`
+	assert.Equal(t, expected, buf.String())
+}
+
+func TestBarChart(t *testing.T) {
+	of := NewOutputFormatter(WithChartType("bar"), WithWidth(20), WithHeight(2))
+
+	buf := &bytes.Buffer{}
+	err := of.OutputTable(context.Background(), createHostsTable(), buf)
+	require.NoError(t, err)
+
+	expected := `100 │████
+  0 │████ ████
+    └───────────────
+     web… web… db-2
+`
+	assert.Equal(t, expected, buf.String())
+
+	of = NewOutputFormatter(WithChartType("bar"), WithWidth(8))
+	err = of.OutputTable(context.Background(), createHostsTable(), buf)
+	assert.Error(t, err)
+}
+
+func TestSparklineAndHistogram(t *testing.T) {
+	table_ := types.NewTable()
+	table_.Columns = []types.FieldName{"latency"}
+	for _, v := range []float64{1, 2, 4, 8, 4, 2, 1, 9.5} {
+		table_.AddRows(types.NewRow(types.MRP("latency", v)))
+	}
+
+	buf := &bytes.Buffer{}
+	of := NewOutputFormatter(WithChartType("sparkline"))
+	err := of.OutputTable(context.Background(), table_, buf)
+	require.NoError(t, err)
+	assert.Equal(t, "latency ▁▂▃▇▃▂▁█ min 1 max 9.5\n", buf.String())
+
+	buf = &bytes.Buffer{}
+	of = NewOutputFormatter(WithChartType("histogram"), WithBins(2), WithWidth(30))
+	err = of.OutputTable(context.Background(), table_, buf)
+	require.NoError(t, err)
+	expected := `1 - 5.25   │████████████████ 6
+5.25 - 9.5 │█████▍ 2
+`
+	assert.Equal(t, expected, buf.String())
+}
+
+func TestChartWithoutNumbers(t *testing.T) {
+	table_ := types.NewTable()
+	table_.Columns = []types.FieldName{"host"}
+	table_.AddRows(types.NewRow(types.MRP("host", "web-1")))
+
+	of := NewOutputFormatter()
+	err := of.OutputTable(context.Background(), table_, &bytes.Buffer{})
+	assert.Error(t, err)
+}
+
+func TestChartNonFiniteValues(t *testing.T) {
+	for _, v := range []interface{}{"NaN", "Inf", "-inf", math.NaN(), math.Inf(1)} {
+		table_ := types.NewTable()
+		table_.Columns = []types.FieldName{"value"}
+		table_.AddRows(
+			types.NewRow(types.MRP("value", v)),
+			types.NewRow(types.MRP("value", 1)),
+			types.NewRow(types.MRP("value", 3)),
+		)
+
+		for _, chartType := range ChartTypes {
+			of := NewOutputFormatter(WithChartType(chartType))
+			err := of.OutputTable(context.Background(), table_, &bytes.Buffer{})
+			assert.Error(t, err, "%v %s", v, chartType)
+		}
+	}
+}
+
+func TestChartDetectedColumnIsNotKept(t *testing.T) {
+	of := NewOutputFormatter(WithChartType("sparkline"))
+
+	table_ := types.NewTable()
+	table_.Columns = []types.FieldName{"a"}
+	table_.AddRows(types.NewRow(types.MRP("a", 1)))
+	buf := &bytes.Buffer{}
+	require.NoError(t, of.OutputTable(context.Background(), table_, buf))
+	assert.Equal(t, "a ▁ min 1 max 1\n", buf.String())
+
+	table_ = types.NewTable()
+	table_.Columns = []types.FieldName{"b"}
+	table_.AddRows(types.NewRow(types.MRP("b", 2)))
+	buf = &bytes.Buffer{}
+	require.NoError(t, of.OutputTable(context.Background(), table_, buf))
+	assert.Equal(t, "b ▁ min 2 max 2\n", buf.String())
+}
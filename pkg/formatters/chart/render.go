@@ -0,0 +1,288 @@
+package chart
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+var (
+	// horizontalBlocks are the partial blocks used to render 1/8 to 7/8 of a cell, from the left.
+	horizontalBlocks = []string{"", "▏", "▎", "▍", "▌", "▋", "▊", "▉"}
+	// verticalBlocks are the partial blocks used to render 1/8 to 8/8 of a cell, from the bottom.
+	verticalBlocks = []string{"▁", "▂", "▃", "▄", "▅", "▆", "▇", "█"}
+)
+
+// formatValue renders v without decimals if it is an integer, and with at most 2 decimals otherwise.
+func formatValue(v float64) string {
+	if v == math.Trunc(v) && math.Abs(v) < 1e15 {
+		return strconv.FormatFloat(v, 'f', 0, 64)
+	}
+	s := strconv.FormatFloat(v, 'f', 2, 64)
+	return strings.TrimRight(strings.TrimRight(s, "0"), ".")
+}
+
+func pad(s string, width int) string {
+	l := utf8.RuneCountInString(s)
+	if l >= width {
+		return s
+	}
+	return s + strings.Repeat(" ", width-l)
+}
+
+func padLeft(s string, width int) string {
+	l := utf8.RuneCountInString(s)
+	if l >= width {
+		return s
+	}
+	return strings.Repeat(" ", width-l) + s
+}
+
+func truncate(s string, width int) string {
+	if utf8.RuneCountInString(s) <= width {
+		return s
+	}
+	if width <= 1 {
+		return string([]rune(s)[:width])
+	}
+	return string([]rune(s)[:width-1]) + "…"
+}
+
+func minMax(values []float64) (float64, float64) {
+	min, max := values[0], values[0]
+	for _, v := range values[1:] {
+		min = math.Min(min, v)
+		max = math.Max(max, v)
+	}
+	return min, max
+}
+
+// resample averages values into n buckets, so that long series fit in the available width.
+func resample(values []float64, n int) []float64 {
+	if n <= 0 || len(values) <= n {
+		return values
+	}
+	ret := make([]float64, n)
+	for i := 0; i < n; i++ {
+		start, end := i*len(values)/n, (i+1)*len(values)/n
+		sum := 0.0
+		for _, v := range values[start:end] {
+			sum += v
+		}
+		ret[i] = sum / float64(end-start)
+	}
+	return ret
+}
+
+func horizontalBar(cells float64) string {
+	if cells <= 0 {
+		return ""
+	}
+	full := int(cells)
+	eighths := int(math.Round((cells - float64(full)) * 8))
+	if eighths == 8 {
+		full++
+		eighths = 0
+	}
+	return strings.Repeat("█", full) + horizontalBlocks[eighths]
+}
+
+// renderHBar renders one line per value, with the bar lengths scaled to the largest value.
+//
+//	host-a │████████████▍ 42
+func renderHBar(labels []string, values []float64, width int) []string {
+	labelWidth := 0
+	for _, label := range labels {
+		labelWidth = int(math.Max(float64(labelWidth), float64(utf8.RuneCountInString(label))))
+	}
+	labelWidth = int(math.Min(float64(labelWidth), math.Max(1, float64(width/3))))
+
+	valueStrings := make([]string, len(values))
+	valueWidth := 0
+	for i, v := range values {
+		valueStrings[i] = formatValue(v)
+		valueWidth = int(math.Max(float64(valueWidth), float64(len(valueStrings[i]))))
+	}
+
+	barWidth := width - labelWidth - 2 - 1 - valueWidth
+	if barWidth < 1 {
+		barWidth = 1
+	}
+
+	_, max := minMax(values)
+
+	lines := []string{}
+	for i, v := range values {
+		bar := ""
+		if max > 0 {
+			bar = horizontalBar(v / max * float64(barWidth))
+		}
+		lines = append(lines, fmt.Sprintf("%s │%s %s",
+			pad(truncate(labels[i], labelWidth), labelWidth), bar, valueStrings[i]))
+	}
+	return lines
+}
+
+// renderBar renders vertical bars of the given height, with the y axis on the left and the labels
+// below the bars.
+func renderBar(labels []string, values []float64, width int, height int) ([]string, error) {
+	_, max := minMax(values)
+	if max <= 0 {
+		max = 1
+	}
+	maxLabel := formatValue(max)
+	axisWidth := len(maxLabel)
+
+	available := width - axisWidth - 2
+	barWidth := available/len(values) - 1
+	if barWidth < 1 {
+		return nil, errors.Errorf(
+			"too many values (%d) for a vertical bar chart of width %d, use the hbar chart type instead",
+			len(values), width)
+	}
+	if barWidth > 8 {
+		barWidth = 8
+	}
+
+	lines := []string{}
+	for r := 0; r < height; r++ {
+		b := height - 1 - r
+		axisLabel := ""
+		if r == 0 {
+			axisLabel = maxLabel
+		} else if r == height-1 {
+			axisLabel = "0"
+		}
+
+		line := padLeft(axisLabel, axisWidth) + " │"
+		for _, v := range values {
+			fill := math.Round(math.Max(v, 0)/max*float64(height*8)) - float64(b*8)
+			cell := " "
+			if fill >= 8 {
+				cell = verticalBlocks[7]
+			} else if fill > 0 {
+				cell = verticalBlocks[int(fill)-1]
+			}
+			line += strings.Repeat(cell, barWidth) + " "
+		}
+		lines = append(lines, line)
+	}
+
+	lines = append(lines, strings.Repeat(" ", axisWidth)+" └"+strings.Repeat("─", len(values)*(barWidth+1)))
+
+	labelLine := strings.Repeat(" ", axisWidth+2)
+	for _, label := range labels {
+		labelLine += pad(truncate(label, barWidth), barWidth) + " "
+	}
+	lines = append(lines, labelLine)
+
+	return lines, nil
+}
+
+// computeHistogram counts the values falling in each of bins buckets of equal width.
+func computeHistogram(values []float64, bins int) ([]string, []float64) {
+	min, max := minMax(values)
+	if min == max || bins <= 1 {
+		return []string{formatValue(min) + " - " + formatValue(max)}, []float64{float64(len(values))}
+	}
+
+	binWidth := (max - min) / float64(bins)
+	counts := make([]float64, bins)
+	for _, v := range values {
+		i := int((v - min) / binWidth)
+		if i >= bins {
+			i = bins - 1
+		}
+		counts[i]++
+	}
+
+	labels := make([]string, bins)
+	for i := range labels {
+		labels[i] = formatValue(min+float64(i)*binWidth) + " - " + formatValue(min+float64(i+1)*binWidth)
+	}
+
+	return labels, counts
+}
+
+// renderSparkline renders the values as a single line of blocks, followed by their range.
+//
+//	latency ▁▂▅█▇▃▁ min 1 max 42
+func renderSparkline(name string, values []float64, width int) []string {
+	min, max := minMax(values)
+	prefix := name + " "
+	suffix := fmt.Sprintf(" min %s max %s", formatValue(min), formatValue(max))
+
+	values = resample(values, width-utf8.RuneCountInString(prefix)-len(suffix))
+	min, max = minMax(values)
+
+	line := prefix
+	for _, v := range values {
+		level := 0
+		if max > min {
+			level = int(math.Round((v - min) / (max - min) * 7))
+		}
+		line += verticalBlocks[level]
+	}
+
+	return []string{line + suffix}
+}
+
+// renderLine plots the values on a grid of the given height, marking the values with • and
+// interpolating between them with ·.
+func renderLine(values []float64, width int, height int) []string {
+	min, max := minMax(values)
+	minLabel, maxLabel := formatValue(min), formatValue(max)
+	axisWidth := int(math.Max(float64(len(minLabel)), float64(len(maxLabel))))
+
+	plotWidth := width - axisWidth - 2
+	if plotWidth < 1 {
+		plotWidth = 1
+	}
+	values = resample(values, plotWidth)
+
+	grid := make([][]rune, height)
+	for i := range grid {
+		grid[i] = []rune(strings.Repeat(" ", plotWidth))
+	}
+
+	rowOf := func(v float64) int {
+		if max == min {
+			return height / 2
+		}
+		return height - 1 - int(math.Round((v-min)/(max-min)*float64(height-1)))
+	}
+	columnOf := func(i int) int {
+		if len(values) == 1 {
+			return 0
+		}
+		return int(math.Round(float64(i) * float64(plotWidth-1) / float64(len(values)-1)))
+	}
+
+	for i := 0; i < len(values)-1; i++ {
+		x0, x1 := columnOf(i), columnOf(i+1)
+		for c := x0 + 1; c < x1; c++ {
+			t := float64(c-x0) / float64(x1-x0)
+			grid[rowOf(values[i]+t*(values[i+1]-values[i]))][c] = '·'
+		}
+	}
+	for i, v := range values {
+		grid[rowOf(v)][columnOf(i)] = '•'
+	}
+
+	lines := []string{}
+	for r, row := range grid {
+		axisLabel := ""
+		if r == 0 {
+			axisLabel = maxLabel
+		} else if r == height-1 {
+			axisLabel = minLabel
+		}
+		lines = append(lines, padLeft(axisLabel, axisWidth)+" │"+string(row))
+	}
+	lines = append(lines, strings.Repeat(" ", axisWidth)+" └"+strings.Repeat("─", plotWidth))
+
+	return lines
+}
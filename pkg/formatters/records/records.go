@@ -0,0 +1,275 @@
+package records
+
+import (
+	"context"
+	"fmt"
+	"github.com/go-go-golems/glazed/pkg/formatters"
+	"github.com/go-go-golems/glazed/pkg/helpers/cast"
+	"github.com/go-go-golems/glazed/pkg/middlewares"
+	"github.com/go-go-golems/glazed/pkg/types"
+	"github.com/muesli/reflow/wordwrap"
+	"github.com/muesli/reflow/wrap"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// OutputFormatter renders each row as a block of `field | value` lines, preceded by a record header,
+// similar to the expanded display of psql (\x).
+//
+// Nested objects are rendered as indented fields, lists of scalars are joined,
+// and lists of objects are rendered as indented [index] entries.
+// If MaxWidth is set, values are wrapped so that lines fit within MaxWidth.
+//
+// Since each record is rendered independently, rows can be streamed.
+type OutputFormatter struct {
+	OutputFile          string
+	OutputFileTemplate  string
+	OutputMultipleFiles bool
+	MaxWidth            int
+	recordIndex         int
+}
+
+var _ formatters.TableOutputFormatter = (*OutputFormatter)(nil)
+var _ formatters.RowOutputFormatter = (*OutputFormatter)(nil)
+
+func (f *OutputFormatter) Close(ctx context.Context, w io.Writer) error {
+	return nil
+}
+
+func (f *OutputFormatter) RegisterTableMiddlewares(mw *middlewares.TableProcessor) error {
+	return nil
+}
+
+func (f *OutputFormatter) RegisterRowMiddlewares(mw *middlewares.TableProcessor) error {
+	return nil
+}
+
+func (f *OutputFormatter) ContentType() string {
+	return "text/plain"
+}
+
+func (f *OutputFormatter) OutputTable(ctx context.Context, table_ *types.Table, w io.Writer) error {
+	if f.OutputMultipleFiles {
+		if f.OutputFileTemplate == "" && f.OutputFile == "" {
+			return fmt.Errorf("neither output file or output file template is set")
+		}
+
+		for i, row := range table_.Rows {
+			outputFileName, err := formatters.ComputeOutputFilename(f.OutputFile, f.OutputFileTemplate, row, i)
+			if err != nil {
+				return err
+			}
+
+			f_, err := os.Create(outputFileName)
+			if err != nil {
+				return err
+			}
+
+			err = f.renderRecord(f_, row, i+1)
+			_ = f_.Close()
+			if err != nil {
+				return err
+			}
+
+			_, _ = fmt.Fprintf(w, "Wrote output to %s\n", outputFileName)
+		}
+
+		return nil
+	}
+
+	if f.OutputFile != "" {
+		f_, err := os.Create(f.OutputFile)
+		if err != nil {
+			return err
+		}
+		defer func(f_ *os.File) {
+			_ = f_.Close()
+		}(f_)
+		w = f_
+	}
+
+	for _, row := range table_.Rows {
+		err := f.OutputRow(ctx, row, w)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (f *OutputFormatter) OutputRow(ctx context.Context, row types.Row, w io.Writer) error {
+	f.recordIndex++
+	return f.renderRecord(w, row, f.recordIndex)
+}
+
+type recordLine struct {
+	field string
+	value string
+}
+
+func (f *OutputFormatter) renderRecord(w io.Writer, row types.Row, index int) error {
+	lines := []recordLine{}
+	for pair := row.Oldest(); pair != nil; pair = pair.Next() {
+		lines = appendValueLines(lines, "", pair.Key, pair.Value)
+	}
+
+	fieldWidth := 0
+	for _, line := range lines {
+		if l := utf8.RuneCountInString(line.field); l > fieldWidth {
+			fieldWidth = l
+		}
+	}
+
+	valueWidth := 0
+	if f.MaxWidth > 0 {
+		valueWidth = f.MaxWidth - fieldWidth - 3
+		if valueWidth < 1 {
+			valueWidth = 1
+		}
+	}
+
+	output := []recordLine{}
+	maxValueWidth := 0
+	for _, line := range lines {
+		value := line.value
+		if valueWidth > 0 {
+			// wrap on words first, then break words that are still too long
+			value = wrap.String(wordwrap.String(value, valueWidth), valueWidth)
+		}
+		for i, s := range strings.Split(value, "\n") {
+			field := line.field
+			if i > 0 {
+				field = ""
+			}
+			output = append(output, recordLine{field: field, value: s})
+			if l := utf8.RuneCountInString(s); l > maxValueWidth {
+				maxValueWidth = l
+			}
+		}
+	}
+
+	header := fmt.Sprintf("-[ RECORD %d ]", index)
+	headerWidth := fieldWidth + 3 + maxValueWidth
+	if f.MaxWidth > 0 && headerWidth > f.MaxWidth {
+		headerWidth = f.MaxWidth
+	}
+	if l := utf8.RuneCountInString(header); l < headerWidth {
+		header += strings.Repeat("-", headerWidth-l)
+	}
+	_, err := fmt.Fprintln(w, header)
+	if err != nil {
+		return err
+	}
+
+	for _, line := range output {
+		padding := strings.Repeat(" ", fieldWidth-utf8.RuneCountInString(line.field))
+		s := strings.TrimRight(fmt.Sprintf("%s%s | %s", line.field, padding, line.value), " ")
+		_, err = fmt.Fprintln(w, s)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// appendValueLines adds the lines for a single field. Nested objects and lists of objects
+// are added as indented fields.
+func appendValueLines(lines []recordLine, indent string, field string, value interface{}) []recordLine {
+	switch v := value.(type) {
+	case nil:
+		return append(lines, recordLine{field: indent + field})
+	case types.Row:
+		lines = append(lines, recordLine{field: indent + field})
+		for pair := v.Oldest(); pair != nil; pair = pair.Next() {
+			lines = appendValueLines(lines, indent+"  ", pair.Key, pair.Value)
+		}
+		return lines
+	case map[string]interface{}:
+		lines = append(lines, recordLine{field: indent + field})
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			lines = appendValueLines(lines, indent+"  ", k, v[k])
+		}
+		return lines
+	case string:
+		return append(lines, recordLine{field: indent + field, value: v})
+	}
+
+	l, err := cast.CastListToInterfaceList(value)
+	if err != nil {
+		return append(lines, recordLine{field: indent + field, value: fmt.Sprintf("%v", value)})
+	}
+
+	if isScalarList(l) {
+		elms := []string{}
+		for _, elm := range l {
+			elms = append(elms, fmt.Sprintf("%v", elm))
+		}
+		return append(lines, recordLine{field: indent + field, value: strings.Join(elms, ", ")})
+	}
+
+	lines = append(lines, recordLine{field: indent + field})
+	for i, elm := range l {
+		lines = appendValueLines(lines, indent+"  ", fmt.Sprintf("[%d]", i), elm)
+	}
+	return lines
+}
+
+func isScalarList(l []interface{}) bool {
+	for _, elm := range l {
+		switch elm.(type) {
+		case types.Row, map[string]interface{}:
+			return false
+		}
+		if _, err := cast.CastListToInterfaceList(elm); err == nil {
+			return false
+		}
+	}
+	return true
+}
+
+type OutputFormatterOption func(*OutputFormatter)
+
+func WithOutputFile(file string) OutputFormatterOption {
+	return func(f *OutputFormatter) {
+		f.OutputFile = file
+	}
+}
+
+func WithOutputFileTemplate(outputFileTemplate string) OutputFormatterOption {
+	return func(f *OutputFormatter) {
+		f.OutputFileTemplate = outputFileTemplate
+	}
+}
+
+func WithOutputMultipleFiles(outputMultipleFiles bool) OutputFormatterOption {
+	return func(f *OutputFormatter) {
+		f.OutputMultipleFiles = outputMultipleFiles
+	}
+}
+
+// WithMaxWidth sets the maximum width of the output lines. Values are not wrapped if maxWidth is 0.
+func WithMaxWidth(maxWidth int) OutputFormatterOption {
+	return func(f *OutputFormatter) {
+		f.MaxWidth = maxWidth
+	}
+}
+
+func NewOutputFormatter(options ...OutputFormatterOption) *OutputFormatter {
+	ret := &OutputFormatter{}
+
+	for _, option := range options {
+		option(ret)
+	}
+
+	return ret
+}
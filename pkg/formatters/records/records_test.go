@@ -0,0 +1,71 @@
+package records
+
+import (
+	"bytes"
+	"context"
+	"github.com/go-go-golems/glazed/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestRecordsOutputRows(t *testing.T) {
+	of := NewOutputFormatter()
+
+	buf := &bytes.Buffer{}
+	ctx := context.Background()
+	err := of.OutputRow(ctx, types.NewRow(
+		types.MRP("name", "foo"),
+		types.MRP("tags", []string{"a", "b"}),
+		types.MRP("labels", types.NewRow(
+			types.MRP("env", "prod"),
+			types.MRP("owner", nil),
+		)),
+	), buf)
+	require.NoError(t, err)
+	err = of.OutputRow(ctx, types.NewRow(
+		types.MRP("name", "bar"),
+		types.MRP("ports", []interface{}{
+			map[string]interface{}{"port": 80, "proto": "tcp"},
+		}),
+	), buf)
+	require.NoError(t, err)
+
+	expected := `-[ RECORD 1 ]-
+name    | foo
+tags    | a, b
+labels  |
+  env   | prod
+  owner |
+-[ RECORD 2 ]--
+name      | bar
+ports     |
+  [0]     |
+    port  | 80
+    proto | tcp
+`
+	assert.Equal(t, expected, buf.String())
+}
+
+func TestRecordsWrapLongValues(t *testing.T) {
+	of := NewOutputFormatter(WithMaxWidth(20))
+
+	buf := &bytes.Buffer{}
+	err := of.OutputRow(context.Background(), types.NewRow(
+		types.MRP("id", 1),
+		types.MRP("text", "the quick brown fox jumps over the lazy dog"),
+		types.MRP("hash", "0123456789abcdefghij"),
+	), buf)
+	require.NoError(t, err)
+
+	expected := `-[ RECORD 1 ]-------
+id   | 1
+text | the quick
+     | brown fox
+     | jumps over
+     | the lazy dog
+hash | 0123456789abc
+     | defghij
+`
+	assert.Equal(t, expected, buf.String())
+}
@@ -0,0 +1,392 @@
+package table
+
+import (
+	"fmt"
+	"github.com/go-go-golems/glazed/pkg/types"
+	"io"
+	"reflect"
+	"strings"
+)
+
+// This file contains the renderers for the document markup table formats
+// (LaTeX, AsciiDoc, reStructuredText, Org-mode and Jira).
+//
+// Each of them can be rendered in one go from a full table, or streamed row by row,
+// in which case the columns and alignments are computed from the first row.
+// Formats that need a closing delimiter write it out when the formatter is closed.
+
+type columnAlignment int
+
+const (
+	alignLeft columnAlignment = iota
+	alignRight
+)
+
+type markupFormat struct {
+	header func(w io.Writer, fields []types.FieldName, alignments []columnAlignment) error
+	row    func(w io.Writer, cells []string) error
+	// footer is optional, and only needed for formats that have a closing delimiter.
+	footer func(w io.Writer) error
+}
+
+var markupFormats = map[string]*markupFormat{
+	"latex": {
+		header: latexHeader,
+		row:    latexRow,
+		footer: latexFooter,
+	},
+	"asciidoc": {
+		header: asciidocHeader,
+		row:    asciidocRow,
+		footer: asciidocFooter,
+	},
+	"rst": {
+		header: rstHeader,
+		row:    rstRow,
+	},
+	"org": {
+		header: orgHeader,
+		row:    orgRow,
+	},
+	"jira": {
+		header: jiraHeader,
+		row:    jiraRow,
+	},
+}
+
+// IsMarkupFormat returns true if the table format is one of the document markup formats.
+func IsMarkupFormat(tableFormat string) bool {
+	_, ok := markupFormats[tableFormat]
+	return ok
+}
+
+// SupportsRowOutput returns true if the given table format can be streamed row by row.
+func SupportsRowOutput(tableFormat string) bool {
+	return tableFormat == "html" || tableFormat == "markdown" || IsMarkupFormat(tableFormat)
+}
+
+func (tof *OutputFormatter) makeMarkupTable(mf *markupFormat, table_ *types.Table, rows []types.Row, w io.Writer) error {
+	err := mf.header(w, table_.Columns, tof.computeAlignments(table_.Columns, rows))
+	if err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		err = mf.row(w, rowToCells(table_.Columns, row))
+		if err != nil {
+			return err
+		}
+	}
+
+	if mf.footer != nil {
+		return mf.footer(w)
+	}
+
+	return nil
+}
+
+func (tof *OutputFormatter) outputMarkupRow(mf *markupFormat, row_ types.Row, w io.Writer) error {
+	if !tof.hasOutputHeaders {
+		tof.streamedFields = types.GetFields(row_)
+		err := mf.header(w, tof.streamedFields, tof.computeAlignments(tof.streamedFields, []types.Row{row_}))
+		if err != nil {
+			return err
+		}
+
+		tof.hasOutputHeaders = true
+	}
+
+	return mf.row(w, rowToCells(tof.streamedFields, row_))
+}
+
+func rowToCells(fields []types.FieldName, row types.Row) []string {
+	cells := make([]string, len(fields))
+	for i, field := range fields {
+		if v, ok := row.Get(field); ok && v != nil {
+			cells[i] = valueToString(v)
+		}
+	}
+	return cells
+}
+
+// computeAlignments right-aligns the columns that only contain numeric values,
+// as well as the columns that are explicitly right-aligned.
+func (tof *OutputFormatter) computeAlignments(fields []types.FieldName, rows []types.Row) []columnAlignment {
+	ret := make([]columnAlignment, len(fields))
+	for i, field := range fields {
+		isRightAligned := false
+		for _, column := range tof.RightAlignedColumns {
+			if column == field {
+				isRightAligned = true
+				break
+			}
+		}
+		if isRightAligned {
+			ret[i] = alignRight
+			continue
+		}
+
+		isNumeric := false
+		for _, row := range rows {
+			v, ok := row.Get(field)
+			if !ok || v == nil {
+				continue
+			}
+			if !isNumericValue(v) {
+				isNumeric = false
+				break
+			}
+			isNumeric = true
+		}
+		if isNumeric {
+			ret[i] = alignRight
+		}
+	}
+	return ret
+}
+
+func isNumericValue(v interface{}) bool {
+	switch reflect.ValueOf(v).Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	default:
+		return false
+	}
+}
+
+// writeLines is a small helper to avoid checking the error after every single line.
+func writeLines(w io.Writer, lines ...string) error {
+	for _, line := range lines {
+		_, err := fmt.Fprintln(w, line)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func escapeCells(cells []string, escape func(string) string) []string {
+	ret := make([]string, len(cells))
+	for i, cell := range cells {
+		ret[i] = escape(cell)
+	}
+	return ret
+}
+
+// LaTeX
+
+var latexReplacer = strings.NewReplacer(
+	`\`, `\textbackslash{}`,
+	`&`, `\&`,
+	`%`, `\%`,
+	`$`, `\$`,
+	`#`, `\#`,
+	`_`, `\_`,
+	`{`, `\{`,
+	`}`, `\}`,
+	`~`, `\textasciitilde{}`,
+	`^`, `\textasciicircum{}`,
+	"\n", " ",
+)
+
+func escapeLaTeX(s string) string {
+	return latexReplacer.Replace(s)
+}
+
+func latexHeader(w io.Writer, fields []types.FieldName, alignments []columnAlignment) error {
+	spec := ""
+	for _, a := range alignments {
+		if a == alignRight {
+			spec += "r"
+		} else {
+			spec += "l"
+		}
+	}
+	return writeLines(w,
+		fmt.Sprintf(`\begin{tabular}{%s}`, spec),
+		`\hline`,
+		strings.Join(escapeCells(fields, escapeLaTeX), " & ")+` \\`,
+		`\hline`,
+	)
+}
+
+func latexRow(w io.Writer, cells []string) error {
+	return writeLines(w, strings.Join(escapeCells(cells, escapeLaTeX), " & ")+` \\`)
+}
+
+func latexFooter(w io.Writer) error {
+	return writeLines(w, `\hline`, `\end{tabular}`)
+}
+
+// AsciiDoc
+
+var asciidocReplacer = strings.NewReplacer(
+	`|`, `\|`,
+	"\n", " +\n",
+)
+
+func escapeAsciiDoc(s string) string {
+	return asciidocReplacer.Replace(s)
+}
+
+func asciidocLine(cells []string) string {
+	s := ""
+	for _, cell := range escapeCells(cells, escapeAsciiDoc) {
+		s += "|" + cell + " "
+	}
+	return strings.TrimSuffix(s, " ")
+}
+
+func asciidocHeader(w io.Writer, fields []types.FieldName, alignments []columnAlignment) error {
+	cols := []string{}
+	for _, a := range alignments {
+		if a == alignRight {
+			cols = append(cols, ">")
+		} else {
+			cols = append(cols, "<")
+		}
+	}
+	return writeLines(w,
+		fmt.Sprintf(`[cols="%s",options="header"]`, strings.Join(cols, ",")),
+		"|===",
+		asciidocLine(fields),
+		"",
+	)
+}
+
+func asciidocRow(w io.Writer, cells []string) error {
+	return writeLines(w, asciidocLine(cells))
+}
+
+func asciidocFooter(w io.Writer) error {
+	return writeLines(w, "|===")
+}
+
+// reStructuredText
+//
+// We use the list-table directive, since it doesn't require knowing the column widths
+// up front, which allows us to stream rows. Docutils doesn't support column alignment.
+
+var rstReplacer = strings.NewReplacer(
+	`\`, `\\`,
+	"*", `\*`,
+	"`", "\\`",
+	"|", `\|`,
+	"_", `\_`,
+	"\n", " ",
+)
+
+func escapeRST(s string) string {
+	return rstReplacer.Replace(s)
+}
+
+func rstListItem(cells []string) []string {
+	lines := []string{}
+	for i, cell := range escapeCells(cells, escapeRST) {
+		prefix := "     -"
+		if i == 0 {
+			prefix = "   * -"
+		}
+		if cell == "" {
+			lines = append(lines, prefix)
+		} else {
+			lines = append(lines, prefix+" "+cell)
+		}
+	}
+	return lines
+}
+
+func rstHeader(w io.Writer, fields []types.FieldName, _ []columnAlignment) error {
+	err := writeLines(w,
+		".. list-table::",
+		"   :header-rows: 1",
+		"",
+	)
+	if err != nil {
+		return err
+	}
+	return writeLines(w, rstListItem(fields)...)
+}
+
+func rstRow(w io.Writer, cells []string) error {
+	return writeLines(w, rstListItem(cells)...)
+}
+
+// Org-mode
+
+var orgReplacer = strings.NewReplacer(
+	"|", `\vert{}`,
+	"\n", " ",
+)
+
+func escapeOrg(s string) string {
+	return orgReplacer.Replace(s)
+}
+
+func orgLine(cells []string) string {
+	return "| " + strings.Join(escapeCells(cells, escapeOrg), " | ") + " |"
+}
+
+func orgHeader(w io.Writer, fields []types.FieldName, alignments []columnAlignment) error {
+	lines := []string{orgLine(fields)}
+
+	separators := []string{}
+	for range fields {
+		separators = append(separators, "---")
+	}
+	separator := "|" + strings.Join(separators, "+") + "|"
+
+	hasRightAlignment := false
+	cookies := []string{}
+	for _, a := range alignments {
+		if a == alignRight {
+			hasRightAlignment = true
+			cookies = append(cookies, "<r>")
+		} else {
+			cookies = append(cookies, "<l>")
+		}
+	}
+	if hasRightAlignment {
+		// org-mode alignment cookies are given in their own row
+		lines = append(lines, separator, "| "+strings.Join(cookies, " | ")+" |")
+	}
+
+	lines = append(lines, separator)
+	return writeLines(w, lines...)
+}
+
+func orgRow(w io.Writer, cells []string) error {
+	return writeLines(w, orgLine(cells))
+}
+
+// Jira
+
+var jiraReplacer = strings.NewReplacer(
+	"|", `\|`,
+	"{", `\{`,
+	"}", `\}`,
+	"[", `\[`,
+	"]", `\]`,
+	"\n", ` \\ `,
+)
+
+func escapeJira(s string) string {
+	return jiraReplacer.Replace(s)
+}
+
+func jiraHeader(w io.Writer, fields []types.FieldName, _ []columnAlignment) error {
+	return writeLines(w, "||"+strings.Join(escapeCells(fields, escapeJira), "||")+"||")
+}
+
+func jiraRow(w io.Writer, cells []string) error {
+	escaped := escapeCells(cells, escapeJira)
+	for i, cell := range escaped {
+		// empty cells collapse the table in jira, use a single space instead
+		if cell == "" {
+			escaped[i] = " "
+		}
+	}
+	return writeLines(w, "|"+strings.Join(escaped, "|")+"|")
+}
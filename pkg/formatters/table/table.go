@@ -51,9 +51,22 @@ type OutputFormatter struct {
 	OutputFile          string
 	PrintTableStyle     bool
 	hasOutputHeaders    bool
+	// RightAlignedColumns are right-aligned, for example because they contain formatted numbers
+	RightAlignedColumns []types.FieldName
+	// streamedFields are the columns computed from the first row when streaming a markup format
+	streamedFields []types.FieldName
 }
 
 func (tof *OutputFormatter) Close(ctx context.Context, w io.Writer) error {
+	// the table output middleware closes with a nil writer, only streamed tables need to be terminated
+	if w == nil || !tof.hasOutputHeaders {
+		return nil
+	}
+
+	if mf, ok := markupFormats[tof.TableFormat]; ok && mf.footer != nil {
+		return mf.footer(w)
+	}
+
 	return nil
 }
 
@@ -111,6 +124,12 @@ func WithPrintTableStyle(printTableStyle bool) OutputFormatterOption {
 	}
 }
 
+func WithRightAlignedColumns(columns []types.FieldName) OutputFormatterOption {
+	return func(f *OutputFormatter) {
+		f.RightAlignedColumns = columns
+	}
+}
+
 func NewOutputFormatter(tableFormat string, opts ...OutputFormatterOption) *OutputFormatter {
 	f := &OutputFormatter{
 		TableFormat: tableFormat,
@@ -134,6 +153,14 @@ func (tof *OutputFormatter) ContentType() string {
 		return "text/csv"
 	case "html":
 		return "text/html"
+	case "latex":
+		return "application/x-latex"
+	case "asciidoc":
+		return "text/asciidoc"
+	case "rst":
+		return "text/x-rst"
+	case "org":
+		return "text/org"
 	default:
 		return "text/plain"
 	}
@@ -196,6 +223,9 @@ func (tof *OutputFormatter) OutputRow(ctx context.Context, row_ types.Row, w io.
 	case "markdown":
 		return tof.outputMarkdownRow(row_, w)
 	default:
+		if mf, ok := markupFormats[tof.TableFormat]; ok {
+			return tof.outputMarkupRow(mf, row_, w)
+		}
 		return errors.New("unsupported table format")
 	}
 }
@@ -286,6 +316,10 @@ func (tof *OutputFormatter) outputHTMLRow(row_ types.Row, w io.Writer) error {
 }
 
 func (tof *OutputFormatter) makeTable(table_ *types.Table, rows []types.Row, w io.Writer) error {
+	if mf, ok := markupFormats[tof.TableFormat]; ok {
+		return tof.makeMarkupTable(mf, table_, rows, w)
+	}
+
 	t := table.NewWriter()
 
 	headers, _ := cast.CastList[interface{}](table_.Columns)
@@ -303,6 +337,16 @@ func (tof *OutputFormatter) makeTable(table_ *types.Table, rows []types.Row, w i
 		t.AppendRow(row_)
 	}
 
+	columnConfigs := []table.ColumnConfig{}
+	for _, column := range tof.RightAlignedColumns {
+		columnConfigs = append(columnConfigs, table.ColumnConfig{
+			Name:        column,
+			Align:       text.AlignRight,
+			AlignHeader: text.AlignLeft,
+		})
+	}
+	t.SetColumnConfigs(columnConfigs)
+
 	if tof.TableFormat == "markdown" {
 		s := t.RenderMarkdown()
 		_, err := w.Write([]byte(s))
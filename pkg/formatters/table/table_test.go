@@ -34,3 +34,138 @@ func TestTableRenameEndToEnd(t *testing.T) {
 	// parse s
 	assert.Equal(t, "| b |\n| --- |\n| 1 |", buf.String())
 }
+
+func TestMarkupTableFormats(t *testing.T) {
+	tests := []struct {
+		format   string
+		expected string
+	}{
+		{
+			format: "latex",
+			expected: `\begin{tabular}{lr}
+\hline
+name & count \\
+\hline
+a\_b & 1 \\
+50\% & 20 \\
+\hline
+\end{tabular}
+`,
+		},
+		{
+			format: "asciidoc",
+			expected: `[cols="<,>",options="header"]
+|===
+|name |count
+
+|a_b |1
+|50% |20
+|===
+`,
+		},
+		{
+			format: "rst",
+			expected: `.. list-table::
+   :header-rows: 1
+
+   * - name
+     - count
+   * - a\_b
+     - 1
+   * - 50%
+     - 20
+`,
+		},
+		{
+			format: "org",
+			expected: `| name | count |
+|---+---|
+| <l> | <r> |
+|---+---|
+| a_b | 1 |
+| 50% | 20 |
+`,
+		},
+		{
+			format: "jira",
+			expected: `||name||count||
+|a_b|1|
+|50%|20|
+`,
+		},
+	}
+
+	rows := []types.Row{
+		types.NewRow(types.MRP("name", "a_b"), types.MRP("count", 1)),
+		types.NewRow(types.MRP("name", "50%"), types.MRP("count", 20)),
+	}
+	ctx := context.Background()
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			table_ := types.NewTable()
+			table_.AddRows(rows...)
+
+			buf := &bytes.Buffer{}
+			of := NewOutputFormatter(tt.format)
+			err := of.OutputTable(ctx, table_, buf)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, buf.String())
+
+			// streaming the rows should result in the same output
+			buf = &bytes.Buffer{}
+			of = NewOutputFormatter(tt.format)
+			for _, row_ := range rows {
+				err = of.OutputRow(ctx, row_, buf)
+				require.NoError(t, err)
+			}
+			err = of.Close(ctx, buf)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, buf.String())
+		})
+	}
+}
+
+func TestMarkupTableEscaping(t *testing.T) {
+	row_ := types.NewRow(types.MRP("a|b", "x|y"), types.MRP("c", "{z}"))
+	ctx := context.Background()
+
+	buf := &bytes.Buffer{}
+	of := NewOutputFormatter("jira")
+	err := of.OutputRow(ctx, row_, buf)
+	require.NoError(t, err)
+	assert.Equal(t, "||a\\|b||c||\n|x\\|y|\\{z\\}|\n", buf.String())
+
+	buf = &bytes.Buffer{}
+	of = NewOutputFormatter("org")
+	err = of.OutputRow(ctx, row_, buf)
+	require.NoError(t, err)
+	assert.Equal(t, "| a\\vert{}b | c |\n|---+---|\n| x\\vert{}y | {z} |\n", buf.String())
+}
+
+func TestTableRightAlignedColumns(t *testing.T) {
+	table_ := types.NewTable()
+	table_.AddRows(
+		types.NewRow(types.MRP("name", "foo"), types.MRP("size", "1.5 KiB")),
+		types.NewRow(types.MRP("name", "bar"), types.MRP("size", "12 B")),
+	)
+	ctx := context.Background()
+
+	buf := &bytes.Buffer{}
+	of := NewOutputFormatter("ascii", WithRightAlignedColumns([]types.FieldName{"size"}))
+	err := of.OutputTable(ctx, table_, buf)
+	require.NoError(t, err)
+	assert.Equal(t, `+------+---------+
+| name | size    |
++------+---------+
+| foo  | 1.5 KiB |
+| bar  |    12 B |
++------+---------+
+`, buf.String())
+
+	buf = &bytes.Buffer{}
+	of = NewOutputFormatter("latex", WithRightAlignedColumns([]types.FieldName{"size"}))
+	err = of.OutputTable(ctx, table_, buf)
+	require.NoError(t, err)
+	assert.Contains(t, buf.String(), `\begin{tabular}{lr}`)
+}
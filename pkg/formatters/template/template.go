@@ -4,26 +4,66 @@ import (
 	"context"
 	"fmt"
 	"github.com/go-go-golems/glazed/pkg/formatters"
+	"github.com/go-go-golems/glazed/pkg/helpers/templating"
 	"github.com/go-go-golems/glazed/pkg/middlewares"
 	"github.com/go-go-golems/glazed/pkg/types"
+	"github.com/pkg/errors"
 	"io"
 	"os"
 	"text/template"
 )
 
+// OutputFormatter renders the output using go templates.
+//
+// If RowTemplate is set, the output is rendered by executing the HeaderTemplate once,
+// then RowTemplate for each row, then FooterTemplate, which allows streaming rows.
+// Otherwise, Template is rendered once with the entire table.
+//
+// If TemplateDir is set, all the files in that directory are loaded as partials,
+// and can be referenced by their path relative to TemplateDir, or by the names of
+// the templates they define.
 type OutputFormatter struct {
 	Template            string
+	RowTemplate         string
+	HeaderTemplate      string
+	FooterTemplate      string
+	TemplateDir         string
 	TemplateFuncMaps    []template.FuncMap
 	OutputFileTemplate  string
 	OutputMultipleFiles bool
 	OutputFile          string
 	AdditionalData      interface{}
+
+	rowTemplates    *template.Template
+	rowIndex        int
+	isStreamingRows bool
+	hasOutputTable  bool
 }
 
+const (
+	headerTemplateName = "glazed-header-template"
+	rowTemplateName    = "glazed-row-template"
+	footerTemplateName = "glazed-footer-template"
+)
+
 var _ formatters.TableOutputFormatter = (*OutputFormatter)(nil)
+var _ formatters.RowOutputFormatter = (*OutputFormatter)(nil)
 
 func (t *OutputFormatter) Close(ctx context.Context, w io.Writer) error {
-	return nil
+	// the table output middleware closes with a nil writer, only streamed rows need a footer
+	if w == nil || t.RowTemplate == "" || t.hasOutputTable {
+		return nil
+	}
+
+	// the header is rendered even if no rows were streamed
+	if !t.isStreamingRows {
+		err := t.startStreamingRows(w)
+		if err != nil {
+			return err
+		}
+	}
+
+	return t.rowTemplates.ExecuteTemplate(w, footerTemplateName, t.footerData(t.rowIndex))
 }
 
 func (t *OutputFormatter) RegisterTableMiddlewares(mw *middlewares.TableProcessor) error {
@@ -34,11 +74,130 @@ func (t *OutputFormatter) RegisterRowMiddlewares(mw *middlewares.TableProcessor)
 	return nil
 }
 
-func (t *OutputFormatter) OutputTable(ctx context.Context, table_ *types.Table, w io.Writer) error {
-	t2 := template.New("template")
+// newTemplate creates a template with the configured functions, and the partials from TemplateDir loaded.
+func (t *OutputFormatter) newTemplate(name string) (*template.Template, error) {
+	t2 := template.New(name)
 	for _, templateFuncMap := range t.TemplateFuncMaps {
 		t2 = t2.Funcs(templateFuncMap)
 	}
+
+	if t.TemplateDir != "" {
+		err := templating.ParseFS(t2, os.DirFS(t.TemplateDir), "**")
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to load templates from %s", t.TemplateDir)
+		}
+	}
+
+	return t2, nil
+}
+
+// parseRowTemplates parses the header, row and footer templates into a single template set.
+func (t *OutputFormatter) parseRowTemplates() (*template.Template, error) {
+	tmpl, err := t.newTemplate("template")
+	if err != nil {
+		return nil, err
+	}
+
+	for name, s := range map[string]string{
+		headerTemplateName: t.HeaderTemplate,
+		rowTemplateName:    t.RowTemplate,
+		footerTemplateName: t.FooterTemplate,
+	} {
+		_, err = tmpl.New(name).Parse(s)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse %s", name)
+		}
+	}
+
+	return tmpl, nil
+}
+
+func (t *OutputFormatter) headerData() map[string]interface{} {
+	return map[string]interface{}{
+		"data": t.AdditionalData,
+	}
+}
+
+func (t *OutputFormatter) rowData(row types.Row, rowIndex int) map[string]interface{} {
+	return map[string]interface{}{
+		"row":      types.RowToMap(row),
+		"rowIndex": rowIndex,
+		"data":     t.AdditionalData,
+	}
+}
+
+func (t *OutputFormatter) footerData(rowCount int) map[string]interface{} {
+	return map[string]interface{}{
+		"rowCount": rowCount,
+		"data":     t.AdditionalData,
+	}
+}
+
+// renderRows renders rows by using the header, row and footer templates.
+func (t *OutputFormatter) renderRows(tmpl *template.Template, rows []types.Row, w io.Writer) error {
+	err := tmpl.ExecuteTemplate(w, headerTemplateName, t.headerData())
+	if err != nil {
+		return err
+	}
+
+	for i, row := range rows {
+		err = tmpl.ExecuteTemplate(w, rowTemplateName, t.rowData(row, i))
+		if err != nil {
+			return err
+		}
+	}
+
+	return tmpl.ExecuteTemplate(w, footerTemplateName, t.footerData(len(rows)))
+}
+
+// startStreamingRows parses the row templates and renders the header.
+func (t *OutputFormatter) startStreamingRows(w io.Writer) error {
+	tmpl, err := t.parseRowTemplates()
+	if err != nil {
+		return err
+	}
+	t.rowTemplates = tmpl
+
+	err = t.rowTemplates.ExecuteTemplate(w, headerTemplateName, t.headerData())
+	if err != nil {
+		return err
+	}
+	t.isStreamingRows = true
+	return nil
+}
+
+func (t *OutputFormatter) OutputRow(ctx context.Context, row types.Row, w io.Writer) error {
+	if t.RowTemplate == "" {
+		return errors.New("row output requires a row template")
+	}
+
+	if !t.isStreamingRows {
+		err := t.startStreamingRows(w)
+		if err != nil {
+			return err
+		}
+	}
+
+	err := t.rowTemplates.ExecuteTemplate(w, rowTemplateName, t.rowData(row, t.rowIndex))
+	if err != nil {
+		return err
+	}
+	t.rowIndex++
+
+	return nil
+}
+
+func (t *OutputFormatter) OutputTable(ctx context.Context, table_ *types.Table, w io.Writer) error {
+	t.hasOutputTable = true
+
+	if t.RowTemplate != "" {
+		return t.outputTableWithRowTemplates(table_, w)
+	}
+
+	t2, err := t.newTemplate("template")
+	if err != nil {
+		return err
+	}
 	tmpl, err := t2.Parse(t.Template)
 	if err != nil {
 		return err
@@ -119,6 +278,55 @@ func (t *OutputFormatter) OutputTable(ctx context.Context, table_ *types.Table,
 	return nil
 }
 
+func (t *OutputFormatter) outputTableWithRowTemplates(table_ *types.Table, w io.Writer) error {
+	tmpl, err := t.parseRowTemplates()
+	if err != nil {
+		return err
+	}
+
+	if t.OutputMultipleFiles {
+		if t.OutputFileTemplate == "" && t.OutputFile == "" {
+			return fmt.Errorf("neither output file or output file template is set")
+		}
+
+		for i, row := range table_.Rows {
+			outputFileName, err := formatters.ComputeOutputFilename(t.OutputFile, t.OutputFileTemplate, row, i)
+			if err != nil {
+				return err
+			}
+
+			f_, err := os.Create(outputFileName)
+			if err != nil {
+				return err
+			}
+
+			err = t.renderRows(tmpl, []types.Row{row}, f_)
+			_ = f_.Close()
+			if err != nil {
+				return err
+			}
+
+			_, _ = fmt.Fprintf(w, "Wrote output to %s\n", outputFileName)
+		}
+
+		return nil
+	}
+
+	if t.OutputFile != "" {
+		f_, err := os.Create(t.OutputFile)
+		if err != nil {
+			return err
+		}
+		defer func(f_ *os.File) {
+			_ = f_.Close()
+		}(f_)
+
+		w = f_
+	}
+
+	return t.renderRows(tmpl, table_.Rows, w)
+}
+
 func (f *OutputFormatter) ContentType() string {
 	return "text/plain"
 }
@@ -137,6 +345,30 @@ func WithAdditionalData(additionalData interface{}) OutputFormatterOption {
 	}
 }
 
+func WithRowTemplate(rowTemplate string) OutputFormatterOption {
+	return func(t *OutputFormatter) {
+		t.RowTemplate = rowTemplate
+	}
+}
+
+func WithHeaderTemplate(headerTemplate string) OutputFormatterOption {
+	return func(t *OutputFormatter) {
+		t.HeaderTemplate = headerTemplate
+	}
+}
+
+func WithFooterTemplate(footerTemplate string) OutputFormatterOption {
+	return func(t *OutputFormatter) {
+		t.FooterTemplate = footerTemplate
+	}
+}
+
+func WithTemplateDir(templateDir string) OutputFormatterOption {
+	return func(t *OutputFormatter) {
+		t.TemplateDir = templateDir
+	}
+}
+
 func WithOutputFile(outputFile string) OutputFormatterOption {
 	return func(t *OutputFormatter) {
 		t.OutputFile = outputFile
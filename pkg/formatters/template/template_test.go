@@ -41,3 +41,83 @@ func TestTemplateRenameEndToEnd(t *testing.T) {
 
 	assert.Equal(t, `1`, buf.String())
 }
+
+func TestTemplateRowStreaming(t *testing.T) {
+	of := NewOutputFormatter("",
+		WithTemplateFuncMaps([]template.FuncMap{
+			sprig.TxtFuncMap(),
+			templating.TemplateFuncs,
+		}),
+		WithHeaderTemplate("# {{ .data.title }}\n"),
+		WithRowTemplate(`{{ .rowIndex }} {{ template "row" .row }}`),
+		WithFooterTemplate("{{ .rowCount }} rows\n"),
+		WithTemplateDir("test-data"),
+		WithAdditionalData(map[string]interface{}{"title": "Report"}),
+	)
+	ctx := context.Background()
+
+	buf := &bytes.Buffer{}
+	p_ := middlewares.NewTableProcessor(
+		middlewares.WithRowMiddleware(row.NewOutputMiddleware(of, buf)),
+	)
+	err := p_.AddRow(ctx, types.NewRow(types.MRP("name", "foo"), types.MRP("count", 1)))
+	require.NoError(t, err)
+	// the header and first row are output before the processor is closed
+	assert.Equal(t, "# Report\n0 - foo: 1\n", buf.String())
+
+	err = p_.AddRow(ctx, types.NewRow(types.MRP("name", "bar"), types.MRP("count", 2)))
+	require.NoError(t, err)
+	err = p_.Close(ctx)
+	require.NoError(t, err)
+
+	assert.Equal(t, "# Report\n0 - foo: 1\n1 - bar: 2\n2 rows\n", buf.String())
+}
+
+func TestTemplateRowStreamingNoRows(t *testing.T) {
+	of := NewOutputFormatter("",
+		WithHeaderTemplate("# Report\n"),
+		WithRowTemplate("{{ .row.name }}\n"),
+		WithFooterTemplate("{{ .rowCount }} rows\n"),
+	)
+	ctx := context.Background()
+
+	buf := &bytes.Buffer{}
+	p_ := middlewares.NewTableProcessor(
+		middlewares.WithRowMiddleware(row.NewOutputMiddleware(of, buf)),
+	)
+	err := p_.Close(ctx)
+	require.NoError(t, err)
+
+	assert.Equal(t, "# Report\n0 rows\n", buf.String())
+}
+
+func TestTemplateRowTemplateTableOutput(t *testing.T) {
+	of := NewOutputFormatter("",
+		WithRowTemplate(`{{ .row.name }},`),
+		WithFooterTemplate("{{ .rowCount }}"),
+	)
+	table_ := types.NewTable()
+	table_.AddRows(
+		types.NewRow(types.MRP("name", "foo")),
+		types.NewRow(types.MRP("name", "bar")),
+	)
+
+	buf := &bytes.Buffer{}
+	err := of.OutputTable(context.Background(), table_, buf)
+	require.NoError(t, err)
+	assert.Equal(t, "foo,bar,2", buf.String())
+}
+
+func TestTemplatePartials(t *testing.T) {
+	of := NewOutputFormatter(
+		`{{ range .rows }}{{ template "partials/row.tmpl" . }}{{ template "row" . }}{{ end }}`,
+		WithTemplateDir("test-data"),
+	)
+	table_ := types.NewTable()
+	table_.AddRows(types.NewRow(types.MRP("name", "foo"), types.MRP("count", 1)))
+
+	buf := &bytes.Buffer{}
+	err := of.OutputTable(context.Background(), table_, buf)
+	require.NoError(t, err)
+	assert.Equal(t, "\n- foo: 1\n", buf.String())
+}
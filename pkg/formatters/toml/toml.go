@@ -0,0 +1,222 @@
+package toml
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/go-go-golems/glazed/pkg/formatters"
+	"github.com/go-go-golems/glazed/pkg/helpers/cast"
+	"github.com/go-go-golems/glazed/pkg/middlewares"
+	"github.com/go-go-golems/glazed/pkg/types"
+	"github.com/pelletier/go-toml/v2"
+	"io"
+	"os"
+)
+
+// OutputFormatter renders rows as a TOML array of tables, with one [[TableName]] table per row.
+//
+// Fields are output in the order of the row, and nested objects and lists are rendered inline.
+// Since TOML has no null value, fields that are nil are omitted.
+type OutputFormatter struct {
+	OutputFile          string
+	OutputFileTemplate  string
+	OutputMultipleFiles bool
+	TableName           string
+	isFirstRow          bool
+}
+
+var _ formatters.TableOutputFormatter = (*OutputFormatter)(nil)
+var _ formatters.RowOutputFormatter = (*OutputFormatter)(nil)
+
+func (f *OutputFormatter) Close(ctx context.Context, w io.Writer) error {
+	return nil
+}
+
+func (f *OutputFormatter) RegisterTableMiddlewares(mw *middlewares.TableProcessor) error {
+	return nil
+}
+
+func (f *OutputFormatter) RegisterRowMiddlewares(mw *middlewares.TableProcessor) error {
+	return nil
+}
+
+func (f *OutputFormatter) ContentType() string {
+	return "application/toml"
+}
+
+func (f *OutputFormatter) OutputTable(ctx context.Context, table_ *types.Table, w io.Writer) error {
+	if f.OutputMultipleFiles {
+		if f.OutputFileTemplate == "" && f.OutputFile == "" {
+			return fmt.Errorf("neither output file or output file template is set")
+		}
+
+		for i, row := range table_.Rows {
+			outputFileName, err := formatters.ComputeOutputFilename(f.OutputFile, f.OutputFileTemplate, row, i)
+			if err != nil {
+				return err
+			}
+
+			f_, err := os.Create(outputFileName)
+			if err != nil {
+				return err
+			}
+
+			// each file contains a single row as top-level keys
+			err = encodeFields(f_, row)
+			if err != nil {
+				_ = f_.Close()
+				return err
+			}
+			_ = f_.Close()
+			_, _ = fmt.Fprintf(w, "Wrote output to %s\n", outputFileName)
+		}
+
+		return nil
+	}
+
+	if f.OutputFile != "" {
+		f_, err := os.Create(f.OutputFile)
+		if err != nil {
+			return err
+		}
+		defer func(f_ *os.File) {
+			_ = f_.Close()
+		}(f_)
+		w = f_
+	}
+
+	for _, row := range table_.Rows {
+		err := f.OutputRow(ctx, row, w)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (f *OutputFormatter) OutputRow(ctx context.Context, row types.Row, w io.Writer) error {
+	if !f.isFirstRow {
+		_, err := w.Write([]byte("\n"))
+		if err != nil {
+			return err
+		}
+	}
+	f.isFirstRow = false
+
+	header, err := encodeKey(f.TableName)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "[[%s]]\n", header)
+	if err != nil {
+		return err
+	}
+
+	return encodeFields(w, row)
+}
+
+// encodeFields writes out each field of the row as a single key/value line, which
+// preserves the order of the row.
+func encodeFields(w io.Writer, row types.Row) error {
+	for pair := row.Oldest(); pair != nil; pair = pair.Next() {
+		if pair.Value == nil {
+			continue
+		}
+
+		enc := toml.NewEncoder(w)
+		enc.SetTablesInline(true)
+		err := enc.Encode(map[string]interface{}{pair.Key: normalizeValue(pair.Value)})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func encodeKey(key string) (string, error) {
+	var buf bytes.Buffer
+	enc := toml.NewEncoder(&buf)
+	// encode a dummy value to let go-toml take care of quoting the key
+	err := enc.Encode(map[string]interface{}{key: 0})
+	if err != nil {
+		return "", err
+	}
+	return string(bytes.TrimSuffix(buf.Bytes(), []byte(" = 0\n"))), nil
+}
+
+// normalizeValue converts nested rows to maps and drops nil values, which can't be represented in TOML.
+func normalizeValue(v interface{}) interface{} {
+	switch v_ := v.(type) {
+	case types.Row:
+		ret := map[string]interface{}{}
+		for pair := v_.Oldest(); pair != nil; pair = pair.Next() {
+			if pair.Value != nil {
+				ret[pair.Key] = normalizeValue(pair.Value)
+			}
+		}
+		return ret
+	case map[string]interface{}:
+		ret := map[string]interface{}{}
+		for k, elm := range v_ {
+			if elm != nil {
+				ret[k] = normalizeValue(elm)
+			}
+		}
+		return ret
+	}
+
+	if l, err := cast.CastListToInterfaceList(v); err == nil {
+		ret := []interface{}{}
+		for _, elm := range l {
+			if elm != nil {
+				ret = append(ret, normalizeValue(elm))
+			}
+		}
+		return ret
+	}
+
+	return v
+}
+
+type OutputFormatterOption func(*OutputFormatter)
+
+func WithOutputFile(file string) OutputFormatterOption {
+	return func(f *OutputFormatter) {
+		f.OutputFile = file
+	}
+}
+
+func WithOutputFileTemplate(outputFileTemplate string) OutputFormatterOption {
+	return func(f *OutputFormatter) {
+		f.OutputFileTemplate = outputFileTemplate
+	}
+}
+
+func WithOutputMultipleFiles(outputMultipleFiles bool) OutputFormatterOption {
+	return func(f *OutputFormatter) {
+		f.OutputMultipleFiles = outputMultipleFiles
+	}
+}
+
+func WithTableName(tableName string) OutputFormatterOption {
+	return func(f *OutputFormatter) {
+		if tableName != "" {
+			f.TableName = tableName
+		}
+	}
+}
+
+func NewOutputFormatter(options ...OutputFormatterOption) *OutputFormatter {
+	ret := &OutputFormatter{
+		TableName:  "rows",
+		isFirstRow: true,
+	}
+
+	for _, option := range options {
+		option(ret)
+	}
+
+	return ret
+}
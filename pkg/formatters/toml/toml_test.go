@@ -0,0 +1,50 @@
+package toml
+
+import (
+	"bytes"
+	"context"
+	"github.com/go-go-golems/glazed/pkg/types"
+	"github.com/pelletier/go-toml/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestTOMLOutputTable(t *testing.T) {
+	of := NewOutputFormatter(WithTableName("hosts"))
+	table_ := types.NewTable()
+	table_.AddRows(
+		types.NewRow(
+			types.MRP("name", "foo"),
+			types.MRP("port", 8080),
+			types.MRP("skipped", nil),
+			types.MRP("tags", []string{"a", "b"}),
+		),
+		types.NewRow(
+			types.MRP("name", "bar"),
+			types.MRP("labels", types.NewRow(types.MRP("env", "prod"))),
+		),
+	)
+
+	buf := &bytes.Buffer{}
+	err := of.OutputTable(context.Background(), table_, buf)
+	require.NoError(t, err)
+
+	expected := `[[hosts]]
+name = 'foo'
+port = 8080
+tags = ['a', 'b']
+
+[[hosts]]
+name = 'bar'
+labels = {env = 'prod'}
+`
+	assert.Equal(t, expected, buf.String())
+
+	// make sure the output can be read back
+	data := map[string][]map[string]interface{}{}
+	err = toml.Unmarshal(buf.Bytes(), &data)
+	require.NoError(t, err)
+	require.Len(t, data["hosts"], 2)
+	assert.Equal(t, "bar", data["hosts"][1]["name"])
+}
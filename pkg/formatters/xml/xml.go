@@ -0,0 +1,346 @@
+package xml
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"github.com/go-go-golems/glazed/pkg/formatters"
+	"github.com/go-go-golems/glazed/pkg/helpers/cast"
+	"github.com/go-go-golems/glazed/pkg/middlewares"
+	"github.com/go-go-golems/glazed/pkg/types"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// OutputFormatter renders rows as XML elements wrapped in a root element.
+//
+// Scalar fields are rendered as child elements, unless they are listed in AttributeFields
+// (or ScalarsAsAttributes is set), in which case they are rendered as attributes of the row element.
+// Nested objects are rendered as nested elements, lists as a sequence of <item> elements.
+type OutputFormatter struct {
+	OutputFile          string
+	OutputFileTemplate  string
+	OutputMultipleFiles bool
+	RootElement         string
+	RowElement          string
+	AttributeFields     []string
+	ScalarsAsAttributes bool
+	isStreamingRows     bool
+	hasOutputTable      bool
+}
+
+var _ formatters.TableOutputFormatter = (*OutputFormatter)(nil)
+var _ formatters.RowOutputFormatter = (*OutputFormatter)(nil)
+
+func (f *OutputFormatter) Close(ctx context.Context, w io.Writer) error {
+	if f.isStreamingRows {
+		_, err := fmt.Fprintf(w, "</%s>\n", elementName(f.RootElement))
+		if err != nil {
+			return err
+		}
+		return nil
+	}
+
+	// no rows were streamed, output an empty document
+	if w != nil && !f.hasOutputTable {
+		root := elementName(f.RootElement)
+		_, err := fmt.Fprintf(w, "%s<%s>\n</%s>\n", xml.Header, root, root)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *OutputFormatter) RegisterTableMiddlewares(mw *middlewares.TableProcessor) error {
+	return nil
+}
+
+func (f *OutputFormatter) RegisterRowMiddlewares(mw *middlewares.TableProcessor) error {
+	return nil
+}
+
+func (f *OutputFormatter) ContentType() string {
+	return "application/xml"
+}
+
+func (f *OutputFormatter) OutputTable(ctx context.Context, table_ *types.Table, w io.Writer) error {
+	f.hasOutputTable = true
+
+	if f.OutputMultipleFiles {
+		if f.OutputFileTemplate == "" && f.OutputFile == "" {
+			return fmt.Errorf("neither output file or output file template is set")
+		}
+
+		for i, row := range table_.Rows {
+			outputFileName, err := formatters.ComputeOutputFilename(f.OutputFile, f.OutputFileTemplate, row, i)
+			if err != nil {
+				return err
+			}
+
+			f_, err := os.Create(outputFileName)
+			if err != nil {
+				return err
+			}
+
+			// each file contains a single row as its document element
+			_, err = f_.Write([]byte(xml.Header))
+			if err == nil {
+				err = f.encodeRow(f_, row, "")
+			}
+			if err != nil {
+				_ = f_.Close()
+				return err
+			}
+			_ = f_.Close()
+			_, _ = fmt.Fprintf(w, "Wrote output to %s\n", outputFileName)
+		}
+
+		return nil
+	}
+
+	if f.OutputFile != "" {
+		f_, err := os.Create(f.OutputFile)
+		if err != nil {
+			return err
+		}
+		defer func(f_ *os.File) {
+			_ = f_.Close()
+		}(f_)
+		w = f_
+	}
+
+	_, err := fmt.Fprintf(w, "%s<%s>\n", xml.Header, elementName(f.RootElement))
+	if err != nil {
+		return err
+	}
+
+	for _, row := range table_.Rows {
+		err = f.encodeRow(w, row, "  ")
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = fmt.Fprintf(w, "</%s>\n", elementName(f.RootElement))
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (f *OutputFormatter) OutputRow(ctx context.Context, row types.Row, w io.Writer) error {
+	if !f.isStreamingRows {
+		_, err := fmt.Fprintf(w, "%s<%s>\n", xml.Header, elementName(f.RootElement))
+		if err != nil {
+			return err
+		}
+		f.isStreamingRows = true
+	}
+
+	return f.encodeRow(w, row, "  ")
+}
+
+func (f *OutputFormatter) isAttribute(field types.FieldName, value interface{}) bool {
+	if !isScalar(value) {
+		return false
+	}
+	if f.ScalarsAsAttributes {
+		return true
+	}
+	for _, attributeField := range f.AttributeFields {
+		if attributeField == field {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *OutputFormatter) encodeRow(w io.Writer, row types.Row, prefix string) error {
+	enc := xml.NewEncoder(w)
+	enc.Indent(prefix, "  ")
+
+	start := xml.StartElement{Name: xml.Name{Local: elementName(f.RowElement)}}
+	for pair := row.Oldest(); pair != nil; pair = pair.Next() {
+		if pair.Value != nil && f.isAttribute(pair.Key, pair.Value) {
+			start.Attr = append(start.Attr, xml.Attr{
+				Name:  xml.Name{Local: elementName(pair.Key)},
+				Value: fmt.Sprintf("%v", pair.Value),
+			})
+		}
+	}
+
+	err := enc.EncodeToken(start)
+	if err != nil {
+		return err
+	}
+
+	for pair := row.Oldest(); pair != nil; pair = pair.Next() {
+		if pair.Value != nil && f.isAttribute(pair.Key, pair.Value) {
+			continue
+		}
+		err = encodeValue(enc, pair.Key, pair.Value)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = enc.EncodeToken(start.End())
+	if err != nil {
+		return err
+	}
+	err = enc.Flush()
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write([]byte("\n"))
+	return err
+}
+
+func encodeValue(enc *xml.Encoder, name string, value interface{}) error {
+	start := xml.StartElement{Name: xml.Name{Local: elementName(name)}}
+	err := enc.EncodeToken(start)
+	if err != nil {
+		return err
+	}
+
+	switch v := value.(type) {
+	case nil:
+	case types.Row:
+		for pair := v.Oldest(); pair != nil; pair = pair.Next() {
+			err = encodeValue(enc, pair.Key, pair.Value)
+			if err != nil {
+				return err
+			}
+		}
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			err = encodeValue(enc, k, v[k])
+			if err != nil {
+				return err
+			}
+		}
+	default:
+		if l, err_ := cast.CastListToInterfaceList(v); err_ == nil {
+			for _, elm := range l {
+				err = encodeValue(enc, "item", elm)
+				if err != nil {
+					return err
+				}
+			}
+		} else {
+			err = enc.EncodeToken(xml.CharData(fmt.Sprintf("%v", v)))
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return enc.EncodeToken(start.End())
+}
+
+func isScalar(v interface{}) bool {
+	switch v.(type) {
+	case nil, types.Row, map[string]interface{}:
+		return false
+	}
+	_, err := cast.CastListToInterfaceList(v)
+	return err != nil
+}
+
+// elementName converts a field name into a valid XML name by replacing invalid characters
+// with underscores.
+func elementName(s string) string {
+	if s == "" {
+		return "_"
+	}
+
+	ret := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '-' || r == '.' {
+			return r
+		}
+		return '_'
+	}, s)
+
+	first := []rune(ret)[0]
+	if !unicode.IsLetter(first) && first != '_' {
+		ret = "_" + ret
+	}
+	// names starting with xml are reserved
+	if strings.HasPrefix(strings.ToLower(ret), "xml") {
+		ret = "_" + ret
+	}
+
+	return ret
+}
+
+type OutputFormatterOption func(*OutputFormatter)
+
+func WithOutputFile(file string) OutputFormatterOption {
+	return func(f *OutputFormatter) {
+		f.OutputFile = file
+	}
+}
+
+func WithOutputFileTemplate(outputFileTemplate string) OutputFormatterOption {
+	return func(f *OutputFormatter) {
+		f.OutputFileTemplate = outputFileTemplate
+	}
+}
+
+func WithOutputMultipleFiles(outputMultipleFiles bool) OutputFormatterOption {
+	return func(f *OutputFormatter) {
+		f.OutputMultipleFiles = outputMultipleFiles
+	}
+}
+
+func WithRootElement(rootElement string) OutputFormatterOption {
+	return func(f *OutputFormatter) {
+		if rootElement != "" {
+			f.RootElement = rootElement
+		}
+	}
+}
+
+func WithRowElement(rowElement string) OutputFormatterOption {
+	return func(f *OutputFormatter) {
+		if rowElement != "" {
+			f.RowElement = rowElement
+		}
+	}
+}
+
+func WithAttributeFields(attributeFields []string) OutputFormatterOption {
+	return func(f *OutputFormatter) {
+		f.AttributeFields = attributeFields
+	}
+}
+
+func WithScalarsAsAttributes(scalarsAsAttributes bool) OutputFormatterOption {
+	return func(f *OutputFormatter) {
+		f.ScalarsAsAttributes = scalarsAsAttributes
+	}
+}
+
+func NewOutputFormatter(options ...OutputFormatterOption) *OutputFormatter {
+	ret := &OutputFormatter{
+		RootElement: "rows",
+		RowElement:  "row",
+	}
+
+	for _, option := range options {
+		option(ret)
+	}
+
+	return ret
+}
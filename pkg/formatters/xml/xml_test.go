@@ -0,0 +1,82 @@
+package xml
+
+import (
+	"bytes"
+	"context"
+	"github.com/go-go-golems/glazed/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestXMLOutputTable(t *testing.T) {
+	of := NewOutputFormatter(
+		WithRootElement("items"),
+		WithRowElement("item"),
+		WithAttributeFields([]string{"id"}),
+	)
+	table_ := types.NewTable()
+	table_.AddRows(
+		types.NewRow(
+			types.MRP("id", 1),
+			types.MRP("name", "a & b"),
+			types.MRP("tags", []interface{}{"x", "y"}),
+			types.MRP("nested", types.NewRow(types.MRP("z", 2))),
+		),
+	)
+
+	buf := &bytes.Buffer{}
+	err := of.OutputTable(context.Background(), table_, buf)
+	require.NoError(t, err)
+
+	expected := `<?xml version="1.0" encoding="UTF-8"?>
+<items>
+  <item id="1">
+    <name>a &amp; b</name>
+    <tags>
+      <item>x</item>
+      <item>y</item>
+    </tags>
+    <nested>
+      <z>2</z>
+    </nested>
+  </item>
+</items>
+`
+	assert.Equal(t, expected, buf.String())
+}
+
+func TestXMLOutputRows(t *testing.T) {
+	of := NewOutputFormatter(WithScalarsAsAttributes(true))
+	ctx := context.Background()
+
+	buf := &bytes.Buffer{}
+	err := of.OutputRow(ctx, types.NewRow(types.MRP("a", 1), types.MRP("b c", "x")), buf)
+	require.NoError(t, err)
+	err = of.OutputRow(ctx, types.NewRow(types.MRP("a", 2), types.MRP("1st", "y")), buf)
+	require.NoError(t, err)
+	err = of.Close(ctx, buf)
+	require.NoError(t, err)
+
+	expected := `<?xml version="1.0" encoding="UTF-8"?>
+<rows>
+  <row a="1" b_c="x"></row>
+  <row a="2" _1st="y"></row>
+</rows>
+`
+	assert.Equal(t, expected, buf.String())
+}
+
+func TestXMLOutputNoRows(t *testing.T) {
+	of := NewOutputFormatter()
+
+	buf := &bytes.Buffer{}
+	err := of.Close(context.Background(), buf)
+	require.NoError(t, err)
+
+	expected := `<?xml version="1.0" encoding="UTF-8"?>
+<rows>
+</rows>
+`
+	assert.Equal(t, expected, buf.String())
+}
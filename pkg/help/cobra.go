@@ -104,6 +104,7 @@ func renderCommandHelpPage(c *cobra.Command, options *RenderOptions, hs *HelpSys
 	data["Command"] = c
 	data["FlagGroupUsage"] = flagGroupUsage
 	data["FlagUsageMaxLength"] = maxLength
+	data["ParameterRelations"] = glazed_cobra.GetParameterRelationDescriptions(c)
 	data["HelpCommand"] = options.HelpCommand
 	data["Slug"] = c.Name()
 
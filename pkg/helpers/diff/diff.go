@@ -0,0 +1,233 @@
+package diff
+
+import (
+	"fmt"
+	"github.com/go-go-golems/glazed/pkg/helpers/cast"
+	"github.com/go-go-golems/glazed/pkg/types"
+	"github.com/pkg/errors"
+	"reflect"
+	"strings"
+)
+
+const (
+	// ChangeField is the column describing the kind of change of each row.
+	ChangeField = "_change"
+
+	ChangeAdded     = "added"
+	ChangeRemoved   = "removed"
+	ChangeChanged   = "changed"
+	ChangeUnchanged = "unchanged"
+)
+
+// RowDiffer matches the rows of two data sets on their key columns and describes
+// the differences as rows.
+//
+// Each resulting row contains the _change column (added, removed, changed or unchanged),
+// the key columns, and for each other field a <field>.old and a <field>.new column.
+// Ignored fields are neither compared nor output.
+type RowDiffer struct {
+	Keys             []types.FieldName
+	IgnoreFields     []types.FieldName
+	IncludeUnchanged bool
+}
+
+type RowDifferOption func(*RowDiffer)
+
+func WithIgnoreFields(fields []types.FieldName) RowDifferOption {
+	return func(d *RowDiffer) {
+		d.IgnoreFields = fields
+	}
+}
+
+func WithIncludeUnchanged(includeUnchanged bool) RowDifferOption {
+	return func(d *RowDiffer) {
+		d.IncludeUnchanged = includeUnchanged
+	}
+}
+
+func NewRowDiffer(keys []types.FieldName, options ...RowDifferOption) *RowDiffer {
+	ret := &RowDiffer{
+		Keys: keys,
+	}
+
+	for _, option := range options {
+		option(ret)
+	}
+
+	return ret
+}
+
+// Diff compares the old and new rows. Removed and changed rows are returned in the order of the old rows,
+// followed by the added rows in the order of the new rows.
+func (d *RowDiffer) Diff(oldRows []types.Row, newRows []types.Row) ([]types.Row, error) {
+	if len(d.Keys) == 0 {
+		return nil, errors.New("at least one key column is required")
+	}
+
+	oldIndex, err := d.indexRows(oldRows)
+	if err != nil {
+		return nil, errors.Wrap(err, "old rows")
+	}
+	newIndex, err := d.indexRows(newRows)
+	if err != nil {
+		return nil, errors.Wrap(err, "new rows")
+	}
+
+	fields := d.collectFields(oldRows, newRows)
+
+	ret := []types.Row{}
+	for _, oldRow := range oldRows {
+		newRow, ok := newIndex[d.key(oldRow)]
+		if !ok {
+			ret = append(ret, d.makeRow(ChangeRemoved, oldRow, fields, oldRow, nil))
+			continue
+		}
+
+		change := ChangeUnchanged
+		for _, field := range fields {
+			oldValue, _ := oldRow.Get(field)
+			newValue, _ := newRow.Get(field)
+			if !Equal(oldValue, newValue) {
+				change = ChangeChanged
+				break
+			}
+		}
+
+		if change == ChangeChanged || d.IncludeUnchanged {
+			ret = append(ret, d.makeRow(change, oldRow, fields, oldRow, newRow))
+		}
+	}
+
+	for _, newRow := range newRows {
+		if _, ok := oldIndex[d.key(newRow)]; !ok {
+			ret = append(ret, d.makeRow(ChangeAdded, newRow, fields, nil, newRow))
+		}
+	}
+
+	return ret, nil
+}
+
+func (d *RowDiffer) makeRow(change string, keyRow types.Row, fields []types.FieldName, oldRow types.Row, newRow types.Row) types.Row {
+	ret := types.NewRow(types.MRP(ChangeField, change))
+	for _, key := range d.Keys {
+		v, _ := keyRow.Get(key)
+		ret.Set(key, v)
+	}
+
+	for _, field := range fields {
+		var oldValue, newValue interface{}
+		if oldRow != nil {
+			oldValue, _ = oldRow.Get(field)
+		}
+		if newRow != nil {
+			newValue, _ = newRow.Get(field)
+		}
+		ret.Set(field+".old", oldValue)
+		ret.Set(field+".new", newValue)
+	}
+
+	return ret
+}
+
+func (d *RowDiffer) isKey(field types.FieldName) bool {
+	for _, key := range d.Keys {
+		if key == field {
+			return true
+		}
+	}
+	return false
+}
+
+func (d *RowDiffer) isIgnored(field types.FieldName) bool {
+	for _, ignored := range d.IgnoreFields {
+		if ignored == field {
+			return true
+		}
+	}
+	return false
+}
+
+// collectFields returns the fields of both data sets that are neither keys nor ignored,
+// in the order they first appear.
+func (d *RowDiffer) collectFields(rowLists ...[]types.Row) []types.FieldName {
+	seen := map[types.FieldName]bool{}
+	ret := []types.FieldName{}
+	for _, rows := range rowLists {
+		for _, row := range rows {
+			for pair := row.Oldest(); pair != nil; pair = pair.Next() {
+				if seen[pair.Key] || d.isKey(pair.Key) || d.isIgnored(pair.Key) {
+					continue
+				}
+				seen[pair.Key] = true
+				ret = append(ret, pair.Key)
+			}
+		}
+	}
+	return ret
+}
+
+func (d *RowDiffer) key(row types.Row) string {
+	parts := []string{}
+	for _, key := range d.Keys {
+		v, _ := row.Get(key)
+		parts = append(parts, fmt.Sprintf("%v", normalize(v)))
+	}
+	return strings.Join(parts, "\x00")
+}
+
+func (d *RowDiffer) indexRows(rows []types.Row) (map[string]types.Row, error) {
+	ret := map[string]types.Row{}
+	for _, row := range rows {
+		for _, key := range d.Keys {
+			if _, ok := row.Get(key); !ok {
+				return nil, errors.Errorf("row is missing key column %s", key)
+			}
+		}
+		k := d.key(row)
+		if _, ok := ret[k]; ok {
+			return nil, errors.Errorf("duplicate key %s", strings.ReplaceAll(k, "\x00", ", "))
+		}
+		ret[k] = row
+	}
+	return ret, nil
+}
+
+// Equal compares two values after normalizing them, so that for example the same number
+// decoded as an int from YAML and as a float64 from JSON is considered equal.
+func Equal(a interface{}, b interface{}) bool {
+	return reflect.DeepEqual(normalize(a), normalize(b))
+}
+
+func normalize(v interface{}) interface{} {
+	switch v_ := v.(type) {
+	case nil:
+		return nil
+	case types.Row:
+		ret := map[string]interface{}{}
+		for pair := v_.Oldest(); pair != nil; pair = pair.Next() {
+			ret[pair.Key] = normalize(pair.Value)
+		}
+		return ret
+	case map[string]interface{}:
+		ret := map[string]interface{}{}
+		for k, elm := range v_ {
+			ret[k] = normalize(elm)
+		}
+		return ret
+	case string:
+		return v_
+	}
+
+	if f, ok := cast.CastNumberInterfaceToFloat[float64](v); ok {
+		return f
+	}
+	if l, err := cast.CastListToInterfaceList(v); err == nil {
+		ret := make([]interface{}, len(l))
+		for i, elm := range l {
+			ret[i] = normalize(elm)
+		}
+		return ret
+	}
+
+	return v
+}
@@ -0,0 +1,81 @@
+package diff
+
+import (
+	assert2 "github.com/go-go-golems/glazed/pkg/helpers/assert"
+	"github.com/go-go-golems/glazed/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestDiffRows(t *testing.T) {
+	oldRows := []types.Row{
+		types.NewRow(types.MRP("id", 1), types.MRP("name", "foo"), types.MRP("port", 80)),
+		types.NewRow(types.MRP("id", 2), types.MRP("name", "bar"), types.MRP("port", 443)),
+		types.NewRow(types.MRP("id", 3), types.MRP("name", "baz"), types.MRP("port", 22)),
+	}
+	newRows := []types.Row{
+		types.NewRow(types.MRP("id", 1.0), types.MRP("name", "foo"), types.MRP("port", 80.0)),
+		types.NewRow(types.MRP("id", 3), types.MRP("name", "baz"), types.MRP("port", 2222)),
+		types.NewRow(types.MRP("id", 4), types.MRP("name", "qux"), types.MRP("port", 8080)),
+	}
+
+	rows, err := NewRowDiffer([]types.FieldName{"id"}).Diff(oldRows, newRows)
+	require.NoError(t, err)
+	require.Len(t, rows, 3)
+
+	assert.Equal(t,
+		[]types.FieldName{"_change", "id", "name.old", "name.new", "port.old", "port.new"},
+		types.GetFields(rows[0]))
+
+	assert2.EqualRowValues(t, rows[0], map[types.FieldName]types.GenericCellValue{
+		"_change": ChangeRemoved, "id": 2,
+		"name.old": "bar", "name.new": nil, "port.old": 443, "port.new": nil,
+	})
+	assert2.EqualRowValues(t, rows[1], map[types.FieldName]types.GenericCellValue{
+		"_change": ChangeChanged, "id": 3,
+		"name.old": "baz", "name.new": "baz", "port.old": 22, "port.new": 2222,
+	})
+	assert2.EqualRowValues(t, rows[2], map[types.FieldName]types.GenericCellValue{
+		"_change": ChangeAdded, "id": 4,
+		"name.old": nil, "name.new": "qux", "port.old": nil, "port.new": 8080,
+	})
+
+	rows, err = NewRowDiffer(
+		[]types.FieldName{"id"},
+		WithIncludeUnchanged(true),
+		WithIgnoreFields([]types.FieldName{"port"}),
+	).Diff(oldRows, newRows)
+	require.NoError(t, err)
+	changes := []string{}
+	for _, row := range rows {
+		v, _ := row.Get(ChangeField)
+		changes = append(changes, v.(string))
+	}
+	assert.Equal(t, []string{ChangeUnchanged, ChangeRemoved, ChangeUnchanged, ChangeAdded}, changes)
+	assert.Equal(t, []types.FieldName{"_change", "id", "name.old", "name.new"}, types.GetFields(rows[0]))
+}
+
+func TestDiffRowsErrors(t *testing.T) {
+	rows := []types.Row{
+		types.NewRow(types.MRP("id", 1)),
+		types.NewRow(types.MRP("id", 1)),
+	}
+
+	_, err := NewRowDiffer([]types.FieldName{"id"}).Diff(rows, nil)
+	assert.Error(t, err)
+
+	_, err = NewRowDiffer([]types.FieldName{"name"}).Diff(rows[:1], nil)
+	assert.Error(t, err)
+
+	_, err = NewRowDiffer(nil).Diff(rows[:1], nil)
+	assert.Error(t, err)
+}
+
+func TestEqualNestedValues(t *testing.T) {
+	assert.True(t, Equal(
+		types.NewRow(types.MRP("a", []interface{}{1, "x"})),
+		map[string]interface{}{"a": []interface{}{1.0, "x"}},
+	))
+	assert.False(t, Equal([]int{1, 2}, []int{2, 1}))
+}
@@ -0,0 +1,284 @@
+package humanize
+
+import (
+	"fmt"
+	"github.com/go-go-golems/glazed/pkg/helpers/cast"
+	"github.com/pkg/errors"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Formatter renders numbers, byte sizes, durations and timestamps in a human-friendly way.
+//
+// Numbers are rendered with the thousands and decimal separators of the formatter's locale.
+// All the methods return false if the value can't be interpreted as the requested kind of value,
+// in which case callers usually keep the original value.
+type Formatter struct {
+	printer *message.Printer
+	// Now is used to compute relative times, and defaults to time.Now.
+	Now func() time.Time
+}
+
+func NewFormatter(locale string) (*Formatter, error) {
+	tag := language.English
+	if locale != "" {
+		var err error
+		tag, err = language.Parse(locale)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid locale %s", locale)
+		}
+	}
+
+	return &Formatter{
+		printer: message.NewPrinter(tag),
+		Now:     time.Now,
+	}, nil
+}
+
+// Number renders v with thousands separators and the given number of decimals.
+// If decimals is negative, integers are rendered without decimals and floats
+// with as many decimals as needed.
+func (f *Formatter) Number(v interface{}, decimals int) (string, bool) {
+	if i, ok := cast.CastNumberInterfaceToInt[int64](v); ok && decimals < 0 {
+		return f.printer.Sprintf("%d", i), true
+	}
+
+	n, ok := toFloat(v)
+	if !ok {
+		return "", false
+	}
+	if decimals < 0 {
+		decimals = countDecimals(n)
+	}
+	return f.printer.Sprintf("%.*f", decimals, n), true
+}
+
+// Percent renders v, a ratio where 1 is 100%, as a percentage with the given number of decimals.
+func (f *Formatter) Percent(v interface{}, decimals int) (string, bool) {
+	n, ok := toFloat(v)
+	if !ok {
+		return "", false
+	}
+	if decimals < 0 {
+		decimals = 0
+	}
+	return f.printer.Sprintf("%.*f%%", decimals, n*100), true
+}
+
+var (
+	iecUnits = []string{"B", "KiB", "MiB", "GiB", "TiB", "PiB", "EiB"}
+	siUnits  = []string{"B", "kB", "MB", "GB", "TB", "PB", "EB"}
+)
+
+// Bytes renders v, a number of bytes, using IEC (1 KiB = 1024 B) or SI (1 kB = 1000 B) units.
+func (f *Formatter) Bytes(v interface{}, iec bool) (string, bool) {
+	n, ok := toFloat(v)
+	if !ok {
+		return "", false
+	}
+
+	base, units := 1000.0, siUnits
+	if iec {
+		base, units = 1024.0, iecUnits
+	}
+
+	i := 0
+	for math.Abs(n) >= base && i < len(units)-1 {
+		n /= base
+		i++
+	}
+
+	if i == 0 {
+		return f.printer.Sprintf("%.0f %s", n, units[i]), true
+	}
+	s := f.printer.Sprintf("%.1f", n)
+	// drop a trailing zero decimal, whatever the decimal separator of the locale
+	if strings.HasSuffix(s, "0") {
+		s = s[:len(s)-2]
+	}
+	return s + " " + units[i], true
+}
+
+// Duration renders v as a duration. Numbers are interpreted in the given unit,
+// strings are parsed with time.ParseDuration.
+//
+// Durations of a minute and more are rendered using their two largest units (for example "3h 12m"),
+// shorter durations are rounded to the millisecond.
+func (f *Formatter) Duration(v interface{}, unit time.Duration) (string, bool) {
+	d, ok := toDuration(v, unit)
+	if !ok {
+		return "", false
+	}
+
+	sign := ""
+	if d < 0 {
+		sign = "-"
+		d = -d
+	}
+
+	if d < time.Minute {
+		if d >= time.Millisecond {
+			d = d.Round(time.Millisecond)
+		}
+		return sign + d.String(), true
+	}
+
+	units := []struct {
+		d    time.Duration
+		name string
+	}{
+		{24 * time.Hour, "d"},
+		{time.Hour, "h"},
+		{time.Minute, "m"},
+		{time.Second, "s"},
+	}
+
+	parts := []string{}
+	for i, u := range units {
+		if d < u.d {
+			continue
+		}
+		parts = append(parts, fmt.Sprintf("%d%s", d/u.d, u.name))
+		if i+1 < len(units) {
+			next := units[i+1]
+			if n := (d % u.d) / next.d; n > 0 {
+				parts = append(parts, fmt.Sprintf("%d%s", n, next.name))
+			}
+		}
+		break
+	}
+	return sign + strings.Join(parts, " "), true
+}
+
+// Date renders v using the given time layout (RFC3339 if empty).
+// Numbers are interpreted as unix timestamps in seconds.
+func (f *Formatter) Date(v interface{}, layout string) (string, bool) {
+	t, ok := toTime(v)
+	if !ok {
+		return "", false
+	}
+	if layout == "" {
+		layout = time.RFC3339
+	}
+	return t.Format(layout), true
+}
+
+// RelativeTime renders v relative to now, for example "3h ago" or "in 2d".
+func (f *Formatter) RelativeTime(v interface{}) (string, bool) {
+	t, ok := toTime(v)
+	if !ok {
+		return "", false
+	}
+
+	d := f.Now().Sub(t)
+	future := d < 0
+	if future {
+		d = -d
+	}
+
+	var s string
+	switch {
+	case d < time.Minute:
+		return "just now", true
+	case d < time.Hour:
+		s = fmt.Sprintf("%dm", d/time.Minute)
+	case d < 24*time.Hour:
+		s = fmt.Sprintf("%dh", d/time.Hour)
+	case d < 30*24*time.Hour:
+		s = fmt.Sprintf("%dd", d/(24*time.Hour))
+	case d < 365*24*time.Hour:
+		s = fmt.Sprintf("%dmo", d/(30*24*time.Hour))
+	default:
+		s = fmt.Sprintf("%dy", d/(365*24*time.Hour))
+	}
+
+	if future {
+		return "in " + s, true
+	}
+	return s + " ago", true
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	if f, ok := cast.CastNumberInterfaceToFloat[float64](v); ok {
+		return f, true
+	}
+	switch v_ := v.(type) {
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(v_), 64)
+		return f, err == nil
+	case fmt.Stringer:
+		// for example json.Number
+		f, err := strconv.ParseFloat(v_.String(), 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// countDecimals returns the number of decimals needed to represent f, capped to avoid
+// rendering floating point noise.
+func countDecimals(f float64) int {
+	s := strconv.FormatFloat(f, 'f', -1, 64)
+	i := strings.IndexByte(s, '.')
+	if i < 0 {
+		return 0
+	}
+	n := len(s) - i - 1
+	if n > 6 {
+		n = 6
+	}
+	return n
+}
+
+func toDuration(v interface{}, unit time.Duration) (time.Duration, bool) {
+	switch v_ := v.(type) {
+	case time.Duration:
+		return v_, true
+	case string:
+		if d, err := time.ParseDuration(strings.TrimSpace(v_)); err == nil {
+			return d, true
+		}
+	}
+
+	n, ok := toFloat(v)
+	if !ok {
+		return 0, false
+	}
+	return time.Duration(n * float64(unit)), true
+}
+
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+func toTime(v interface{}) (time.Time, bool) {
+	switch v_ := v.(type) {
+	case time.Time:
+		return v_, true
+	case *time.Time:
+		if v_ == nil {
+			return time.Time{}, false
+		}
+		return *v_, true
+	case string:
+		for _, layout := range timeLayouts {
+			if t, err := time.Parse(layout, strings.TrimSpace(v_)); err == nil {
+				return t, true
+			}
+		}
+		return time.Time{}, false
+	}
+
+	n, ok := toFloat(v)
+	if !ok {
+		return time.Time{}, false
+	}
+	sec, frac := math.Modf(n)
+	return time.Unix(int64(sec), int64(frac*1e9)), true
+}
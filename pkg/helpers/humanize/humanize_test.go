@@ -0,0 +1,108 @@
+package humanize
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestBytes(t *testing.T) {
+	f, err := NewFormatter("en")
+	require.NoError(t, err)
+
+	tests := []struct {
+		v        interface{}
+		iec      bool
+		expected string
+	}{
+		{512, true, "512 B"},
+		{1024, true, "1 KiB"},
+		{1536, true, "1.5 KiB"},
+		{1536, false, "1.5 kB"},
+		{int64(5) * 1024 * 1024 * 1024, true, "5 GiB"},
+		{"2000000", false, "2 MB"},
+	}
+	for _, tt := range tests {
+		s, ok := f.Bytes(tt.v, tt.iec)
+		assert.True(t, ok)
+		assert.Equal(t, tt.expected, s)
+	}
+
+	_, ok := f.Bytes("foo", true)
+	assert.False(t, ok)
+}
+
+func TestDuration(t *testing.T) {
+	f, err := NewFormatter("en")
+	require.NoError(t, err)
+
+	tests := []struct {
+		v        interface{}
+		unit     time.Duration
+		expected string
+	}{
+		{0.2534, time.Second, "253ms"},
+		{42, time.Second, "42s"},
+		{3725, time.Second, "1h 2m"},
+		{3605, time.Second, "1h"},
+		{90061, time.Second, "1d 1h"},
+		{1500, time.Millisecond, "1.5s"},
+		{"2h45m", time.Second, "2h 45m"},
+		{-90, time.Second, "-1m 30s"},
+	}
+	for _, tt := range tests {
+		s, ok := f.Duration(tt.v, tt.unit)
+		assert.True(t, ok)
+		assert.Equal(t, tt.expected, s)
+	}
+}
+
+func TestNumberAndPercent(t *testing.T) {
+	f, err := NewFormatter("en")
+	require.NoError(t, err)
+
+	s, _ := f.Number(1234567, -1)
+	assert.Equal(t, "1,234,567", s)
+	s, _ = f.Number(1234.5, -1)
+	assert.Equal(t, "1,234.5", s)
+	s, _ = f.Number(1234.5, 2)
+	assert.Equal(t, "1,234.50", s)
+	s, _ = f.Percent(0.1234, 1)
+	assert.Equal(t, "12.3%", s)
+
+	de, err := NewFormatter("de")
+	require.NoError(t, err)
+	s, _ = de.Number(1234.5, 2)
+	assert.Equal(t, "1.234,50", s)
+	s, _ = de.Bytes(1536, true)
+	assert.Equal(t, "1,5 KiB", s)
+
+	_, err = NewFormatter("not a locale!")
+	assert.Error(t, err)
+}
+
+func TestRelativeTime(t *testing.T) {
+	f, err := NewFormatter("en")
+	require.NoError(t, err)
+	now := time.Date(2023, 6, 2, 12, 0, 0, 0, time.UTC)
+	f.Now = func() time.Time { return now }
+
+	tests := []struct {
+		v        interface{}
+		expected string
+	}{
+		{now.Add(-10 * time.Second), "just now"},
+		{now.Add(-5 * time.Minute), "5m ago"},
+		{"2023-06-02T09:00:00Z", "3h ago"},
+		{"2023-05-01", "1mo ago"},
+		{"2023-05-20", "13d ago"},
+		{now.Add(48 * time.Hour), "in 2d"},
+		{now.Add(-400 * 24 * time.Hour).Unix(), "1y ago"},
+	}
+	for _, tt := range tests {
+		s, ok := f.RelativeTime(tt.v)
+		assert.True(t, ok)
+		assert.Equal(t, tt.expected, s)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"github.com/go-go-golems/glazed/pkg/helpers/cast"
 	"reflect"
 	"strconv"
+	"time"
 )
 
 func SetReflectValue(value reflect.Value, v interface{}) error {
@@ -20,6 +21,23 @@ func SetReflectValue(value reflect.Value, v interface{}) error {
 		return nil
 	}
 
+	// values of the exact type, such as time.Duration, time.Time or *url.URL, are assigned as is
+	if reflect.TypeOf(v).AssignableTo(value.Type()) {
+		value.Set(reflect.ValueOf(v))
+		return nil
+	}
+
+	if value.Type() == reflect.TypeOf(time.Duration(0)) {
+		if s, ok := v.(string); ok {
+			d, err := time.ParseDuration(s)
+			if err != nil {
+				return err
+			}
+			value.SetInt(int64(d))
+			return nil
+		}
+	}
+
 	//exhaustive:ignore
 	switch value.Kind() {
 	case reflect.String:
@@ -27,6 +45,11 @@ func SetReflectValue(value reflect.Value, v interface{}) error {
 			value.SetString(s)
 			return nil
 		}
+		// named string types, for example secrets that mask themselves when printed
+		if rv := reflect.ValueOf(v); rv.Kind() == reflect.String {
+			value.SetString(rv.String())
+			return nil
+		}
 		return fmt.Errorf("cannot set reflect.Value of type %s from %T", value.Kind(), v)
 
 	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
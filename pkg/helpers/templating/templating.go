@@ -6,6 +6,7 @@ import (
 	"github.com/Masterminds/sprig"
 	"github.com/bmatcuk/doublestar/v4"
 	"github.com/go-go-golems/glazed/pkg/helpers/cast"
+	"github.com/go-go-golems/glazed/pkg/helpers/humanize"
 	"github.com/go-go-golems/glazed/pkg/helpers/list"
 	"github.com/pkg/errors"
 	"gopkg.in/yaml.v3"
@@ -75,6 +76,12 @@ var TemplateFuncs = template.FuncMap{
 
 	"currency": currency,
 
+	"formatNumber":   formatNumber,
+	"formatPercent":  formatPercent,
+	"formatBytes":    formatBytes,
+	"formatDuration": formatDuration,
+	"timeAgo":        timeAgo,
+
 	"padLeft":   padLeft,
 	"padRight":  padRight,
 	"padCenter": padCenter,
@@ -520,6 +527,43 @@ func currency(i interface{}) string {
 	}
 }
 
+// defaultHumanizer is used by the format* template helpers, which render values
+// the same way as the --format-column flag with the default locale.
+var defaultHumanizer, _ = humanize.NewFormatter("en")
+
+// humanized returns the formatted value, or the value as is if it couldn't be formatted.
+func humanized(s string, ok bool, v interface{}) string {
+	if !ok {
+		return fmt.Sprintf("%v", v)
+	}
+	return s
+}
+
+func formatNumber(decimals int, v interface{}) string {
+	s, ok := defaultHumanizer.Number(v, decimals)
+	return humanized(s, ok, v)
+}
+
+func formatPercent(decimals int, v interface{}) string {
+	s, ok := defaultHumanizer.Percent(v, decimals)
+	return humanized(s, ok, v)
+}
+
+func formatBytes(v interface{}) string {
+	s, ok := defaultHumanizer.Bytes(v, true)
+	return humanized(s, ok, v)
+}
+
+func formatDuration(v interface{}) string {
+	s, ok := defaultHumanizer.Duration(v, time.Second)
+	return humanized(s, ok, v)
+}
+
+func timeAgo(v interface{}) string {
+	s, ok := defaultHumanizer.RelativeTime(v)
+	return humanized(s, ok, v)
+}
+
 type TemplateExecute interface {
 	Execute(wr io.Writer, data any) error
 }
@@ -579,7 +623,7 @@ func CreateHTMLTemplate(name string) *html.Template {
 func ParseFS(t *template.Template, f fs.FS, patterns ...string) error {
 	listMap := make(map[string]struct{})
 	for _, p := range patterns {
-		list, err := doublestar.FilepathGlob(p, doublestar.WithFilesOnly())
+		list, err := doublestar.Glob(f, p, doublestar.WithFilesOnly())
 		if err != nil {
 			return err
 		}
@@ -0,0 +1,131 @@
+// Package testcommands provides the commands used to test the packages that run commands
+// without going through cobra, such as runner, server and tools.
+package testcommands
+
+import (
+	"context"
+	"fmt"
+	"github.com/go-go-golems/glazed/pkg/cmds"
+	"github.com/go-go-golems/glazed/pkg/cmds/layers"
+	"github.com/go-go-golems/glazed/pkg/cmds/parameters"
+	"github.com/go-go-golems/glazed/pkg/middlewares"
+	"github.com/go-go-golems/glazed/pkg/settings"
+	"github.com/go-go-golems/glazed/pkg/types"
+	"github.com/pkg/errors"
+	"io"
+)
+
+// ListCommand is the glaze command items list. It outputs count rows with an id and
+// a name made of the prefix argument. It returns an error before outputting any row if
+// the fail flag is set, and after outputting them if the fail-after-rows flag is set.
+// It has the glazed layers and a config flag that loads an object from a file.
+type ListCommand struct {
+	*cmds.CommandDescription
+	// Emitted records the number of rows output so far.
+	Emitted int
+}
+
+var _ cmds.GlazeCommand = (*ListCommand)(nil)
+
+func NewListCommand() (*ListCommand, error) {
+	glazedLayer, err := settings.NewGlazedParameterLayers()
+	if err != nil {
+		return nil, err
+	}
+
+	return &ListCommand{
+		CommandDescription: cmds.NewCommandDescription("list",
+			cmds.WithShort("List items"),
+			cmds.WithParents("items"),
+			cmds.WithFlags(
+				parameters.NewParameterDefinition("count", parameters.ParameterTypeInteger, parameters.WithDefault(2)),
+				parameters.NewParameterDefinition("fail", parameters.ParameterTypeBool, parameters.WithDefault(false)),
+				parameters.NewParameterDefinition("fail-after-rows", parameters.ParameterTypeBool, parameters.WithDefault(false)),
+				parameters.NewParameterDefinition("config", parameters.ParameterTypeObjectFromFile),
+			),
+			cmds.WithArguments(
+				parameters.NewParameterDefinition("prefix", parameters.ParameterTypeString, parameters.WithDefault("item")),
+			),
+			cmds.WithLayers(glazedLayer),
+		),
+	}, nil
+}
+
+func (c *ListCommand) Run(
+	ctx context.Context,
+	parsedLayers map[string]*layers.ParsedParameterLayer,
+	ps map[string]interface{},
+	gp middlewares.Processor,
+) error {
+	if ps["fail"].(bool) {
+		return errors.New("failed")
+	}
+	for i := 0; i < ps["count"].(int); i++ {
+		c.Emitted++
+		err := gp.AddRow(ctx, types.NewRow(
+			types.MRP("id", i),
+			types.MRP("name", fmt.Sprintf("%s-%d", ps["prefix"], i)),
+		))
+		if err != nil {
+			return err
+		}
+	}
+	if ps["fail-after-rows"].(bool) {
+		return errors.New("failed")
+	}
+	return nil
+}
+
+// EchoCommand is the writer command echo. It writes its required message flag,
+// followed by its token secret flag if it is set.
+type EchoCommand struct {
+	*cmds.CommandDescription
+}
+
+var _ cmds.WriterCommand = (*EchoCommand)(nil)
+
+func NewEchoCommand() *EchoCommand {
+	return &EchoCommand{
+		CommandDescription: cmds.NewCommandDescription("echo",
+			cmds.WithFlags(
+				parameters.NewParameterDefinition("message", parameters.ParameterTypeString, parameters.WithRequired(true)),
+				parameters.NewParameterDefinition("token", parameters.ParameterTypeSecret, parameters.WithDefault("")),
+			),
+		),
+	}
+}
+
+func (c *EchoCommand) RunIntoWriter(
+	ctx context.Context,
+	parsedLayers map[string]*layers.ParsedParameterLayer,
+	ps map[string]interface{},
+	w io.Writer,
+) error {
+	_, err := fmt.Fprintf(w, "%s", ps["message"])
+	if err != nil {
+		return err
+	}
+	if token := ps["token"].(parameters.Secret).Reveal(); token != "" {
+		_, err = fmt.Fprintf(w, " %s", token)
+	}
+	return err
+}
+
+// ExitCommand is the bare command exit, which stops with cmds.ExitWithoutGlazeError.
+type ExitCommand struct {
+	*cmds.CommandDescription
+}
+
+var _ cmds.BareCommand = (*ExitCommand)(nil)
+
+func NewExitCommand() *ExitCommand {
+	return &ExitCommand{CommandDescription: cmds.NewCommandDescription("exit")}
+}
+
+func (c *ExitCommand) Run(
+	ctx context.Context,
+	parsedLayers map[string]*layers.ParsedParameterLayer,
+	ps map[string]interface{},
+) error {
+	return &cmds.ExitWithoutGlazeError{}
+}
@@ -0,0 +1,48 @@
+package row
+
+import (
+	"context"
+	"github.com/go-go-golems/glazed/pkg/middlewares"
+	"github.com/go-go-golems/glazed/pkg/types"
+)
+
+// FanOutMiddleware sends a copy of each row it receives to each of its processors,
+// which allows rendering the same rows with different middlewares and output formatters.
+//
+// The processors are closed when the middleware is closed.
+type FanOutMiddleware struct {
+	processors []middlewares.Processor
+}
+
+var _ middlewares.RowMiddleware = (*FanOutMiddleware)(nil)
+
+func NewFanOutMiddleware(processors ...middlewares.Processor) *FanOutMiddleware {
+	return &FanOutMiddleware{
+		processors: processors,
+	}
+}
+
+func (f *FanOutMiddleware) Process(ctx context.Context, row types.Row) ([]types.Row, error) {
+	for _, p := range f.processors {
+		// copy the row so that the middlewares of one processor don't modify the rows of another
+		err := p.AddRow(ctx, types.NewRowFromRow(row))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return []types.Row{row}, nil
+}
+
+func (f *FanOutMiddleware) Close(ctx context.Context) error {
+	var firstErr error
+	// close all processors, even if one of them fails, so that all outputs are flushed
+	for _, p := range f.processors {
+		err := p.Close(ctx)
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return firstErr
+}
@@ -0,0 +1,208 @@
+package row
+
+import (
+	"context"
+	"github.com/go-go-golems/glazed/pkg/helpers/humanize"
+	"github.com/go-go-golems/glazed/pkg/middlewares"
+	"github.com/go-go-golems/glazed/pkg/types"
+	"github.com/pkg/errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ColumnFormat describes how to render the values of a single column.
+//
+// It is given as field:type[:argument], for example:
+//
+//   - amount:number:2 renders numbers with thousands separators and 2 decimals
+//   - ratio:percent:1 renders 0.253 as 25.3%
+//   - bytes:size renders byte counts with IEC units (size:si for SI units)
+//   - latency:duration:ms renders durations, numbers being interpreted in the given unit (default s)
+//   - ts:date:2006-01-02 renders timestamps using a Go time layout (default RFC3339)
+//   - ts:relative renders timestamps relative to now, for example "3h ago"
+type ColumnFormat struct {
+	Field    types.FieldName
+	Type     string
+	Argument string
+}
+
+// numericColumnFormats are the formats whose output should be right-aligned.
+var numericColumnFormats = map[string]bool{
+	"number":   true,
+	"percent":  true,
+	"size":     true,
+	"duration": true,
+}
+
+var durationUnits = map[string]time.Duration{
+	"ns": time.Nanosecond,
+	"us": time.Microsecond,
+	"ms": time.Millisecond,
+	"s":  time.Second,
+	"m":  time.Minute,
+	"h":  time.Hour,
+}
+
+func ParseColumnFormat(s string) (*ColumnFormat, error) {
+	// date layouts can contain colons, so only split off the field and the type
+	parts := strings.SplitN(s, ":", 3)
+	if len(parts) < 2 || parts[0] == "" {
+		return nil, errors.Errorf("invalid column format %s, expected field:type[:argument]", s)
+	}
+
+	ret := &ColumnFormat{
+		Field: parts[0],
+		Type:  parts[1],
+	}
+	if len(parts) == 3 {
+		ret.Argument = parts[2]
+	}
+
+	return ret, nil
+}
+
+// IsNumeric returns true if the formatted values are numbers and should be right-aligned.
+func (c *ColumnFormat) IsNumeric() bool {
+	return numericColumnFormats[c.Type]
+}
+
+type valueFormatter func(v interface{}) (string, bool)
+
+func (c *ColumnFormat) compile(h *humanize.Formatter) (valueFormatter, error) {
+	parseDecimals := func(default_ int) (int, error) {
+		if c.Argument == "" {
+			return default_, nil
+		}
+		decimals, err := strconv.Atoi(c.Argument)
+		if err != nil || decimals < 0 {
+			return 0, errors.Errorf("invalid number of decimals %s for column %s", c.Argument, c.Field)
+		}
+		return decimals, nil
+	}
+
+	switch c.Type {
+	case "number":
+		decimals, err := parseDecimals(-1)
+		if err != nil {
+			return nil, err
+		}
+		return func(v interface{}) (string, bool) {
+			return h.Number(v, decimals)
+		}, nil
+
+	case "percent":
+		decimals, err := parseDecimals(0)
+		if err != nil {
+			return nil, err
+		}
+		return func(v interface{}) (string, bool) {
+			return h.Percent(v, decimals)
+		}, nil
+
+	case "size":
+		var iec bool
+		switch c.Argument {
+		case "", "iec":
+			iec = true
+		case "si":
+			iec = false
+		default:
+			return nil, errors.Errorf("invalid size units %s for column %s, expected iec or si", c.Argument, c.Field)
+		}
+		return func(v interface{}) (string, bool) {
+			return h.Bytes(v, iec)
+		}, nil
+
+	case "duration":
+		unit := time.Second
+		if c.Argument != "" {
+			var ok bool
+			unit, ok = durationUnits[c.Argument]
+			if !ok {
+				return nil, errors.Errorf("invalid duration unit %s for column %s", c.Argument, c.Field)
+			}
+		}
+		return func(v interface{}) (string, bool) {
+			return h.Duration(v, unit)
+		}, nil
+
+	case "date":
+		return func(v interface{}) (string, bool) {
+			return h.Date(v, c.Argument)
+		}, nil
+
+	case "relative":
+		return h.RelativeTime, nil
+
+	default:
+		return nil, errors.Errorf("unknown column format %s for column %s", c.Type, c.Field)
+	}
+}
+
+// FormatColumnsMiddleware replaces the values of the given columns with their human-friendly
+// rendering. Values that can't be formatted (for example, a string in a number column)
+// are left untouched.
+//
+// Since values are converted to strings, this should only be used in front of text-based output formats.
+type FormatColumnsMiddleware struct {
+	Formats    []*ColumnFormat
+	formatters map[types.FieldName]valueFormatter
+}
+
+var _ middlewares.RowMiddleware = (*FormatColumnsMiddleware)(nil)
+
+// NewFormatColumnsMiddleware creates a FormatColumnsMiddleware rendering numbers according to locale
+// (for example "en" or "de-CH"). If now is nil, relative times are computed using time.Now.
+func NewFormatColumnsMiddleware(formats []*ColumnFormat, locale string, now func() time.Time) (*FormatColumnsMiddleware, error) {
+	h, err := humanize.NewFormatter(locale)
+	if err != nil {
+		return nil, err
+	}
+	if now != nil {
+		h.Now = now
+	}
+
+	ret := &FormatColumnsMiddleware{
+		Formats:    formats,
+		formatters: map[types.FieldName]valueFormatter{},
+	}
+	for _, format := range formats {
+		f, err := format.compile(h)
+		if err != nil {
+			return nil, err
+		}
+		ret.formatters[format.Field] = f
+	}
+
+	return ret, nil
+}
+
+// NumericFields returns the fields whose formatted values are numbers.
+func (fcm *FormatColumnsMiddleware) NumericFields() []types.FieldName {
+	ret := []types.FieldName{}
+	for _, format := range fcm.Formats {
+		if format.IsNumeric() {
+			ret = append(ret, format.Field)
+		}
+	}
+	return ret
+}
+
+func (fcm *FormatColumnsMiddleware) Close(ctx context.Context) error {
+	return nil
+}
+
+func (fcm *FormatColumnsMiddleware) Process(ctx context.Context, row types.Row) ([]types.Row, error) {
+	for field, f := range fcm.formatters {
+		v, ok := row.Get(field)
+		if !ok || v == nil {
+			continue
+		}
+		if s, ok := f(v); ok {
+			row.Set(field, s)
+		}
+	}
+
+	return []types.Row{row}, nil
+}
@@ -0,0 +1,100 @@
+package row
+
+import (
+	assert2 "github.com/go-go-golems/glazed/pkg/helpers/assert"
+	"github.com/go-go-golems/glazed/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func parseColumnFormats(t *testing.T, specs ...string) []*ColumnFormat {
+	ret := []*ColumnFormat{}
+	for _, spec := range specs {
+		f, err := ParseColumnFormat(spec)
+		require.NoError(t, err)
+		ret = append(ret, f)
+	}
+	return ret
+}
+
+func TestParseColumnFormat(t *testing.T) {
+	f, err := ParseColumnFormat("ts:date:15:04:05")
+	require.NoError(t, err)
+	assert.Equal(t, "ts", f.Field)
+	assert.Equal(t, "date", f.Type)
+	assert.Equal(t, "15:04:05", f.Argument)
+
+	_, err = ParseColumnFormat("ts")
+	assert.Error(t, err)
+}
+
+func TestFormatColumns(t *testing.T) {
+	now := time.Date(2023, 6, 2, 12, 0, 0, 0, time.UTC)
+	mw, err := NewFormatColumnsMiddleware(
+		parseColumnFormats(t,
+			"bytes:size",
+			"latency:duration:ms",
+			"amount:number:2",
+			"ratio:percent:1",
+			"ts:date:2006-01-02",
+			"seen:relative",
+		),
+		"en",
+		func() time.Time { return now },
+	)
+	require.NoError(t, err)
+
+	rows, err := processRows(mw, []types.Row{
+		types.NewRow(
+			types.MRP("name", "foo"),
+			types.MRP("bytes", 1536),
+			types.MRP("latency", 3725000),
+			types.MRP("amount", 1234567.891),
+			types.MRP("ratio", 0.253),
+			types.MRP("ts", "2023-06-01T10:00:00Z"),
+			types.MRP("seen", now.Add(-3*time.Hour)),
+		),
+		types.NewRow(
+			types.MRP("name", "bar"),
+			types.MRP("bytes", "n/a"),
+			types.MRP("latency", nil),
+		),
+	})
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+
+	row := rows[0]
+	assert2.EqualRowValue(t, "foo", row, "name")
+	assert2.EqualRowValue(t, "1.5 KiB", row, "bytes")
+	assert2.EqualRowValue(t, "1h 2m", row, "latency")
+	assert2.EqualRowValue(t, "1,234,567.89", row, "amount")
+	assert2.EqualRowValue(t, "25.3%", row, "ratio")
+	assert2.EqualRowValue(t, "2023-06-01", row, "ts")
+	assert2.EqualRowValue(t, "3h ago", row, "seen")
+	assert.Equal(t, []types.FieldName{"name", "bytes", "latency", "amount", "ratio", "ts", "seen"}, types.GetFields(row))
+
+	// values that can't be formatted are kept as is
+	assert2.EqualRowValue(t, "n/a", rows[1], "bytes")
+	assert2.EqualRowValue(t, nil, rows[1], "latency")
+
+	assert.Equal(t, []types.FieldName{"bytes", "latency", "amount", "ratio"}, mw.NumericFields())
+}
+
+func TestFormatColumnsLocale(t *testing.T) {
+	mw, err := NewFormatColumnsMiddleware(parseColumnFormats(t, "amount:number"), "de", nil)
+	require.NoError(t, err)
+
+	rows, err := processRows(mw, []types.Row{types.NewRow(types.MRP("amount", 1234567.5))})
+	require.NoError(t, err)
+	assert2.EqualRowValue(t, "1.234.567,5", rows[0], "amount")
+}
+
+func TestFormatColumnsInvalidFormat(t *testing.T) {
+	_, err := NewFormatColumnsMiddleware(parseColumnFormats(t, "amount:bogus"), "en", nil)
+	assert.Error(t, err)
+
+	_, err = NewFormatColumnsMiddleware(parseColumnFormats(t, "bytes:size:foo"), "en", nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,49 @@
+package table
+
+import (
+	"context"
+	"github.com/go-go-golems/glazed/pkg/middlewares"
+	"github.com/go-go-golems/glazed/pkg/types"
+)
+
+// FanOutMiddleware sends a copy of each row of the table to each of its processors.
+// It is the table equivalent of row.FanOutMiddleware, and is used when rows can only
+// be output once the table middlewares (for example, sorting) have been applied.
+//
+// The processors are closed when the middleware is closed.
+type FanOutMiddleware struct {
+	processors []middlewares.Processor
+}
+
+var _ middlewares.TableMiddleware = (*FanOutMiddleware)(nil)
+
+func NewFanOutMiddleware(processors ...middlewares.Processor) *FanOutMiddleware {
+	return &FanOutMiddleware{
+		processors: processors,
+	}
+}
+
+func (f *FanOutMiddleware) Process(ctx context.Context, table *types.Table) (*types.Table, error) {
+	for _, p := range f.processors {
+		for _, row := range table.Rows {
+			err := p.AddRow(ctx, types.NewRowFromRow(row))
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return table, nil
+}
+
+func (f *FanOutMiddleware) Close(ctx context.Context) error {
+	var firstErr error
+	for _, p := range f.processors {
+		err := p.Close(ctx)
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return firstErr
+}
@@ -0,0 +1,39 @@
+package table
+
+import (
+	"context"
+	"github.com/go-go-golems/glazed/pkg/middlewares"
+	"github.com/go-go-golems/glazed/pkg/middlewares/row"
+	"github.com/go-go-golems/glazed/pkg/types"
+)
+
+// FormatColumnsMiddleware applies a row.FormatColumnsMiddleware to all the rows of the table.
+//
+// It is used instead of the row middleware when the table is output as a whole,
+// so that the other table middlewares (for example, sorting) still see the raw values.
+type FormatColumnsMiddleware struct {
+	rowMiddleware *row.FormatColumnsMiddleware
+}
+
+var _ middlewares.TableMiddleware = (*FormatColumnsMiddleware)(nil)
+
+func NewFormatColumnsMiddleware(rowMiddleware *row.FormatColumnsMiddleware) *FormatColumnsMiddleware {
+	return &FormatColumnsMiddleware{
+		rowMiddleware: rowMiddleware,
+	}
+}
+
+func (f *FormatColumnsMiddleware) Process(ctx context.Context, table *types.Table) (*types.Table, error) {
+	for _, row_ := range table.Rows {
+		_, err := f.rowMiddleware.Process(ctx, row_)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return table, nil
+}
+
+func (f *FormatColumnsMiddleware) Close(ctx context.Context) error {
+	return f.rowMiddleware.Close(ctx)
+}
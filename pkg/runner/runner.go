@@ -0,0 +1,422 @@
+package runner
+
+import (
+	"context"
+	"github.com/go-go-golems/glazed/pkg/cmds"
+	"github.com/go-go-golems/glazed/pkg/cmds/layers"
+	"github.com/go-go-golems/glazed/pkg/cmds/parameters"
+	"github.com/go-go-golems/glazed/pkg/formatters"
+	"github.com/go-go-golems/glazed/pkg/middlewares"
+	"github.com/go-go-golems/glazed/pkg/middlewares/row"
+	"github.com/go-go-golems/glazed/pkg/middlewares/table"
+	"github.com/go-go-golems/glazed/pkg/settings"
+	"github.com/go-go-golems/glazed/pkg/types"
+	"github.com/pkg/errors"
+	"io"
+	"os"
+	"reflect"
+	"sort"
+)
+
+// RowSink receives the rows output by a glaze command, see WithRowSink.
+type RowSink func(ctx context.Context, row types.Row) error
+
+// FormatterCallback is called with the output formatter of a glaze command, see WithFormatterCallback.
+type FormatterCallback func(of formatters.OutputFormatter)
+
+type runSettings struct {
+	values            map[string]interface{}
+	writer            io.Writer
+	rowSink           RowSink
+	formatterCallback FormatterCallback
+}
+
+type RunOption func(*runSettings)
+
+// WithValues sets the values of the parameters of the command, by parameter name, as in
+// the JSON object passed to --load-parameters-from-json. Parameters that are not given
+// are set to their default.
+func WithValues(values map[string]interface{}) RunOption {
+	return func(s *runSettings) {
+		for k, v := range values {
+			s.values[k] = v
+		}
+	}
+}
+
+// WithWriter sets the writer that writer commands write to, and that the rows of glaze
+// commands are formatted into, according to the output parameters of the command.
+func WithWriter(w io.Writer) RunOption {
+	return func(s *runSettings) {
+		s.writer = w
+	}
+}
+
+// WithRowSink streams the rows output by a glaze command to sink, once they have gone
+// through the middlewares set up by the glazed parameters, such as --fields or --filter.
+//
+// Rows are passed to sink as soon as they are output, unless table middlewares
+// such as --sort-by need all the rows, in which case they are passed once the command is done.
+func WithRowSink(sink RowSink) RunOption {
+	return func(s *runSettings) {
+		s.rowSink = sink
+	}
+}
+
+// WithFormatterCallback calls f with the output formatter set up for the rows of a glaze
+// command formatted into the writer given with WithWriter, before the command is run.
+// This is used to set the content type of an HTTP response, for example.
+func WithFormatterCallback(f FormatterCallback) RunOption {
+	return func(s *runSettings) {
+		s.formatterCallback = f
+	}
+}
+
+// ParameterError is returned when the parameters of a command are invalid, as opposed to
+// the errors returned by the command itself.
+type ParameterError struct {
+	Err error
+}
+
+func (e *ParameterError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ParameterError) Unwrap() error {
+	return e.Err
+}
+
+// Run parses the values given with WithValues and runs the command, without going
+// through cobra. Errors, including invalid or missing parameters, are returned.
+//
+// Bare commands are run as is, and writer commands write to the writer given with WithWriter,
+// or else to stdout. The rows of glaze commands are streamed to the row sink given with
+// WithRowSink, or else formatted into the writer given with WithWriter, or else returned.
+//
+// Invalid parameters are returned as a ParameterError.
+func Run(ctx context.Context, c cmds.Command, options ...RunOption) ([]types.Row, error) {
+	s := newRunSettings(options...)
+
+	parsedLayers, ps, err := ParseValues(c.Description(), s.values)
+	if err != nil {
+		return nil, &ParameterError{Err: err}
+	}
+
+	return runParsed(ctx, c, parsedLayers, ps, s)
+}
+
+// RunParsed runs the command with parameters that have already been parsed, for example
+// with ParseValues or ParseUntrustedValues, as described in Run. The values given with
+// WithValues are ignored.
+func RunParsed(
+	ctx context.Context,
+	c cmds.Command,
+	parsedLayers map[string]*layers.ParsedParameterLayer,
+	ps map[string]interface{},
+	options ...RunOption,
+) ([]types.Row, error) {
+	return runParsed(ctx, c, parsedLayers, ps, newRunSettings(options...))
+}
+
+func newRunSettings(options ...RunOption) *runSettings {
+	s := &runSettings{
+		values: map[string]interface{}{},
+	}
+	for _, o := range options {
+		o(s)
+	}
+	return s
+}
+
+func runParsed(
+	ctx context.Context,
+	c cmds.Command,
+	parsedLayers map[string]*layers.ParsedParameterLayer,
+	ps map[string]interface{},
+	s *runSettings,
+) ([]types.Row, error) {
+	rows, err := run(ctx, c, parsedLayers, ps, s)
+	if _, ok := err.(*cmds.ExitWithoutGlazeError); ok {
+		return nil, nil
+	}
+	return rows, err
+}
+
+// ParseValues parses the values of the parameters of the command described by description
+// into parsed layers, using the defaults of the parameters that are not given. Unknown
+// parameters and missing required parameters are errors.
+func ParseValues(
+	description *cmds.CommandDescription,
+	values map[string]interface{},
+) (map[string]*layers.ParsedParameterLayer, map[string]interface{}, error) {
+	pds := description.GetAllParameterDefinitions()
+
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if _, ok := pds[name]; !ok {
+			return nil, nil, errors.Errorf("unknown parameter %s", name)
+		}
+	}
+
+	// ParseCommandFromMap doesn't check for required parameters
+	names = make([]string, 0, len(pds))
+	for name := range pds {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if _, ok := values[name]; !ok && pds[name].Required {
+			return nil, nil, errors.Errorf("parameter %s is required", name)
+		}
+	}
+
+	return cmds.ParseCommandFromMap(description, values)
+}
+
+// restrictedParameters are the glazed parameters that untrusted values can't set, because
+// they read or write files on the host, or are rendered as templates whose functions,
+// such as env, can read the environment of the process.
+var restrictedParameters = map[string]bool{
+	"output-file":           true,
+	"output-file-template":  true,
+	"output-multiple-files": true,
+	"template-dir":          true,
+	"table-style-file":      true,
+	"replace-file":          true,
+	"template-file":         true,
+	"row-template":          true,
+	"header-template":       true,
+	"footer-template":       true,
+	"template":              true,
+	"template-field":        true,
+	"select-template":       true,
+}
+
+// IsRestrictedParameter returns true if name is one of the glazed parameters that can't be
+// set by ParseUntrustedValues, because they read or write files on the host or render templates.
+func IsRestrictedParameter(name string) bool {
+	return restrictedParameters[name]
+}
+
+// ParseUntrustedValues parses values that come from an untrusted source, such as the
+// body of an HTTP request or the arguments of a tool call, as ParseValues does.
+//
+// Secret parameters must be given as strings, and can't reference environment variables
+// or files. Restricted glazed parameters (see IsRestrictedParameter) can't be set, and the
+// output can't be written to files or rendered with a template.
+func ParseUntrustedValues(
+	description *cmds.CommandDescription,
+	values map[string]interface{},
+) (map[string]*layers.ParsedParameterLayer, map[string]interface{}, error) {
+	pds := description.GetAllParameterDefinitions()
+
+	m := map[string]interface{}{}
+	for k, v := range values {
+		p, ok := pds[k]
+		if ok && p.Type == parameters.ParameterTypeSecret {
+			switch v_ := v.(type) {
+			case parameters.Secret:
+			case string:
+				if parameters.IsSecretReference(v_) {
+					return nil, nil, errors.Errorf("parameter %s can't reference environment variables or files", k)
+				}
+				v = parameters.Secret(v_)
+			default:
+				return nil, nil, errors.Errorf("parameter %s must be a string", k)
+			}
+		}
+		m[k] = v
+	}
+
+	parsedLayers, ps, err := ParseValues(description, m)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	err = checkUntrustedParameters(values, ps)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return parsedLayers, ps, nil
+}
+
+// checkUntrustedParameters checks that the values don't set restricted parameters, and that
+// the output, if any, is neither written to files nor rendered with a template.
+func checkUntrustedParameters(values map[string]interface{}, ps map[string]interface{}) error {
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if IsRestrictedParameter(name) && !isEmpty(ps[name]) {
+			return errors.Errorf("parameter %s can't be set", name)
+		}
+	}
+
+	if _, ok := ps["output"]; !ok {
+		return nil
+	}
+	outputSettings, err := settings.NewOutputFormatterSettings(ps)
+	if err != nil {
+		return err
+	}
+	specs, err := outputSettings.GetOutputSpecs()
+	if err != nil {
+		return err
+	}
+	for _, spec := range specs {
+		if spec.Destination != "" {
+			return errors.New("output can't be written to files")
+		}
+		if spec.Format == "template" {
+			return errors.New("template output can't be used")
+		}
+	}
+	return nil
+}
+
+// isEmpty returns true if v is nil, the zero value of its type, or an empty string, slice or map.
+func isEmpty(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+	rv := reflect.ValueOf(v)
+	//exhaustive:ignore
+	switch rv.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map:
+		return rv.Len() == 0
+	default:
+		return rv.IsZero()
+	}
+}
+
+func run(
+	ctx context.Context,
+	c cmds.Command,
+	parsedLayers map[string]*layers.ParsedParameterLayer,
+	ps map[string]interface{},
+	s *runSettings,
+) ([]types.Row, error) {
+	switch c_ := c.(type) {
+	case cmds.GlazeCommand:
+		gp, err := settings.SetupTableProcessor(ps)
+		if err != nil {
+			return nil, &ParameterError{Err: err}
+		}
+
+		// once the command has failed, closing the processor closes the outputs
+		// without passing the remaining rows and output to the caller
+		var sinkMiddleware *rowSinkMiddleware
+		w := &discardWriter{w: s.writer}
+
+		switch {
+		case s.rowSink != nil:
+			if len(gp.TableMiddlewares) == 0 {
+				gp.AddRowMiddleware(row.NewLambdaMiddleware(func(ctx context.Context, row_ types.Row) ([]types.Row, error) {
+					return []types.Row{row_}, s.rowSink(ctx, row_)
+				}))
+			} else {
+				sinkMiddleware = &rowSinkMiddleware{sink: s.rowSink}
+				gp.AddTableMiddleware(sinkMiddleware)
+			}
+
+		case s.writer != nil:
+			of, err := settings.SetupProcessorOutput(gp, ps, w)
+			if err != nil {
+				return nil, &ParameterError{Err: err}
+			}
+			if s.formatterCallback != nil {
+				s.formatterCallback(of)
+			}
+
+		default:
+			// keep the rows in the table of the processor
+			gp.AddTableMiddleware(&table.NullTableMiddleware{})
+		}
+
+		err = c_.Run(ctx, parsedLayers, ps, gp)
+		if err != nil {
+			if _, ok := err.(*cmds.ExitWithoutGlazeError); !ok {
+				w.discard = true
+				if sinkMiddleware != nil {
+					sinkMiddleware.discard = true
+				}
+			}
+			// the outputs opened by the processor, such as files, are closed in any case
+			_ = gp.Close(ctx)
+			return nil, err
+		}
+		// Close will run the TableMiddlewares
+		err = gp.Close(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		if s.rowSink != nil || s.writer != nil {
+			return nil, nil
+		}
+		rows := gp.GetTable().Rows
+		if rows == nil {
+			rows = []types.Row{}
+		}
+		return rows, nil
+
+	case cmds.WriterCommand:
+		w := s.writer
+		if w == nil {
+			w = os.Stdout
+		}
+		return nil, c_.RunIntoWriter(ctx, parsedLayers, ps, w)
+
+	case cmds.BareCommand:
+		return nil, c_.Run(ctx, parsedLayers, ps)
+
+	default:
+		return nil, errors.Errorf("command %s can't be run", c.Description().Name)
+	}
+}
+
+// rowSinkMiddleware passes the rows of the table to a RowSink once all the other
+// table middlewares have run, unless discard is set.
+type rowSinkMiddleware struct {
+	sink    RowSink
+	discard bool
+}
+
+var _ middlewares.TableMiddleware = (*rowSinkMiddleware)(nil)
+
+func (r *rowSinkMiddleware) Process(ctx context.Context, table *types.Table) (*types.Table, error) {
+	if r.discard {
+		return table, nil
+	}
+	for _, row_ := range table.Rows {
+		err := r.sink(ctx, row_)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return table, nil
+}
+
+func (r *rowSinkMiddleware) Close(ctx context.Context) error {
+	return nil
+}
+
+// discardWriter writes to w, until discard is set.
+type discardWriter struct {
+	w       io.Writer
+	discard bool
+}
+
+func (d *discardWriter) Write(b []byte) (int, error) {
+	if d.discard {
+		return len(b), nil
+	}
+	return d.w.Write(b)
+}
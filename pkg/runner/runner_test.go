@@ -0,0 +1,180 @@
+package runner
+
+import (
+	"bytes"
+	"context"
+	"github.com/go-go-golems/glazed/pkg/cmds/parameters"
+	"github.com/go-go-golems/glazed/pkg/internal/testcommands"
+	"github.com/go-go-golems/glazed/pkg/types"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func rowNames(rows []types.Row) []interface{} {
+	ret := []interface{}{}
+	for _, row := range rows {
+		name, _ := row.Get("name")
+		ret = append(ret, name)
+	}
+	return ret
+}
+
+func TestRunGlazeCommand(t *testing.T) {
+	c, err := testcommands.NewListCommand()
+	require.NoError(t, err)
+
+	rows, err := Run(context.Background(), c, WithValues(map[string]interface{}{
+		"prefix": "item",
+		"count":  3,
+		"fields": []string{"name"},
+	}))
+	require.NoError(t, err)
+	require.Len(t, rows, 3)
+	assert.Equal(t, []interface{}{"item-0", "item-1", "item-2"}, rowNames(rows))
+	_, ok := rows[0].Get("id")
+	assert.False(t, ok)
+
+	var b bytes.Buffer
+	rows, err = Run(context.Background(), c,
+		WithValues(map[string]interface{}{"prefix": "item", "output": "csv"}),
+		WithWriter(&b),
+	)
+	require.NoError(t, err)
+	assert.Nil(t, rows)
+	assert.Equal(t, "id,name\n0,item-0\n1,item-1\n", b.String())
+}
+
+func TestRunRowSink(t *testing.T) {
+	c, err := testcommands.NewListCommand()
+	require.NoError(t, err)
+
+	// rows are streamed while the command runs
+	emitted := []int{}
+	sink := func(ctx context.Context, row types.Row) error {
+		emitted = append(emitted, c.Emitted)
+		return nil
+	}
+	_, err = Run(context.Background(), c,
+		WithValues(map[string]interface{}{"prefix": "item", "count": 3}),
+		WithRowSink(sink),
+	)
+	require.NoError(t, err)
+	assert.Equal(t, []int{1, 2, 3}, emitted)
+
+	// sorted rows are passed once the command is done
+	names := []interface{}{}
+	sink = func(ctx context.Context, row types.Row) error {
+		name, _ := row.Get("name")
+		names = append(names, name)
+		return nil
+	}
+	_, err = Run(context.Background(), c,
+		WithValues(map[string]interface{}{"prefix": "item", "count": 3, "sort-by": []string{"-name"}}),
+		WithRowSink(sink),
+	)
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{"item-2", "item-1", "item-0"}, names)
+
+	_, err = Run(context.Background(), c,
+		WithValues(map[string]interface{}{"prefix": "item"}),
+		WithRowSink(func(ctx context.Context, row types.Row) error {
+			return errors.New("sink failed")
+		}),
+	)
+	assert.EqualError(t, err, "sink failed")
+}
+
+func TestRunWriterAndBareCommands(t *testing.T) {
+	echo := testcommands.NewEchoCommand()
+	var b bytes.Buffer
+	rows, err := Run(context.Background(), echo,
+		WithValues(map[string]interface{}{"message": "hello"}),
+		WithWriter(&b),
+	)
+	require.NoError(t, err)
+	assert.Nil(t, rows)
+	assert.Equal(t, "hello", b.String())
+
+	_, err = Run(context.Background(), testcommands.NewExitCommand())
+	assert.NoError(t, err)
+}
+
+func TestRunErrors(t *testing.T) {
+	c, err := testcommands.NewListCommand()
+	require.NoError(t, err)
+
+	for _, values := range []map[string]interface{}{
+		{"unknown": 1},
+		{"count": "foo"},
+		{"output": "unknown"},
+	} {
+		_, err := Run(context.Background(), c, WithValues(values))
+		assert.IsType(t, &ParameterError{}, err, values)
+	}
+
+	_, err = Run(context.Background(), testcommands.NewEchoCommand())
+	assert.EqualError(t, err, "parameter message is required")
+	assert.IsType(t, &ParameterError{}, err)
+
+	_, err = Run(context.Background(), c, WithValues(map[string]interface{}{"fail": true}))
+	assert.EqualError(t, err, "failed")
+	_, ok := err.(*ParameterError)
+	assert.False(t, ok)
+}
+
+func TestParseUntrustedValues(t *testing.T) {
+	echo := testcommands.NewEchoCommand()
+
+	_, ps, err := ParseUntrustedValues(echo.Description(), map[string]interface{}{
+		"message": "hello",
+		"token":   "s3cret",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, parameters.Secret("s3cret"), ps["token"])
+
+	for _, token := range []interface{}{"env:HOME", "@/etc/passwd", 1} {
+		_, _, err = ParseUntrustedValues(echo.Description(), map[string]interface{}{
+			"message": "hello",
+			"token":   token,
+		})
+		assert.Error(t, err, token)
+	}
+}
+
+func TestRunClosesOutputsOnError(t *testing.T) {
+	c, err := testcommands.NewListCommand()
+	require.NoError(t, err)
+	file := filepath.Join(t.TempDir(), "out.csv")
+
+	var b bytes.Buffer
+	_, err = Run(context.Background(), c,
+		WithValues(map[string]interface{}{
+			"fail-after-rows": true,
+			"output":          []string{"csv:" + file, "yaml"},
+		}),
+		WithWriter(&b),
+	)
+	assert.EqualError(t, err, "failed")
+
+	// the output file is flushed and closed, but nothing is written to the writer
+	out, err := os.ReadFile(file)
+	require.NoError(t, err)
+	assert.Equal(t, "id,name\n0,item-0\n1,item-1\n", string(out))
+	assert.Empty(t, b.String())
+
+	// the rows sorted by the table middlewares are not passed to the row sink
+	rows := []types.Row{}
+	_, err = Run(context.Background(), c,
+		WithValues(map[string]interface{}{"fail-after-rows": true, "sort-by": []string{"-name"}}),
+		WithRowSink(func(ctx context.Context, row types.Row) error {
+			rows = append(rows, row)
+			return nil
+		}),
+	)
+	assert.EqualError(t, err, "failed")
+	assert.Empty(t, rows)
+}
@@ -0,0 +1,315 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/go-go-golems/glazed/pkg/cmds"
+	"github.com/go-go-golems/glazed/pkg/cmds/layers"
+	"github.com/go-go-golems/glazed/pkg/cmds/parameters"
+	"github.com/go-go-golems/glazed/pkg/formatters"
+	"github.com/go-go-golems/glazed/pkg/runner"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"strings"
+)
+
+// Server is an http.Handler that exposes a set of commands as an HTTP API.
+//
+// Each command is mounted at the path returned by cmds.GetCommandPath, for example
+// /db/query, and can be called with GET and POST requests. Its parameters (flags,
+// arguments, and the flags of all its layers, including the glazed output flags) are
+// taken from the body of POST requests, either a JSON object or a form, and from the
+// query string, which takes precedence. Files can be uploaded in multipart forms.
+//
+// The rows output by GlazeCommands are streamed in the format given by the output parameter,
+// or else chosen from the Accept header of the request, defaulting to JSON. The OpenAPI
+// document of the commands is served at /openapi.json.
+//
+// The server is meant to expose commands locally: parameters are parsed with
+// runner.ParseUntrustedValues, so they can't load files from the server or reference its
+// environment. Output can't be written to files or rendered with templates, and the glazed
+// parameters that read files, such as replace-file or table-style-file, can't be set.
+// Commands themselves are free to access the filesystem.
+type Server struct {
+	prefix   string
+	title    string
+	version  string
+	commands map[string]cmds.Command
+	order    []cmds.Command
+}
+
+var _ http.Handler = (*Server)(nil)
+
+type ServerOption func(*Server)
+
+// WithPrefix mounts the commands and the OpenAPI document under prefix, for example /api.
+func WithPrefix(prefix string) ServerOption {
+	return func(s *Server) {
+		s.prefix = strings.TrimRight(prefix, "/")
+	}
+}
+
+// WithInfo sets the title and version of the OpenAPI document.
+func WithInfo(title string, version string) ServerOption {
+	return func(s *Server) {
+		s.title = title
+		s.version = version
+	}
+}
+
+func NewServer(commands []cmds.Command, options ...ServerOption) (*Server, error) {
+	ret := &Server{
+		title:    "glazed",
+		version:  "0.0.0",
+		commands: map[string]cmds.Command{},
+	}
+	for _, o := range options {
+		o(ret)
+	}
+
+	for _, c := range commands {
+		path := cmds.GetCommandPath(c.Description())
+		if _, ok := ret.commands[path]; ok {
+			return nil, errors.Errorf("duplicate command for path %s", path)
+		}
+		switch c.(type) {
+		case cmds.GlazeCommand, cmds.WriterCommand, cmds.BareCommand:
+		default:
+			return nil, errors.Errorf("command %s can't be run", path)
+		}
+		ret.commands[path] = c
+		ret.order = append(ret.order, c)
+	}
+
+	return ret, nil
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasPrefix(r.URL.Path, s.prefix) {
+		writeError(w, http.StatusNotFound, errors.Errorf("not found: %s", r.URL.Path))
+		return
+	}
+	path := strings.TrimPrefix(r.URL.Path, s.prefix)
+
+	if path == "/openapi.json" && r.Method == http.MethodGet {
+		descriptions := []*cmds.CommandDescription{}
+		for _, c := range s.order {
+			descriptions = append(descriptions, c.Description())
+		}
+		w.Header().Set("Content-Type", "application/json")
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		_ = encoder.Encode(cmds.NewOpenAPIDocument(s.title, s.version, descriptions...))
+		return
+	}
+
+	c, ok := s.commands[path]
+	if !ok {
+		writeError(w, http.StatusNotFound, errors.Errorf("not found: %s", r.URL.Path))
+		return
+	}
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		w.Header().Set("Allow", "GET, POST")
+		writeError(w, http.StatusMethodNotAllowed, errors.Errorf("method %s not allowed", r.Method))
+		return
+	}
+
+	parsedLayers, ps, err := ParseRequest(c.Description(), r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	rw := &responseWriter{w: w}
+	// glaze commands set the content type of their output format
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	_, err = runner.RunParsed(r.Context(), c, parsedLayers, ps,
+		runner.WithWriter(rw),
+		runner.WithFormatterCallback(func(of formatters.OutputFormatter) {
+			w.Header().Set("Content-Type", of.ContentType())
+		}),
+	)
+	if err == nil {
+		if !rw.written {
+			w.Header().Del("Content-Type")
+			w.WriteHeader(http.StatusNoContent)
+		}
+		return
+	}
+
+	if rw.written {
+		// the status has already been sent, all we can do is cut the response short
+		log.Error().Err(err).Str("path", path).Msg("error running command")
+		return
+	}
+	status := http.StatusInternalServerError
+	if _, ok := err.(*runner.ParameterError); ok {
+		status = http.StatusBadRequest
+	}
+	writeError(w, status, err)
+}
+
+// maxFormMemory is the size of the uploaded files kept in memory, larger files are stored
+// in temporary files.
+const maxFormMemory = 32 << 20
+
+// ParseRequest parses the parameters of the command described by description out of the
+// body of a POST request, which is either a JSON object or a form, and the query string of r,
+// as described in Server. Forms and query strings are parsed with parameters.GatherParametersFromForm,
+// which allows uploading files in multipart forms.
+//
+// If no output format is given, it is chosen from the Accept header, see GetOutputFromAccept.
+func ParseRequest(
+	description *cmds.CommandDescription,
+	r *http.Request,
+) (map[string]*layers.ParsedParameterLayer, map[string]interface{}, error) {
+	pds := description.GetAllParameterDefinitions()
+
+	m := map[string]interface{}{}
+	if r.Method == http.MethodPost && r.ContentLength != 0 {
+		mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		switch mediaType {
+		case "", "application/json":
+			err := json.NewDecoder(r.Body).Decode(&m)
+			if err != nil && err != io.EOF {
+				return nil, nil, errors.Wrap(err, "could not decode JSON body")
+			}
+
+		case "application/x-www-form-urlencoded", "multipart/form-data":
+			form, err := parseForm(r, mediaType)
+			if err != nil {
+				return nil, nil, err
+			}
+			ps_, err := parseFormParameters(pds, form)
+			if err != nil {
+				return nil, nil, err
+			}
+			for k, v := range ps_ {
+				m[k] = v
+			}
+
+		default:
+			return nil, nil, errors.Errorf("unsupported content type %s", mediaType)
+		}
+	}
+
+	ps_, err := parseFormParameters(pds, &multipart.Form{Value: r.URL.Query()})
+	if err != nil {
+		return nil, nil, err
+	}
+	for k, v := range ps_ {
+		m[k] = v
+	}
+
+	if _, ok := pds["output"]; ok {
+		if _, ok := m["output"]; !ok {
+			m["output"] = []string{GetOutputFromAccept(r.Header.Get("Accept"))}
+		}
+	}
+
+	return runner.ParseUntrustedValues(description, m)
+}
+
+func parseForm(r *http.Request, mediaType string) (*multipart.Form, error) {
+	if mediaType == "multipart/form-data" {
+		err := r.ParseMultipartForm(maxFormMemory)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not parse multipart form")
+		}
+		return r.MultipartForm, nil
+	}
+	err := r.ParseForm()
+	if err != nil {
+		return nil, errors.Wrap(err, "could not parse form")
+	}
+	return &multipart.Form{Value: r.PostForm}, nil
+}
+
+// parseFormParameters parses the parameters given in a form or the query string, which
+// can't contain unknown keys.
+func parseFormParameters(
+	pds map[string]*parameters.ParameterDefinition,
+	form *multipart.Form,
+) (map[string]interface{}, error) {
+	keys := []string{}
+	for k := range form.Value {
+		keys = append(keys, k)
+	}
+	for k := range form.File {
+		keys = append(keys, k)
+	}
+	for _, k := range keys {
+		name, _, _ := parameters.SplitQueryKey(k)
+		if _, ok := pds[name]; !ok {
+			return nil, errors.Errorf("unknown parameter %s", name)
+		}
+	}
+
+	return parameters.GatherParametersFromForm(form, pds, true)
+}
+
+// acceptedOutputs maps media types to glazed output formats.
+var acceptedOutputs = map[string]string{
+	"application/json":          "json",
+	"application/yaml":          "yaml",
+	"application/x-yaml":        "yaml",
+	"text/yaml":                 "yaml",
+	"text/csv":                  "csv",
+	"text/tab-separated-values": "tsv",
+	"application/xml":           "xml",
+	"text/xml":                  "xml",
+	"application/toml":          "toml",
+	"text/markdown":             "markdown",
+	"text/html":                 "html",
+	"text/plain":                "table",
+}
+
+// GetOutputFromAccept returns the output format for the first media type of an Accept
+// header that has one, or json.
+func GetOutputFromAccept(accept string) string {
+	for _, mediaRange := range strings.Split(accept, ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(mediaRange))
+		if err != nil {
+			continue
+		}
+		if output, ok := acceptedOutputs[mediaType]; ok {
+			return output
+		}
+	}
+	return "json"
+}
+
+// responseWriter flushes every write, so that rows are streamed to the client as they
+// are output, and records whether the response has been started.
+type responseWriter struct {
+	w       http.ResponseWriter
+	written bool
+}
+
+func (rw *responseWriter) Header() http.Header {
+	return rw.w.Header()
+}
+
+func (rw *responseWriter) Write(b []byte) (int, error) {
+	if len(b) == 0 {
+		return 0, nil
+	}
+	rw.written = true
+	n, err := rw.w.Write(b)
+	if f, ok := rw.w.(http.Flusher); ok {
+		f.Flush()
+	}
+	return n, err
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{
+		"error": fmt.Sprintf("%s", err),
+	})
+}
@@ -0,0 +1,194 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/go-go-golems/glazed/pkg/cmds"
+	"github.com/go-go-golems/glazed/pkg/internal/testcommands"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newTestServer(t *testing.T) *httptest.Server {
+	list, err := testcommands.NewListCommand()
+	require.NoError(t, err)
+	echo := testcommands.NewEchoCommand()
+
+	s, err := NewServer([]cmds.Command{list, echo}, WithPrefix("/api"))
+	require.NoError(t, err)
+	return httptest.NewServer(s)
+}
+
+func do(t *testing.T, method string, url string, body string, header ...string) (int, string, string) {
+	var r io.Reader
+	if body != "" {
+		r = strings.NewReader(body)
+	}
+	req, err := http.NewRequest(method, url, r)
+	require.NoError(t, err)
+	for i := 0; i+1 < len(header); i += 2 {
+		req.Header.Set(header[i], header[i+1])
+	}
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp.StatusCode, resp.Header.Get("Content-Type"), string(b)
+}
+
+func TestServerGlazeCommand(t *testing.T) {
+	ts := newTestServer(t)
+	defer ts.Close()
+
+	status, contentType, body := do(t, "GET", ts.URL+"/api/items/list?count=3&fields=name", "")
+	require.Equal(t, http.StatusOK, status, body)
+	assert.Equal(t, "application/json", contentType)
+	rows := []map[string]interface{}{}
+	require.NoError(t, json.Unmarshal([]byte(body), &rows))
+	assert.Equal(t, []map[string]interface{}{{"name": "item-0"}, {"name": "item-1"}, {"name": "item-2"}}, rows)
+
+	status, contentType, body = do(t, "GET", ts.URL+"/api/items/list", "", "Accept", "text/html;q=0.9, text/csv")
+	require.Equal(t, http.StatusOK, status, body)
+	assert.Equal(t, "text/html", contentType)
+
+	status, contentType, body = do(t, "GET", ts.URL+"/api/items/list?output=csv", "", "Accept", "application/json")
+	require.Equal(t, http.StatusOK, status, body)
+	assert.Equal(t, "text/csv", contentType)
+	assert.Equal(t, "id,name\n0,item-0\n1,item-1\n", body)
+
+	// the query string takes precedence over the body
+	status, _, body = do(t, "POST", ts.URL+"/api/items/list?count=1",
+		`{"count": 3, "prefix": "row", "output": "csv"}`, "Content-Type", "application/json")
+	require.Equal(t, http.StatusOK, status, body)
+	assert.Equal(t, "id,name\n0,row-0\n", body)
+}
+
+func TestServerErrors(t *testing.T) {
+	ts := newTestServer(t)
+	defer ts.Close()
+
+	for _, url := range []string{
+		"/api/items/list?unknown=1",
+		"/api/items/list?count=foo",
+		"/api/items/list?config=/etc/passwd",
+		"/api/items/list?output-file=/tmp/out.json",
+		"/api/items/list?output=json:/tmp/out.json",
+		"/api/echo",
+		"/api/echo?message=hello&token=env:HOME",
+	} {
+		status, contentType, body := do(t, "GET", ts.URL+url, "")
+		assert.Equal(t, http.StatusBadRequest, status, url)
+		assert.Equal(t, "application/json", contentType)
+		assert.Contains(t, body, `"error"`)
+	}
+
+	status, _, body := do(t, "GET", ts.URL+"/api/items/list?fail", "")
+	assert.Equal(t, http.StatusInternalServerError, status)
+	assert.Contains(t, body, "failed")
+
+	status, _, _ = do(t, "GET", ts.URL+"/api/missing", "")
+	assert.Equal(t, http.StatusNotFound, status)
+	status, _, _ = do(t, "GET", ts.URL+"/items/list", "")
+	assert.Equal(t, http.StatusNotFound, status)
+	status, _, _ = do(t, "DELETE", ts.URL+"/api/items/list", "")
+	assert.Equal(t, http.StatusMethodNotAllowed, status)
+}
+
+func TestServerRestrictedParameters(t *testing.T) {
+	t.Setenv("SERVER_SECRET", "s3cret")
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "x.tmpl"), []byte("server file"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "replace.yaml"), []byte("name:\n  item: server file\n"), 0644))
+
+	ts := newTestServer(t)
+	defer ts.Close()
+
+	for _, body := range []string{
+		`{"output": ["template"], "template-file": "{{ env \"SERVER_SECRET\" }}"}`,
+		`{"output": ["template"], "row-template": "{{ expandenv \"$SERVER_SECRET\" }}"}`,
+		`{"output": "template"}`,
+		`{"template-dir": "` + dir + `", "template-file": "{{ template \"x.tmpl\" . }}"}`,
+		`{"template-dir": "` + dir + `"}`,
+		`{"output": "table", "table-style-file": "` + filepath.Join(dir, "x.tmpl") + `"}`,
+		`{"replace-file": "` + filepath.Join(dir, "replace.yaml") + `"}`,
+		`{"template": "{{ env \"SERVER_SECRET\" }}", "use-row-templates": true}`,
+		`{"template-field": {"name": "{{ env \"SERVER_SECRET\" }}"}, "use-row-templates": true}`,
+		`{"select-template": "{{ env \"SERVER_SECRET\" }}"}`,
+	} {
+		status, _, response := do(t, "POST", ts.URL+"/api/items/list", body, "Content-Type", "application/json")
+		assert.Equal(t, http.StatusBadRequest, status, body)
+		assert.NotContains(t, response, "s3cret", body)
+		assert.NotContains(t, response, "server file", body)
+	}
+
+	status, _, body := do(t, "GET", ts.URL+"/api/items/list?output=template&template-file=x", "")
+	assert.Equal(t, http.StatusBadRequest, status, body)
+	status, _, body = do(t, "GET", ts.URL+"/api/items/list?replace-file="+filepath.Join(dir, "replace.yaml"), "")
+	assert.Equal(t, http.StatusBadRequest, status, body)
+}
+
+func TestServerWriterCommand(t *testing.T) {
+	ts := newTestServer(t)
+	defer ts.Close()
+
+	status, contentType, body := do(t, "POST", ts.URL+"/api/echo", `{"message": "hello", "token": "s3cret"}`)
+	require.Equal(t, http.StatusOK, status, body)
+	assert.Equal(t, "text/plain; charset=utf-8", contentType)
+	assert.Equal(t, "hello s3cret", body)
+}
+
+func TestServerForms(t *testing.T) {
+	ts := newTestServer(t)
+	defer ts.Close()
+
+	status, _, body := do(t, "POST", ts.URL+"/api/items/list?count=1",
+		"count=3&prefix=row&output=csv", "Content-Type", "application/x-www-form-urlencoded")
+	require.Equal(t, http.StatusOK, status, body)
+	assert.Equal(t, "id,name\n0,row-0\n", body)
+
+	var b bytes.Buffer
+	w := multipart.NewWriter(&b)
+	require.NoError(t, w.WriteField("output", "csv"))
+	fw, err := w.CreateFormFile("config", "config.json")
+	require.NoError(t, err)
+	_, err = fw.Write([]byte(`{"host": "localhost"}`))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	status, _, body = do(t, "POST", ts.URL+"/api/items/list", b.String(), "Content-Type", w.FormDataContentType())
+	require.Equal(t, http.StatusOK, status, body)
+	assert.Equal(t, "id,name\n0,item-0\n1,item-1\n", body)
+
+	status, _, body = do(t, "POST", ts.URL+"/api/items/list", "unknown=1",
+		"Content-Type", "application/x-www-form-urlencoded")
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Contains(t, body, "unknown parameter unknown")
+}
+
+func TestServerOpenAPI(t *testing.T) {
+	ts := newTestServer(t)
+	defer ts.Close()
+
+	status, _, body := do(t, "GET", ts.URL+"/api/openapi.json", "")
+	require.Equal(t, http.StatusOK, status)
+	doc := &cmds.OpenAPIDocument{}
+	require.NoError(t, json.Unmarshal([]byte(body), doc))
+	assert.Contains(t, doc.Paths, "/items/list")
+	assert.Contains(t, doc.Paths, "/echo")
+}
+
+func TestGetOutputFromAccept(t *testing.T) {
+	assert.Equal(t, "json", GetOutputFromAccept(""))
+	assert.Equal(t, "json", GetOutputFromAccept("*/*"))
+	assert.Equal(t, "yaml", GetOutputFromAccept("image/png, application/yaml"))
+	assert.Equal(t, "table", GetOutputFromAccept("text/plain; charset=utf-8"))
+}
@@ -1,18 +1,24 @@
 package settings
 
 import (
+	"context"
 	_ "embed"
 	"github.com/go-go-golems/glazed/pkg/cmds/layers"
 	"github.com/go-go-golems/glazed/pkg/cmds/parameters"
 	"github.com/go-go-golems/glazed/pkg/formatters"
 	"github.com/go-go-golems/glazed/pkg/formatters/simple"
+	tableformatter "github.com/go-go-golems/glazed/pkg/formatters/table"
 	"github.com/go-go-golems/glazed/pkg/middlewares"
 	"github.com/go-go-golems/glazed/pkg/middlewares/object"
 	"github.com/go-go-golems/glazed/pkg/middlewares/row"
 	"github.com/go-go-golems/glazed/pkg/middlewares/table"
+	"github.com/go-go-golems/glazed/pkg/types"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	"io"
+	"mime/multipart"
+	"net/url"
+	"os"
 )
 
 // Helpers for cobra commands
@@ -27,6 +33,7 @@ type GlazedParameterLayers struct {
 	JqParameterLayer            *JqParameterLayer            `yaml:"jqParameterLayer"`
 	SortParameterLayer          *SortParameterLayer          `yaml:"sortParameterLayer"`
 	SkipLimitParameterLayer     *SkipLimitParameterLayer     `yaml:"skipLimitParameterLayer"`
+	FormatParameterLayer        *FormatParameterLayer        `yaml:"formatParameterLayer"`
 }
 
 func (g *GlazedParameterLayers) MarshalYAML() (interface{}, error) {
@@ -44,10 +51,28 @@ func (g *GlazedParameterLayers) MarshalYAML() (interface{}, error) {
 			g.TemplateParameterLayer,
 			g.JqParameterLayer,
 			g.SortParameterLayer,
+			g.FormatParameterLayer,
 		},
 	}, nil
 }
 
+var _ layers.CompositeParameterLayer = (*GlazedParameterLayers)(nil)
+
+func (g *GlazedParameterLayers) GetChildLayers() []layers.ParameterLayer {
+	return []layers.ParameterLayer{
+		g.FieldsFiltersParameterLayer,
+		g.OutputParameterLayer,
+		g.RenameParameterLayer,
+		g.ReplaceParameterLayer,
+		g.SelectParameterLayer,
+		g.TemplateParameterLayer,
+		g.JqParameterLayer,
+		g.SortParameterLayer,
+		g.SkipLimitParameterLayer,
+		g.FormatParameterLayer,
+	}
+}
+
 func (g *GlazedParameterLayers) GetName() string {
 	return "Glazed Flags"
 }
@@ -106,6 +131,31 @@ func (g *GlazedParameterLayers) GetParameterDefinitions() map[string]*parameters
 		ret[k] = v
 	}
 
+	for k, v := range g.FormatParameterLayer.GetParameterDefinitions() {
+		ret[k] = v
+	}
+
+	return ret
+}
+
+var _ layers.ParameterRelationsLayer = (*GlazedParameterLayers)(nil)
+
+func (g *GlazedParameterLayers) GetParameterRelations() []*parameters.ParameterRelation {
+	ret := []*parameters.ParameterRelation{}
+	for _, l := range []*layers.ParameterLayerImpl{
+		g.OutputParameterLayer.ParameterLayerImpl,
+		g.FieldsFiltersParameterLayer.ParameterLayerImpl,
+		g.SelectParameterLayer.ParameterLayerImpl,
+		g.TemplateParameterLayer.ParameterLayerImpl,
+		g.RenameParameterLayer.ParameterLayerImpl,
+		g.ReplaceParameterLayer.ParameterLayerImpl,
+		g.JqParameterLayer.ParameterLayerImpl,
+		g.SortParameterLayer.ParameterLayerImpl,
+		g.SkipLimitParameterLayer.ParameterLayerImpl,
+		g.FormatParameterLayer.ParameterLayerImpl,
+	} {
+		ret = append(ret, l.GetParameterRelations()...)
+	}
 	return ret
 }
 
@@ -146,6 +196,10 @@ func (g *GlazedParameterLayers) AddFlagsToCobraCommand(cmd *cobra.Command) error
 	if err != nil {
 		return err
 	}
+	err = g.FormatParameterLayer.AddFlagsToCobraCommand(cmd)
+	if err != nil {
+		return err
+	}
 
 	return nil
 }
@@ -211,6 +265,13 @@ func (g *GlazedParameterLayers) ParseFlagsFromCobraCommand(cmd *cobra.Command) (
 	for k, v := range ps_ {
 		ps[k] = v
 	}
+	ps_, err = g.FormatParameterLayer.ParseFlagsFromCobraCommand(cmd)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range ps_ {
+		ps[k] = v
+	}
 
 	return ps, nil
 }
@@ -276,11 +337,49 @@ func (g *GlazedParameterLayers) ParseFlagsFromJSON(m map[string]interface{}, onl
 	for k, v := range ps_ {
 		ps[k] = v
 	}
+	ps_, err = g.FormatParameterLayer.ParseFlagsFromJSON(m, onlyProvided)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range ps_ {
+		ps[k] = v
+	}
 
 	return ps, nil
 
 }
 
+var _ layers.QueryParameterLayer = (*GlazedParameterLayers)(nil)
+
+func (g *GlazedParameterLayers) ParseFlagsFromQuery(values url.Values, onlyProvided bool) (map[string]interface{}, error) {
+	return g.ParseFlagsFromForm(&multipart.Form{Value: values}, onlyProvided)
+}
+
+func (g *GlazedParameterLayers) ParseFlagsFromForm(form *multipart.Form, onlyProvided bool) (map[string]interface{}, error) {
+	ps := map[string]interface{}{}
+	for _, l := range []*layers.ParameterLayerImpl{
+		g.OutputParameterLayer.ParameterLayerImpl,
+		g.SelectParameterLayer.ParameterLayerImpl,
+		g.RenameParameterLayer.ParameterLayerImpl,
+		g.TemplateParameterLayer.ParameterLayerImpl,
+		g.FieldsFiltersParameterLayer.ParameterLayerImpl,
+		g.ReplaceParameterLayer.ParameterLayerImpl,
+		g.JqParameterLayer.ParameterLayerImpl,
+		g.SortParameterLayer.ParameterLayerImpl,
+		g.SkipLimitParameterLayer.ParameterLayerImpl,
+		g.FormatParameterLayer.ParameterLayerImpl,
+	} {
+		ps_, err := l.ParseFlagsFromForm(form, onlyProvided)
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range ps_ {
+			ps[k] = v
+		}
+	}
+	return ps, nil
+}
+
 func (g *GlazedParameterLayers) InitializeParameterDefaultsFromStruct(s interface{}) error {
 	err := g.OutputParameterLayer.InitializeParameterDefaultsFromStruct(s)
 	if err != nil {
@@ -319,6 +418,10 @@ func (g *GlazedParameterLayers) InitializeParameterDefaultsFromStruct(s interfac
 	if err != nil {
 		return err
 	}
+	err = g.FormatParameterLayer.InitializeParameterDefaultsFromStruct(s)
+	if err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -432,6 +535,18 @@ func WithSkipLimitParameterLayerOptions(options ...layers.ParameterLayerOptions)
 	}
 }
 
+func WithFormatParameterLayerOptions(options ...layers.ParameterLayerOptions) GlazeParameterLayerOption {
+	return func(g *GlazedParameterLayers) error {
+		for _, option := range options {
+			err := option(g.FormatParameterLayer.ParameterLayerImpl)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
 func NewGlazedParameterLayers(options ...GlazeParameterLayerOption) (*GlazedParameterLayers, error) {
 	fieldsFiltersParameterLayer, err := NewFieldsFiltersParameterLayer()
 	if err != nil {
@@ -469,6 +584,10 @@ func NewGlazedParameterLayers(options ...GlazeParameterLayerOption) (*GlazedPara
 	if err != nil {
 		return nil, err
 	}
+	formatParameterLayer, err := NewFormatParameterLayer()
+	if err != nil {
+		return nil, err
+	}
 	ret := &GlazedParameterLayers{
 		FieldsFiltersParameterLayer: fieldsFiltersParameterLayer,
 		OutputParameterLayer:        outputParameterLayer,
@@ -479,6 +598,7 @@ func NewGlazedParameterLayers(options ...GlazeParameterLayerOption) (*GlazedPara
 		JqParameterLayer:            jqParameterLayer,
 		SortParameterLayer:          sortParameterLayer,
 		SkipLimitParameterLayer:     skipLimitParameterLayer,
+		FormatParameterLayer:        formatParameterLayer,
 	}
 
 	for _, option := range options {
@@ -540,7 +660,8 @@ func SetupTableOutputFormatter(ps map[string]interface{}) (formatters.TableOutpu
 //
 // DO(manuel, 2023-06-30) It would be good to used a parsedLayer here, if we ever refactor that part
 func SetupTableProcessor(ps map[string]interface{}, options ...middlewares.TableProcessorOption) (*middlewares.TableProcessor, error) {
-	// TODO(manuel, 2023-03-06): This is where we should check that flags that are mutually incompatible don't clash
+	// Flags that are mutually incompatible are declared as relations in the flags YAML files,
+	// and checked when the command is parsed.
 	//
 	// See: https://github.com/go-go-golems/glazed/issues/199
 	templateSettings, err := NewTemplateSettings(ps)
@@ -598,7 +719,10 @@ func SetupTableProcessor(ps map[string]interface{}, options ...middlewares.Table
 		return nil, errors.Wrapf(err, "Error adding template middlewares")
 	}
 
-	if (outputSettings.Output == "json" || outputSettings.Output == "yaml") && outputSettings.FlattenObjects {
+	// when fanning out to multiple outputs, flattening is done for each output separately
+	if (outputSettings.Output == "json" || outputSettings.Output == "yaml") &&
+		outputSettings.FlattenObjects &&
+		!outputSettings.HasMultipleOutputs() {
 		mw := row.NewFlattenObjectMiddleware()
 		gp.AddRowMiddlewareInFront(mw)
 	}
@@ -654,8 +778,35 @@ func SetupTableProcessor(ps map[string]interface{}, options ...middlewares.Table
 // the chosen output format might be added as well (for example, flattening rows when using table-oriented
 // output formats).
 //
+// If multiple outputs are given (or an output has an explicit destination),
+// see SetupProcessorOutputs.
+//
 // It also returns the output formatter that was created.
 func SetupProcessorOutput(gp *middlewares.TableProcessor, ps map[string]interface{}, w io.Writer) (formatters.OutputFormatter, error) {
+	outputSettings, err := NewOutputFormatterSettings(ps)
+	if err != nil {
+		return nil, err
+	}
+
+	if outputSettings.HasMultipleOutputs() {
+		ofs, err := SetupProcessorOutputs(gp, ps, w)
+		if err != nil {
+			return nil, err
+		}
+		return ofs[0], nil
+	}
+
+	return addOutputMiddleware(gp, ps, w)
+}
+
+// addOutputMiddleware adds the output formatter described by ps to gp,
+// preferring a row formatter over a table formatter when available.
+func addOutputMiddleware(gp *middlewares.TableProcessor, ps map[string]interface{}, w io.Writer) (formatters.OutputFormatter, error) {
+	formatMiddleware, err := setupFormatColumnsMiddleware(ps)
+	if err != nil {
+		return nil, err
+	}
+
 	// first, try to get a row updater
 	rowOf, err := SetupRowOutputFormatter(ps)
 
@@ -664,6 +815,10 @@ func SetupProcessorOutput(gp *middlewares.TableProcessor, ps map[string]interfac
 		if err != nil {
 			return nil, err
 		}
+		if formatMiddleware != nil {
+			gp.AddRowMiddleware(formatMiddleware)
+			setRightAlignedColumns(rowOf, formatMiddleware)
+		}
 		gp.AddRowMiddleware(row.NewOutputMiddleware(rowOf, w))
 		return rowOf, nil
 	} else {
@@ -680,8 +835,219 @@ func SetupProcessorOutput(gp *middlewares.TableProcessor, ps map[string]interfac
 			return nil, err
 		}
 
+		if formatMiddleware != nil {
+			// format the columns once the whole table has been processed, so that
+			// table middlewares such as sorting see the raw values
+			gp.AddTableMiddleware(table.NewFormatColumnsMiddleware(formatMiddleware))
+			setRightAlignedColumns(of, formatMiddleware)
+		}
 		gp.AddTableMiddleware(table.NewOutputMiddleware(of, w))
 
 		return of, nil
 	}
 }
+
+// setupFormatColumnsMiddleware returns the middleware formatting the columns given with
+// --format-column, or nil if there are none or if the output format should keep the raw values.
+func setupFormatColumnsMiddleware(ps map[string]interface{}) (*row.FormatColumnsMiddleware, error) {
+	outputSettings, err := NewOutputFormatterSettings(ps)
+	if err != nil {
+		return nil, err
+	}
+	if !outputSettings.IsTextOutput() {
+		return nil, nil
+	}
+
+	formatSettings, err := NewFormatSettingsFromParameters(ps)
+	if err != nil {
+		return nil, err
+	}
+
+	return formatSettings.NewFormatColumnsMiddleware()
+}
+
+func setRightAlignedColumns(of formatters.OutputFormatter, formatMiddleware *row.FormatColumnsMiddleware) {
+	if tof, ok := of.(*tableformatter.OutputFormatter); ok {
+		tof.RightAlignedColumns = formatMiddleware.NumericFields()
+	}
+}
+
+// outputSink is the processor of a single output when fanning out to multiple outputs.
+// It closes its destination file once its middlewares have been closed.
+type outputSink struct {
+	*middlewares.TableProcessor
+	closer io.Closer
+}
+
+func (o *outputSink) Close(ctx context.Context) error {
+	err := o.TableProcessor.Close(ctx)
+	if o.closer != nil {
+		closeErr := o.closer.Close()
+		if err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
+
+// outputFanOutMiddleware copies the rows to the processors of the outputs. The rows are
+// streamed to the outputs as they come in, unless gp has table middlewares (for example,
+// sorting), in which case the rows of the table are fanned out once it has been processed.
+//
+// The mode is decided when the first row is added, or when gp is closed if there are no rows,
+// so that table middlewares added to gp after setting up the outputs are taken into account.
+type outputFanOutMiddleware struct {
+	gp          *middlewares.TableProcessor
+	rows        *row.FanOutMiddleware
+	table       *table.FanOutMiddleware
+	isDecided   bool
+	isStreaming bool
+}
+
+var _ middlewares.RowMiddleware = (*outputFanOutMiddleware)(nil)
+
+func newOutputFanOutMiddleware(gp *middlewares.TableProcessor, processors ...middlewares.Processor) *outputFanOutMiddleware {
+	return &outputFanOutMiddleware{
+		gp:    gp,
+		rows:  row.NewFanOutMiddleware(processors...),
+		table: table.NewFanOutMiddleware(processors...),
+	}
+}
+
+func (o *outputFanOutMiddleware) decide() {
+	if !o.isDecided {
+		o.isDecided = true
+		o.isStreaming = len(o.gp.TableMiddlewares) == 0
+	}
+}
+
+func (o *outputFanOutMiddleware) Process(ctx context.Context, row_ types.Row) ([]types.Row, error) {
+	o.decide()
+	if o.isStreaming {
+		return o.rows.Process(ctx, row_)
+	}
+	// the row is added to the table of gp
+	return []types.Row{row_}, nil
+}
+
+// Close is called once the table middlewares of gp have processed its table.
+func (o *outputFanOutMiddleware) Close(ctx context.Context) error {
+	o.decide()
+	if o.isStreaming {
+		return o.rows.Close(ctx)
+	}
+
+	_, err := o.table.Process(ctx, o.gp.GetTable())
+	closeErr := o.table.Close(ctx)
+	if err != nil {
+		return err
+	}
+	return closeErr
+}
+
+// SetupProcessorOutputs sets up one output for each of the outputs given in the output parameter.
+//
+// Each output gets its own TableProcessor with the middlewares its output formatter requires,
+// so that for example flattening rows for table output doesn't affect the JSON output.
+// Rows are copied to each of the outputs by a fan-out middleware added to gp. If gp has table
+// middlewares (for example, sorting) when the first row is added, the rows are fanned out once
+// the table has been processed, otherwise they are streamed to the outputs as they come in.
+//
+// Outputs with a destination are written to that file, the others to the output-file parameter
+// if set, else to w. Two outputs can't be written to the same file.
+//
+// It returns the output formatters that were created, in the order of the outputs.
+func SetupProcessorOutputs(gp *middlewares.TableProcessor, ps map[string]interface{}, w io.Writer) ([]formatters.OutputFormatter, error) {
+	outputSettings, err := NewOutputFormatterSettings(ps)
+	if err != nil {
+		return nil, err
+	}
+	specs, err := outputSettings.GetOutputSpecs()
+	if err != nil {
+		return nil, err
+	}
+	if len(specs) == 0 {
+		return nil, errors.New("no output given")
+	}
+	if len(specs) > 1 && outputSettings.OutputMultipleFiles {
+		return nil, errors.New("output-multiple-files is not supported with multiple outputs")
+	}
+
+	destinations := map[string]string{}
+	for _, spec := range specs {
+		destination := spec.Destination
+		if destination == "" {
+			destination = outputSettings.OutputFile
+		}
+		if destination == "" {
+			continue
+		}
+		if format, ok := destinations[destination]; ok {
+			return nil, errors.Errorf("outputs %s and %s are both written to %s", format, spec.Format, destination)
+		}
+		destinations[destination] = spec.Format
+	}
+
+	ofs := []formatters.OutputFormatter{}
+	sinks := []*outputSink{}
+
+	// close the files that were already created if one of the outputs can't be set up
+	closeSinks := func() {
+		for _, sink := range sinks {
+			if sink.closer != nil {
+				_ = sink.closer.Close()
+			}
+		}
+	}
+
+	for _, spec := range specs {
+		ps_ := map[string]interface{}{}
+		for k, v := range ps {
+			ps_[k] = v
+		}
+		ps_["output"] = []string{spec.Format}
+
+		sink := &outputSink{TableProcessor: middlewares.NewTableProcessor()}
+		w_ := w
+		if spec.Destination != "" {
+			if spec.Format == "excel" {
+				// the excel formatter writes the workbook to its output file itself
+				ps_["output-file"] = spec.Destination
+			} else {
+				f, err := os.Create(spec.Destination)
+				if err != nil {
+					closeSinks()
+					return nil, err
+				}
+				sink.closer = f
+				w_ = f
+				ps_["output-file"] = ""
+				if spec.Format == "records" && outputSettings.RecordsMaxWidth == 0 {
+					// don't wrap to the terminal width when writing to a file
+					ps_["records-max-width"] = -1
+				}
+			}
+		}
+		sinks = append(sinks, sink)
+
+		if (spec.Format == "json" || spec.Format == "yaml") && outputSettings.FlattenObjects {
+			sink.AddRowMiddleware(row.NewFlattenObjectMiddleware())
+		}
+
+		of, err := addOutputMiddleware(sink.TableProcessor, ps_, w_)
+		if err != nil {
+			closeSinks()
+			return nil, errors.Wrapf(err, "could not set up output %s", spec.Format)
+		}
+		ofs = append(ofs, of)
+	}
+
+	processors := []middlewares.Processor{}
+	for _, sink := range sinks {
+		processors = append(processors, sink)
+	}
+
+	gp.AddRowMiddleware(newOutputFanOutMiddleware(gp, processors...))
+
+	return ofs, nil
+}
@@ -4,6 +4,7 @@ import (
 	_ "embed"
 	"github.com/go-go-golems/glazed/pkg/cmds/layers"
 	"github.com/go-go-golems/glazed/pkg/cmds/parameters"
+	"github.com/go-go-golems/glazed/pkg/completion"
 	"github.com/go-go-golems/glazed/pkg/middlewares"
 	"github.com/go-go-golems/glazed/pkg/middlewares/row"
 	"github.com/pkg/errors"
@@ -42,6 +43,13 @@ func NewFieldsFiltersParameterLayer(options ...layers.ParameterLayerOptions) (*F
 	}
 	ret.ParameterLayerImpl = layer
 
+	pds := layer.GetParameterDefinitions()
+	for _, name := range []string{"fields", "filter", "remove-duplicates"} {
+		if p, ok := pds[name]; ok {
+			p.Completion = completion.CompleteColumns
+		}
+	}
+
 	return ret, nil
 }
 
@@ -0,0 +1,61 @@
+package settings
+
+import (
+	_ "embed"
+	"github.com/go-go-golems/glazed/pkg/cmds/layers"
+	"github.com/go-go-golems/glazed/pkg/cmds/parameters"
+	"github.com/go-go-golems/glazed/pkg/middlewares/row"
+	"github.com/pkg/errors"
+)
+
+//go:embed "flags/format.yaml"
+var formatFlagsYaml []byte
+
+type FormatSettings struct {
+	FormatColumns []string `glazed.parameter:"format-column"`
+	FormatLocale  string   `glazed.parameter:"format-locale"`
+}
+
+func NewFormatSettingsFromParameters(ps map[string]interface{}) (*FormatSettings, error) {
+	s := &FormatSettings{}
+	err := parameters.InitializeStructFromParameters(s, ps)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to initialize format settings from parameters")
+	}
+
+	return s, nil
+}
+
+// NewFormatColumnsMiddleware returns the middleware formatting the columns given with --format-column,
+// or nil if no column needs to be formatted.
+func (fs *FormatSettings) NewFormatColumnsMiddleware() (*row.FormatColumnsMiddleware, error) {
+	if len(fs.FormatColumns) == 0 {
+		return nil, nil
+	}
+
+	formats := []*row.ColumnFormat{}
+	for _, s := range fs.FormatColumns {
+		format, err := row.ParseColumnFormat(s)
+		if err != nil {
+			return nil, err
+		}
+		formats = append(formats, format)
+	}
+
+	return row.NewFormatColumnsMiddleware(formats, fs.FormatLocale, nil)
+}
+
+type FormatParameterLayer struct {
+	*layers.ParameterLayerImpl `yaml:",inline"`
+}
+
+func NewFormatParameterLayer(options ...layers.ParameterLayerOptions) (*FormatParameterLayer, error) {
+	ret := &FormatParameterLayer{}
+	layer, err := layers.NewParameterLayerFromYAML(formatFlagsYaml, options...)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to create format parameter layer")
+	}
+	ret.ParameterLayerImpl = layer
+
+	return ret, nil
+}
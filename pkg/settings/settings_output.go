@@ -7,15 +7,22 @@ import (
 	"github.com/go-go-golems/glazed/pkg/cmds/layers"
 	"github.com/go-go-golems/glazed/pkg/cmds/parameters"
 	"github.com/go-go-golems/glazed/pkg/formatters"
+	"github.com/go-go-golems/glazed/pkg/formatters/chart"
 	"github.com/go-go-golems/glazed/pkg/formatters/csv"
 	"github.com/go-go-golems/glazed/pkg/formatters/excel"
 	"github.com/go-go-golems/glazed/pkg/formatters/json"
+	"github.com/go-go-golems/glazed/pkg/formatters/records"
 	"github.com/go-go-golems/glazed/pkg/formatters/sql"
 	tableformatter "github.com/go-go-golems/glazed/pkg/formatters/table"
 	templateformatter "github.com/go-go-golems/glazed/pkg/formatters/template"
+	"github.com/go-go-golems/glazed/pkg/formatters/toml"
+	"github.com/go-go-golems/glazed/pkg/formatters/xml"
 	"github.com/go-go-golems/glazed/pkg/formatters/yaml"
 	"github.com/go-go-golems/glazed/pkg/helpers/templating"
+	tsize "github.com/kopoli/go-terminal-size"
 	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"strings"
 	"text/template"
 	"unicode/utf8"
 )
@@ -26,7 +33,10 @@ type TemplateFormatterSettings struct {
 }
 
 type OutputFormatterSettings struct {
-	Output                    string                 `glazed.parameter:"output"`
+	// Output is the format of the first output in Outputs.
+	Output string
+	// Outputs is the list of output specifications, see ParseOutputSpec.
+	Outputs                   []string               `glazed.parameter:"output"`
 	OutputFile                string                 `glazed.parameter:"output-file"`
 	OutputFileTemplate        string                 `glazed.parameter:"output-file-template"`
 	OutputMultipleFiles       bool                   `glazed.parameter:"output-multiple-files"`
@@ -41,11 +51,27 @@ type OutputFormatterSettings struct {
 	WithHeaders               bool                   `glazed.parameter:"with-headers"`
 	CsvSeparator              string                 `glazed.parameter:"csv-separator"`
 	Template                  string                 `glazed.parameter:"template-file"`
+	RowTemplate               string                 `glazed.parameter:"row-template"`
+	HeaderTemplate            string                 `glazed.parameter:"header-template"`
+	FooterTemplate            string                 `glazed.parameter:"footer-template"`
+	TemplateDir               string                 `glazed.parameter:"template-dir"`
 	TemplateData              map[string]interface{} `glazed.parameter:"template-data"`
 	TemplateFormatterSettings *TemplateFormatterSettings
-	SqlTableName              string `glazed.parameter:"sql-table-name"`
-	WithUpsert                bool   `glazed.parameter:"sql-upsert"`
-	SqlSplitByRows            int    `glazed.parameter:"sql-split-by-rows"`
+	SqlTableName              string   `glazed.parameter:"sql-table-name"`
+	WithUpsert                bool     `glazed.parameter:"sql-upsert"`
+	SqlSplitByRows            int      `glazed.parameter:"sql-split-by-rows"`
+	XmlRootElement            string   `glazed.parameter:"xml-root-element"`
+	XmlRowElement             string   `glazed.parameter:"xml-row-element"`
+	XmlAttributeFields        []string `glazed.parameter:"xml-attribute-fields"`
+	XmlScalarsAsAttributes    bool     `glazed.parameter:"xml-scalars-as-attributes"`
+	TomlTableName             string   `glazed.parameter:"toml-table-name"`
+	RecordsMaxWidth           int      `glazed.parameter:"records-max-width"`
+	ChartType                 string   `glazed.parameter:"chart-type"`
+	ChartX                    string   `glazed.parameter:"x"`
+	ChartY                    string   `glazed.parameter:"y"`
+	ChartWidth                int      `glazed.parameter:"chart-width"`
+	ChartHeight               int      `glazed.parameter:"chart-height"`
+	ChartBins                 int      `glazed.parameter:"chart-bins"`
 }
 
 //go:embed "flags/output.yaml"
@@ -55,6 +81,12 @@ type OutputParameterLayer struct {
 	*layers.ParameterLayerImpl `yaml:",inline"`
 }
 
+// OutputFormats are the formats that can be passed to --output.
+var OutputFormats = []string{
+	"table", "csv", "tsv", "json", "yaml", "xml", "toml", "records",
+	"chart", "sql", "template", "markdown", "html", "excel",
+}
+
 func NewOutputParameterLayer(options ...layers.ParameterLayerOptions) (*OutputParameterLayer, error) {
 	ret := &OutputParameterLayer{}
 	layer, err := layers.NewParameterLayerFromYAML(outputFlagsYaml, options...)
@@ -63,19 +95,128 @@ func NewOutputParameterLayer(options ...layers.ParameterLayerOptions) (*OutputPa
 	}
 	ret.ParameterLayerImpl = layer
 
+	// --output takes format[:destination], so it can't be a choice list
+	if p, ok := layer.GetParameterDefinitions()["output"]; ok {
+		p.Pattern = "^(" + strings.Join(OutputFormats, "|") + ")(:.*)?$"
+		p.Completion = completeOutput
+		err = p.CheckConstraintsDefinition()
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return ret, nil
 }
 
+// completeOutput completes the format of an output, and the file it is written to
+// after the colon.
+func completeOutput(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if strings.Contains(toComplete, ":") {
+		return nil, cobra.ShellCompDirectiveDefault
+	}
+	ret := []string{}
+	for _, format := range OutputFormats {
+		if strings.HasPrefix(format, toComplete) {
+			ret = append(ret, format)
+		}
+	}
+	return ret, cobra.ShellCompDirectiveNoFileComp
+}
+
 func NewOutputFormatterSettings(ps map[string]interface{}) (*OutputFormatterSettings, error) {
+	// output used to be a single choice, accept a plain string for backwards compatibility
+	if output, ok := ps["output"].(string); ok {
+		ps_ := map[string]interface{}{}
+		for k, v := range ps {
+			ps_[k] = v
+		}
+		ps_["output"] = []string{output}
+		ps = ps_
+	}
+
 	s := &OutputFormatterSettings{}
 	err := parameters.InitializeStructFromParameters(s, ps)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to initialize output formatter settings")
 	}
 
+	specs, err := s.GetOutputSpecs()
+	if err != nil {
+		return nil, err
+	}
+	if len(specs) > 0 {
+		s.Output = specs[0].Format
+	}
+
 	return s, nil
 }
 
+// OutputSpec is a single output given to --output, in the form format[:destination].
+type OutputSpec struct {
+	Format string
+	// Destination is the file the output is written to. If empty, the output is written
+	// to the writer passed to SetupProcessorOutput.
+	Destination string
+}
+
+func ParseOutputSpec(s string) (*OutputSpec, error) {
+	format, destination, _ := strings.Cut(s, ":")
+	if format == "" {
+		return nil, errors.Errorf("invalid output %s, expected format[:destination]", s)
+	}
+	isKnown := false
+	for _, format_ := range OutputFormats {
+		if format == format_ {
+			isKnown = true
+		}
+	}
+	if !isKnown {
+		return nil, &ErrorUnknownFormat{format}
+	}
+	return &OutputSpec{
+		Format:      format,
+		Destination: destination,
+	}, nil
+}
+
+func (ofs *OutputFormatterSettings) GetOutputSpecs() ([]*OutputSpec, error) {
+	ret := []*OutputSpec{}
+	for _, output := range ofs.Outputs {
+		spec, err := ParseOutputSpec(output)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, spec)
+	}
+	return ret, nil
+}
+
+// HasMultipleOutputs returns true if the rows need to be fanned out to multiple outputs,
+// which is also the case for a single output with an explicit destination.
+func (ofs *OutputFormatterSettings) HasMultipleOutputs() bool {
+	if len(ofs.Outputs) > 1 {
+		return true
+	}
+	for _, output := range ofs.Outputs {
+		if strings.Contains(output, ":") {
+			return true
+		}
+	}
+	return false
+}
+
+// IsTextOutput returns true if the output format renders values as text for humans to read,
+// as opposed to the structured formats that keep the raw values.
+func (ofs *OutputFormatterSettings) IsTextOutput() bool {
+	switch ofs.Output {
+	// charts need the raw numbers to scale their bars
+	case "json", "yaml", "xml", "toml", "excel", "sql", "chart":
+		return false
+	default:
+		return true
+	}
+}
+
 func (ofs *OutputFormatterSettings) computeCanonicalFormat() error {
 	if ofs.Output == "csv" {
 		ofs.Output = "table"
@@ -89,6 +230,8 @@ func (ofs *OutputFormatterSettings) computeCanonicalFormat() error {
 	} else if ofs.Output == "html" {
 		ofs.Output = "table"
 		ofs.TableFormat = "html"
+	} else if ofs.Output == "table" && ofs.TableFormat == "vertical" {
+		ofs.Output = "records"
 	}
 
 	if ofs.OutputMultipleFiles {
@@ -159,7 +302,7 @@ func (ofs *OutputFormatterSettings) CreateRowOutputFormatter() (formatters.RowOu
 				)
 				tsvOf.WithHeaders = ofs.WithHeaders
 				of = tsvOf
-			} else if ofs.TableFormat == "html" || ofs.TableFormat == "markdown" {
+			} else if tableformatter.SupportsRowOutput(ofs.TableFormat) {
 				of = tableformatter.NewOutputFormatter(ofs.TableFormat)
 			} else {
 				return nil, &ErrorRowFormatUnsupported{ofs.Output + ":" + ofs.TableFormat}
@@ -171,6 +314,25 @@ func (ofs *OutputFormatterSettings) CreateRowOutputFormatter() (formatters.RowOu
 		}
 	} else if ofs.Output == "yaml" {
 		return nil, &ErrorRowFormatUnsupported{"yaml"}
+	} else if ofs.Output == "xml" {
+		// writing to files is handled by the table formatter
+		if ofs.OutputFile != "" || ofs.OutputMultipleFiles {
+			return nil, &ErrorRowFormatUnsupported{"xml with output-file"}
+		}
+		of = ofs.createXMLOutputFormatter()
+	} else if ofs.Output == "toml" {
+		if ofs.OutputFile != "" || ofs.OutputMultipleFiles {
+			return nil, &ErrorRowFormatUnsupported{"toml with output-file"}
+		}
+		of = ofs.createTOMLOutputFormatter()
+	} else if ofs.Output == "records" {
+		if ofs.OutputFile != "" || ofs.OutputMultipleFiles {
+			return nil, &ErrorRowFormatUnsupported{"records with output-file"}
+		}
+		of = ofs.createRecordsOutputFormatter()
+	} else if ofs.Output == "chart" {
+		// charts are scaled to the whole table
+		return nil, &ErrorRowFormatUnsupported{"chart"}
 	} else if ofs.Output == "excel" {
 		if ofs.OutputFile == "" {
 			return nil, errors.New("output-file is required for excel output")
@@ -189,7 +351,11 @@ func (ofs *OutputFormatterSettings) CreateRowOutputFormatter() (formatters.RowOu
 			sql.WithSplitByRows(ofs.SqlSplitByRows),
 		)
 	} else if ofs.Output == "template" {
-		return nil, &ErrorRowFormatUnsupported{"template"}
+		// only row templates can be streamed, writing to files is handled by the table formatter
+		if ofs.RowTemplate == "" || ofs.OutputFile != "" || ofs.OutputMultipleFiles {
+			return nil, &ErrorRowFormatUnsupported{"template"}
+		}
+		of = ofs.createTemplateOutputFormatter()
 	} else {
 		return nil, &ErrorUnknownFormat{ofs.Output}
 	}
@@ -218,6 +384,17 @@ func (ofs *OutputFormatterSettings) CreateTableOutputFormatter() (formatters.Tab
 			yaml.WithOutputFileTemplate(ofs.OutputFileTemplate),
 			yaml.WithOutputIndividualRows(ofs.OutputAsObjects),
 		)
+	} else if ofs.Output == "xml" {
+		of = ofs.createXMLOutputFormatter()
+	} else if ofs.Output == "toml" {
+		of = ofs.createTOMLOutputFormatter()
+	} else if ofs.Output == "records" {
+		of = ofs.createRecordsOutputFormatter()
+	} else if ofs.Output == "chart" {
+		if ofs.OutputMultipleFiles {
+			return nil, errors.New("output-multiple-files is not supported for chart output")
+		}
+		of = ofs.createChartOutputFormatter()
 	} else if ofs.Output == "excel" {
 		return nil, &ErrorTableFormatUnsupported{"excel"}
 	} else if ofs.Output == "table" {
@@ -251,25 +428,93 @@ func (ofs *OutputFormatterSettings) CreateTableOutputFormatter() (formatters.Tab
 			)
 		}
 	} else if ofs.Output == "template" {
-		if ofs.TemplateFormatterSettings == nil {
-			ofs.TemplateFormatterSettings = &TemplateFormatterSettings{
-				TemplateFuncMaps: []template.FuncMap{
-					sprig.TxtFuncMap(),
-					templating.TemplateFuncs,
-				},
-			}
-		}
-		of = templateformatter.NewOutputFormatter(
-			ofs.Template,
-			templateformatter.WithTemplateFuncMaps(ofs.TemplateFormatterSettings.TemplateFuncMaps),
-			templateformatter.WithAdditionalData(ofs.TemplateData),
-			templateformatter.WithOutputFile(ofs.OutputFile),
-			templateformatter.WithOutputMultipleFiles(ofs.OutputMultipleFiles),
-			templateformatter.WithOutputFileTemplate(ofs.OutputFileTemplate),
-		)
+		of = ofs.createTemplateOutputFormatter()
 	} else {
 		return nil, &ErrorUnknownFormat{ofs.Output}
 	}
 
 	return of, nil
 }
+
+func (ofs *OutputFormatterSettings) createTemplateOutputFormatter() *templateformatter.OutputFormatter {
+	if ofs.TemplateFormatterSettings == nil {
+		ofs.TemplateFormatterSettings = &TemplateFormatterSettings{
+			TemplateFuncMaps: []template.FuncMap{
+				sprig.TxtFuncMap(),
+				templating.TemplateFuncs,
+			},
+		}
+	}
+	return templateformatter.NewOutputFormatter(
+		ofs.Template,
+		templateformatter.WithRowTemplate(ofs.RowTemplate),
+		templateformatter.WithHeaderTemplate(ofs.HeaderTemplate),
+		templateformatter.WithFooterTemplate(ofs.FooterTemplate),
+		templateformatter.WithTemplateDir(ofs.TemplateDir),
+		templateformatter.WithTemplateFuncMaps(ofs.TemplateFormatterSettings.TemplateFuncMaps),
+		templateformatter.WithAdditionalData(ofs.TemplateData),
+		templateformatter.WithOutputFile(ofs.OutputFile),
+		templateformatter.WithOutputMultipleFiles(ofs.OutputMultipleFiles),
+		templateformatter.WithOutputFileTemplate(ofs.OutputFileTemplate),
+	)
+}
+
+func (ofs *OutputFormatterSettings) createXMLOutputFormatter() *xml.OutputFormatter {
+	return xml.NewOutputFormatter(
+		xml.WithOutputFile(ofs.OutputFile),
+		xml.WithOutputMultipleFiles(ofs.OutputMultipleFiles),
+		xml.WithOutputFileTemplate(ofs.OutputFileTemplate),
+		xml.WithRootElement(ofs.XmlRootElement),
+		xml.WithRowElement(ofs.XmlRowElement),
+		xml.WithAttributeFields(ofs.XmlAttributeFields),
+		xml.WithScalarsAsAttributes(ofs.XmlScalarsAsAttributes),
+	)
+}
+
+func (ofs *OutputFormatterSettings) createTOMLOutputFormatter() *toml.OutputFormatter {
+	return toml.NewOutputFormatter(
+		toml.WithOutputFile(ofs.OutputFile),
+		toml.WithOutputMultipleFiles(ofs.OutputMultipleFiles),
+		toml.WithOutputFileTemplate(ofs.OutputFileTemplate),
+		toml.WithTableName(ofs.TomlTableName),
+	)
+}
+
+func (ofs *OutputFormatterSettings) createRecordsOutputFormatter() *records.OutputFormatter {
+	maxWidth := ofs.RecordsMaxWidth
+	// only wrap to the terminal width when writing to a terminal
+	if maxWidth == 0 && ofs.OutputFile == "" {
+		if size, err := tsize.GetSize(); err == nil {
+			maxWidth = size.Width
+		}
+	}
+	if maxWidth < 0 {
+		maxWidth = 0
+	}
+
+	return records.NewOutputFormatter(
+		records.WithOutputFile(ofs.OutputFile),
+		records.WithOutputMultipleFiles(ofs.OutputMultipleFiles),
+		records.WithOutputFileTemplate(ofs.OutputFileTemplate),
+		records.WithMaxWidth(maxWidth),
+	)
+}
+
+func (ofs *OutputFormatterSettings) createChartOutputFormatter() *chart.OutputFormatter {
+	width := ofs.ChartWidth
+	if width == 0 && ofs.OutputFile == "" {
+		if size, err := tsize.GetSize(); err == nil {
+			width = size.Width
+		}
+	}
+
+	return chart.NewOutputFormatter(
+		chart.WithOutputFile(ofs.OutputFile),
+		chart.WithChartType(ofs.ChartType),
+		chart.WithX(ofs.ChartX),
+		chart.WithY(ofs.ChartY),
+		chart.WithWidth(width),
+		chart.WithHeight(ofs.ChartHeight),
+		chart.WithBins(ofs.ChartBins),
+	)
+}
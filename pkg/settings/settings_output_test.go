@@ -0,0 +1,181 @@
+package settings
+
+import (
+	"bytes"
+	"context"
+	"github.com/go-go-golems/glazed/pkg/middlewares/table"
+	"github.com/go-go-golems/glazed/pkg/types"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func parseGlazedFlags(t *testing.T, args ...string) map[string]interface{} {
+	gpl, err := NewGlazedParameterLayers()
+	require.NoError(t, err)
+
+	cmd := &cobra.Command{}
+	err = gpl.AddFlagsToCobraCommand(cmd)
+	require.NoError(t, err)
+	err = cmd.ParseFlags(args)
+	require.NoError(t, err)
+
+	ps, err := gpl.ParseFlagsFromCobraCommand(cmd)
+	require.NoError(t, err)
+
+	return ps
+}
+
+func TestParseOutputSpec(t *testing.T) {
+	spec, err := ParseOutputSpec("json")
+	require.NoError(t, err)
+	assert.Equal(t, &OutputSpec{Format: "json"}, spec)
+
+	spec, err = ParseOutputSpec("json:results.json")
+	require.NoError(t, err)
+	assert.Equal(t, &OutputSpec{Format: "json", Destination: "results.json"}, spec)
+
+	_, err = ParseOutputSpec(":results.json")
+	assert.Error(t, err)
+
+	_, err = ParseOutputSpec("jsn:results.json")
+	assert.EqualError(t, err, "output format jsn is not supported")
+}
+
+func TestOutputFlagValidation(t *testing.T) {
+	gpl, err := NewGlazedParameterLayers()
+	require.NoError(t, err)
+
+	cmd := &cobra.Command{}
+	err = gpl.AddFlagsToCobraCommand(cmd)
+	require.NoError(t, err)
+	err = cmd.ParseFlags([]string{"-o", "json", "-o", "jsn:results.json"})
+	require.NoError(t, err)
+
+	// unknown formats are rejected when parsing the flags
+	_, err = gpl.ParseFlagsFromCobraCommand(cmd)
+	assert.Error(t, err)
+
+	p := gpl.OutputParameterLayer.GetParameterDefinitions()["output"]
+	completions, directive := p.Complete(cmd, nil, "t")
+	assert.Equal(t, []string{"table", "tsv", "toml", "template"}, completions)
+	assert.Equal(t, cobra.ShellCompDirectiveNoFileComp, directive)
+	_, directive = p.Complete(cmd, nil, "json:")
+	assert.Equal(t, cobra.ShellCompDirectiveDefault, directive)
+}
+
+func TestOutputFormatterSettingsSingleOutput(t *testing.T) {
+	s, err := NewOutputFormatterSettings(parseGlazedFlags(t))
+	require.NoError(t, err)
+	assert.Equal(t, "table", s.Output)
+	assert.False(t, s.HasMultipleOutputs())
+
+	s, err = NewOutputFormatterSettings(parseGlazedFlags(t, "-o", "json"))
+	require.NoError(t, err)
+	assert.Equal(t, "json", s.Output)
+	assert.False(t, s.HasMultipleOutputs())
+
+	// output used to be a single string
+	s, err = NewOutputFormatterSettings(map[string]interface{}{"output": "yaml"})
+	require.NoError(t, err)
+	assert.Equal(t, "yaml", s.Output)
+}
+
+func TestExcelOutputRequiresOutputFile(t *testing.T) {
+	// settings can be created without going through the parameter relations
+	s := &OutputFormatterSettings{Output: "excel"}
+	_, err := s.CreateRowOutputFormatter()
+	assert.EqualError(t, err, "output-file is required for excel output")
+
+	s.OutputFile = filepath.Join(t.TempDir(), "out.xlsx")
+	_, err = s.CreateRowOutputFormatter()
+	assert.NoError(t, err)
+}
+
+func TestSetupProcessorOutputFanOut(t *testing.T) {
+	dir := t.TempDir()
+	jsonFile := filepath.Join(dir, "results.json")
+
+	ps := parseGlazedFlags(t, "-o", "csv", "-o", "json:"+jsonFile)
+	s, err := NewOutputFormatterSettings(ps)
+	require.NoError(t, err)
+	assert.Equal(t, "csv", s.Output)
+	assert.True(t, s.HasMultipleOutputs())
+
+	gp, err := SetupTableProcessor(ps)
+	require.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+	ofs, err := SetupProcessorOutputs(gp, ps, buf)
+	require.NoError(t, err)
+	require.Len(t, ofs, 2)
+	assert.Equal(t, "text/csv", ofs[0].ContentType())
+	assert.Equal(t, "application/json", ofs[1].ContentType())
+
+	ctx := context.Background()
+	err = gp.AddRow(ctx, types.NewRow(
+		types.MRP("a", 1),
+		types.MRP("b", types.NewRow(types.MRP("c", 2))),
+	))
+	require.NoError(t, err)
+	err = gp.Close(ctx)
+	require.NoError(t, err)
+
+	// csv output is flattened, json output isn't
+	assert.Equal(t, "a,b.c\n1,2\n", buf.String())
+
+	b, err := os.ReadFile(jsonFile)
+	require.NoError(t, err)
+	assert.JSONEq(t, `[{"a": 1, "b": {"c": 2}}]`, string(b))
+}
+
+func TestSetupProcessorOutputsLateTableMiddlewares(t *testing.T) {
+	dir := t.TempDir()
+	jsonFile := filepath.Join(dir, "results.json")
+
+	ps := parseGlazedFlags(t, "-o", "csv", "-o", "json:"+jsonFile)
+	gp, err := SetupTableProcessor(ps)
+	require.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+	_, err = SetupProcessorOutputs(gp, ps, buf)
+	require.NoError(t, err)
+
+	// table middlewares added after the outputs are set up still apply to them
+	gp.AddTableMiddleware(table.NewSortByMiddlewareFromColumns("-a"))
+
+	ctx := context.Background()
+	for _, a := range []int{1, 3, 2} {
+		err = gp.AddRow(ctx, types.NewRow(types.MRP("a", a)))
+		require.NoError(t, err)
+	}
+	err = gp.Close(ctx)
+	require.NoError(t, err)
+
+	assert.Equal(t, "a\n3\n2\n1\n", buf.String())
+	b, err := os.ReadFile(jsonFile)
+	require.NoError(t, err)
+	assert.JSONEq(t, `[{"a": 3}, {"a": 2}, {"a": 1}]`, string(b))
+}
+
+func TestSetupProcessorOutputsDuplicateDestinations(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "results.txt")
+
+	for _, args := range [][]string{
+		{"-o", "csv:" + file, "-o", "json:" + file},
+		// outputs without a destination are written to --output-file
+		{"-o", "csv", "-o", "json", "--output-file", file},
+		{"-o", "csv", "-o", "json:" + file, "--output-file", file},
+	} {
+		ps := parseGlazedFlags(t, args...)
+		gp, err := SetupTableProcessor(ps)
+		require.NoError(t, err)
+
+		_, err = SetupProcessorOutputs(gp, ps, &bytes.Buffer{})
+		assert.Error(t, err, args)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	_ "embed"
 	"github.com/go-go-golems/glazed/pkg/cmds/layers"
 	"github.com/go-go-golems/glazed/pkg/cmds/parameters"
+	"github.com/go-go-golems/glazed/pkg/completion"
 	"github.com/go-go-golems/glazed/pkg/middlewares"
 	"github.com/go-go-golems/glazed/pkg/middlewares/table"
 	"github.com/pkg/errors"
@@ -38,6 +39,10 @@ func NewSortParameterLayer(options ...layers.ParameterLayerOptions) (*SortParame
 	}
 	ret.ParameterLayerImpl = layer
 
+	if p, ok := layer.GetParameterDefinitions()["sort-by"]; ok {
+		p.Completion = completion.CompleteColumns
+	}
+
 	return ret, nil
 }
 
@@ -0,0 +1,374 @@
+package tools
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/go-go-golems/glazed/pkg/cmds"
+	"github.com/go-go-golems/glazed/pkg/cmds/layers"
+	"github.com/go-go-golems/glazed/pkg/cmds/parameters"
+	"github.com/go-go-golems/glazed/pkg/runner"
+	"github.com/go-go-golems/glazed/pkg/settings"
+	"github.com/go-go-golems/glazed/pkg/types"
+	"github.com/pkg/errors"
+	"io"
+	"strings"
+)
+
+// ProtocolVersion is the version of the Model Context Protocol returned by initialize.
+const ProtocolVersion = "2024-11-05"
+
+// Server exposes a set of commands as tools over a JSON-RPC 2.0 protocol, with one message
+// per line, as used on stdin and stdout by editor integrations and agents.
+//
+// The methods are those of the tools of the Model Context Protocol:
+//
+//   - initialize returns the protocol version, the capabilities and the name of the server
+//   - tools/list returns the tools, one per command, with the JSON Schema of their parameters
+//   - tools/call runs a command with the arguments given as JSON object
+//   - ping returns an empty result
+//
+// Glaze commands return their rows as JSON, in the text content of the result as well as
+// in its structured content. The output flags of the glazed layers are not tool arguments,
+// and neither are the glazed flags that read files or render templates, such as
+// --replace-file or --template (see runner.IsRestrictedParameter). The other glazed flags,
+// such as --fields or --filter, are applied to the rows.
+// Writer commands return the text they write.
+//
+// Invalid arguments and unknown tools are returned as invalid params errors, and the
+// errors of the commands themselves as errors with the CodeCommandError code.
+type Server struct {
+	name     string
+	version  string
+	commands map[string]cmds.Command
+	order    []string
+}
+
+type ServerOption func(*Server)
+
+// WithInfo sets the name and version of the server returned by initialize.
+func WithInfo(name string, version string) ServerOption {
+	return func(s *Server) {
+		s.name = name
+		s.version = version
+	}
+}
+
+func NewServer(commands []cmds.Command, options ...ServerOption) (*Server, error) {
+	ret := &Server{
+		name:     "glazed",
+		version:  "0.0.0",
+		commands: map[string]cmds.Command{},
+	}
+	for _, o := range options {
+		o(ret)
+	}
+
+	for _, c := range commands {
+		name := cmds.GetCommandOperationID(c.Description())
+		if _, ok := ret.commands[name]; ok {
+			return nil, errors.Errorf("duplicate tool %s", name)
+		}
+		switch c.(type) {
+		case cmds.GlazeCommand, cmds.WriterCommand, cmds.BareCommand:
+		default:
+			return nil, errors.Errorf("command %s can't be run", name)
+		}
+		ret.commands[name] = c
+		ret.order = append(ret.order, name)
+	}
+
+	return ret, nil
+}
+
+// Tool describes a command in the result of tools/list.
+type Tool struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description,omitempty"`
+	InputSchema *parameters.JSONSchema `json:"inputSchema"`
+}
+
+// Content is an item of the content of a CallToolResult.
+type Content struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// CallToolResult is the result of tools/call.
+type CallToolResult struct {
+	Content           []Content   `json:"content"`
+	StructuredContent interface{} `json:"structuredContent,omitempty"`
+}
+
+// ListTools returns a tool for each command, named after its parents and name (see
+// cmds.GetCommandOperationID), in the order in which the commands were given.
+func (s *Server) ListTools() []*Tool {
+	ret := []*Tool{}
+	for _, name := range s.order {
+		description := getToolDescription(s.commands[name])
+		text := description.Short
+		if description.Long != "" {
+			text = strings.TrimSpace(text + "\n\n" + description.Long)
+		}
+		schema := description.ToJSONSchema()
+		hiddenParameters := getHiddenParameters(description)
+		for name := range hiddenParameters {
+			delete(schema.Properties, name)
+		}
+		required := []string{}
+		for _, name := range schema.Required {
+			if !hiddenParameters[name] {
+				required = append(required, name)
+			}
+		}
+		if len(required) != len(schema.Required) {
+			schema.Required = required
+		}
+		ret = append(ret, &Tool{
+			Name:        name,
+			Description: text,
+			InputSchema: schema,
+		})
+	}
+	return ret
+}
+
+// CallTool parses the arguments of the tool, runs its command and returns its output.
+func (s *Server) CallTool(ctx context.Context, name string, arguments map[string]interface{}) (*CallToolResult, error) {
+	c, ok := s.commands[name]
+	if !ok {
+		return nil, NewError(CodeInvalidParams, "unknown tool %s", name)
+	}
+	description := getToolDescription(c)
+
+	parsedLayers, ps, err := parseArguments(description, arguments)
+	if err != nil {
+		return nil, NewError(CodeInvalidParams, "%s", err)
+	}
+
+	// rows are collected by the sink, and writer commands write to b
+	var b bytes.Buffer
+	rows := []types.Row{}
+	_, err = runner.RunParsed(ctx, c, parsedLayers, ps,
+		runner.WithWriter(&b),
+		runner.WithRowSink(func(ctx context.Context, row types.Row) error {
+			rows = append(rows, row)
+			return nil
+		}),
+	)
+	if err != nil {
+		if _, ok := err.(*runner.ParameterError); ok {
+			return nil, NewError(CodeInvalidParams, "%s", err)
+		}
+		return nil, NewError(CodeCommandError, "%s", err)
+	}
+
+	switch c.(type) {
+	case cmds.GlazeCommand:
+		text, err := json.Marshal(rows)
+		if err != nil {
+			return nil, err
+		}
+		return &CallToolResult{
+			Content:           []Content{{Type: "text", Text: string(text)}},
+			StructuredContent: map[string]interface{}{"rows": rows},
+		}, nil
+	case cmds.WriterCommand:
+		return &CallToolResult{Content: []Content{{Type: "text", Text: b.String()}}}, nil
+	default:
+		return &CallToolResult{Content: []Content{}}, nil
+	}
+}
+
+// getToolDescription returns the description of the command without the glazed-command
+// layer, whose flags only make sense on the command line.
+func getToolDescription(c cmds.Command) *cmds.CommandDescription {
+	description := *c.Description()
+	description.Layers = []layers.ParameterLayer{}
+	for _, layer := range c.Description().Layers {
+		if layer.GetSlug() != "glazed-command" {
+			description.Layers = append(description.Layers, layer)
+		}
+	}
+	return &description
+}
+
+// getHiddenParameters returns the names of the parameters that are not tool arguments: the
+// parameters of the glazed output layer, since rows are always returned as JSON, and the
+// glazed parameters that read files or render templates (see runner.IsRestrictedParameter).
+func getHiddenParameters(description *cmds.CommandDescription) map[string]bool {
+	ret := map[string]bool{}
+	for _, layer := range description.Layers {
+		var outputLayer *settings.OutputParameterLayer
+		switch l := layer.(type) {
+		case *settings.GlazedParameterLayers:
+			outputLayer = l.OutputParameterLayer
+		case *settings.OutputParameterLayer:
+			outputLayer = l
+		}
+		for name := range layer.GetParameterDefinitions() {
+			if runner.IsRestrictedParameter(name) {
+				ret[name] = true
+			}
+		}
+		if outputLayer == nil {
+			continue
+		}
+		for name := range outputLayer.GetParameterDefinitions() {
+			ret[name] = true
+		}
+	}
+	return ret
+}
+
+func parseArguments(
+	description *cmds.CommandDescription,
+	arguments map[string]interface{},
+) (map[string]*layers.ParsedParameterLayer, map[string]interface{}, error) {
+	hiddenParameters := getHiddenParameters(description)
+	for k := range arguments {
+		if hiddenParameters[k] {
+			return nil, nil, errors.Errorf("unknown parameter %s", k)
+		}
+	}
+
+	return runner.ParseUntrustedValues(description, arguments)
+}
+
+// Serve reads requests from r, one per line, and writes their responses to w, until r
+// is closed or ctx is canceled. Requests are handled one after the other, and notifications,
+// which have no id, get no response.
+func (s *Server) Serve(ctx context.Context, r io.Reader, w io.Writer) error {
+	reader := bufio.NewReader(r)
+	encoder := json.NewEncoder(w)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(bytes.TrimSpace(line)) > 0 {
+			response := s.handleMessage(ctx, line)
+			if response != nil {
+				err := encoder.Encode(response)
+				if err != nil {
+					return errors.Wrap(err, "could not write response")
+				}
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return errors.Wrap(err, "could not read request")
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+	}
+}
+
+// Request is a JSON-RPC 2.0 request, or a notification if it has no ID.
+type Request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// Response is a JSON-RPC 2.0 response, with either a Result or an Error.
+type Response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+}
+
+var nullID = json.RawMessage("null")
+
+func (s *Server) handleMessage(ctx context.Context, line []byte) *Response {
+	request := &Request{}
+	err := json.Unmarshal(line, request)
+	if err != nil {
+		if json.Valid(line) {
+			return newErrorResponse(nullID, NewError(CodeInvalidRequest, "invalid request"))
+		}
+		return newErrorResponse(nullID, NewError(CodeParseError, "parse error: %s", err))
+	}
+
+	id := request.ID
+	if len(id) == 0 {
+		id = nullID
+	}
+	if request.JSONRPC != "2.0" || request.Method == "" {
+		return newErrorResponse(id, NewError(CodeInvalidRequest, "invalid request"))
+	}
+
+	result, err := s.handleRequest(ctx, request.Method, request.Params)
+	if len(request.ID) == 0 {
+		return nil
+	}
+	if err != nil {
+		return newErrorResponse(id, err)
+	}
+	return &Response{JSONRPC: "2.0", ID: id, Result: result}
+}
+
+func newErrorResponse(id json.RawMessage, err error) *Response {
+	err_, ok := err.(*Error)
+	if !ok {
+		err_ = NewError(CodeInternalError, "%s", err)
+	}
+	return &Response{JSONRPC: "2.0", ID: id, Error: err_}
+}
+
+type initializeResult struct {
+	ProtocolVersion string                 `json:"protocolVersion"`
+	Capabilities    map[string]interface{} `json:"capabilities"`
+	ServerInfo      serverInfo             `json:"serverInfo"`
+}
+
+type serverInfo struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type callToolParams struct {
+	Name      string                 `json:"name"`
+	Arguments map[string]interface{} `json:"arguments"`
+}
+
+func (s *Server) handleRequest(ctx context.Context, method string, params json.RawMessage) (interface{}, error) {
+	switch method {
+	case "initialize":
+		return &initializeResult{
+			ProtocolVersion: ProtocolVersion,
+			Capabilities: map[string]interface{}{
+				"tools": map[string]interface{}{},
+			},
+			ServerInfo: serverInfo{Name: s.name, Version: s.version},
+		}, nil
+
+	case "ping":
+		return map[string]interface{}{}, nil
+
+	case "tools/list":
+		return map[string]interface{}{"tools": s.ListTools()}, nil
+
+	case "tools/call":
+		p := &callToolParams{}
+		if len(params) > 0 {
+			err := json.Unmarshal(params, p)
+			if err != nil {
+				return nil, NewError(CodeInvalidParams, "invalid params: %s", err)
+			}
+		}
+		if p.Name == "" {
+			return nil, NewError(CodeInvalidParams, "missing tool name")
+		}
+		return s.CallTool(ctx, p.Name, p.Arguments)
+
+	default:
+		if strings.HasPrefix(method, "notifications/") {
+			return nil, nil
+		}
+		return nil, NewError(CodeMethodNotFound, "method %s not found", method)
+	}
+}
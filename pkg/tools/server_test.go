@@ -0,0 +1,137 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/go-go-golems/glazed/pkg/cmds"
+	"github.com/go-go-golems/glazed/pkg/internal/testcommands"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+)
+
+func newTestServer(t *testing.T) *Server {
+	list, err := testcommands.NewListCommand()
+	require.NoError(t, err)
+	echo := testcommands.NewEchoCommand()
+
+	s, err := NewServer([]cmds.Command{list, echo}, WithInfo("test", "1.0.0"))
+	require.NoError(t, err)
+	return s
+}
+
+// serve sends the requests to the server and returns the decoded responses.
+func serve(t *testing.T, s *Server, requests ...string) []map[string]interface{} {
+	var out strings.Builder
+	err := s.Serve(context.Background(), strings.NewReader(strings.Join(requests, "\n")), &out)
+	require.NoError(t, err)
+
+	ret := []map[string]interface{}{}
+	decoder := json.NewDecoder(strings.NewReader(out.String()))
+	for decoder.More() {
+		response := map[string]interface{}{}
+		require.NoError(t, decoder.Decode(&response))
+		ret = append(ret, response)
+	}
+	return ret
+}
+
+func TestServerListTools(t *testing.T) {
+	s := newTestServer(t)
+
+	responses := serve(t, s,
+		`{"jsonrpc": "2.0", "id": 1, "method": "initialize", "params": {}}`,
+		`{"jsonrpc": "2.0", "method": "notifications/initialized"}`,
+		`{"jsonrpc": "2.0", "id": 2, "method": "tools/list"}`,
+	)
+	require.Len(t, responses, 2)
+
+	result := responses[0]["result"].(map[string]interface{})
+	assert.Equal(t, ProtocolVersion, result["protocolVersion"])
+	assert.Equal(t, map[string]interface{}{"name": "test", "version": "1.0.0"}, result["serverInfo"])
+
+	tools := s.ListTools()
+	require.Len(t, tools, 2)
+	assert.Equal(t, "items-list", tools[0].Name)
+	assert.Equal(t, "List items", tools[0].Description)
+	assert.Contains(t, tools[0].InputSchema.Properties, "count")
+	assert.Contains(t, tools[0].InputSchema.Properties, "fields")
+	// rows are always returned as JSON
+	assert.NotContains(t, tools[0].InputSchema.Properties, "output")
+	assert.NotContains(t, tools[0].InputSchema.Properties, "output-file")
+	assert.NotContains(t, tools[0].InputSchema.Properties, "table-format")
+	// neither are the flags that read files or render templates
+	assert.NotContains(t, tools[0].InputSchema.Properties, "replace-file")
+	assert.NotContains(t, tools[0].InputSchema.Properties, "template")
+	assert.NotContains(t, tools[0].InputSchema.Properties, "select-template")
+	assert.Equal(t, "echo", tools[1].Name)
+	assert.Equal(t, []string{"message"}, tools[1].InputSchema.Required)
+
+	listed := responses[1]["result"].(map[string]interface{})["tools"].([]interface{})
+	assert.Len(t, listed, 2)
+}
+
+func TestServerCallTool(t *testing.T) {
+	s := newTestServer(t)
+
+	responses := serve(t, s,
+		`{"jsonrpc": "2.0", "id": 1, "method": "tools/call", "params": {"name": "items-list", "arguments": {"count": 3, "fields": ["name"]}}}`,
+		`{"jsonrpc": "2.0", "id": 2, "method": "tools/call", "params": {"name": "echo", "arguments": {"message": "hello"}}}`,
+	)
+	require.Len(t, responses, 2)
+
+	result := responses[0]["result"].(map[string]interface{})
+	rows := []interface{}{
+		map[string]interface{}{"name": "item-0"},
+		map[string]interface{}{"name": "item-1"},
+		map[string]interface{}{"name": "item-2"},
+	}
+	assert.Equal(t, map[string]interface{}{"rows": rows}, result["structuredContent"])
+	content := result["content"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, `[{"name":"item-0"},{"name":"item-1"},{"name":"item-2"}]`, content["text"])
+
+	result = responses[1]["result"].(map[string]interface{})
+	assert.Equal(t, []interface{}{map[string]interface{}{"type": "text", "text": "hello"}}, result["content"])
+}
+
+func TestServerErrors(t *testing.T) {
+	s := newTestServer(t)
+
+	responses := serve(t, s,
+		`not json`,
+		`[1, 2]`,
+		`{"jsonrpc": "2.0", "id": 1, "method": "unknown"}`,
+		`{"jsonrpc": "2.0", "id": 2, "method": "tools/call", "params": {"name": "unknown"}}`,
+		`{"jsonrpc": "2.0", "id": 3, "method": "tools/call", "params": {"name": "echo", "arguments": {}}}`,
+		`{"jsonrpc": "2.0", "id": 4, "method": "tools/call", "params": {"name": "items-list", "arguments": {"count": "foo"}}}`,
+		`{"jsonrpc": "2.0", "id": 5, "method": "tools/call", "params": {"name": "items-list", "arguments": {"unknown": 1}}}`,
+		`{"jsonrpc": "2.0", "id": 6, "method": "tools/call", "params": {"name": "items-list", "arguments": {"output": "csv"}}}`,
+		`{"jsonrpc": "2.0", "id": 7, "method": "tools/call", "params": {"name": "items-list", "arguments": {"replace-file": "/etc/passwd"}}}`,
+		`{"jsonrpc": "2.0", "id": 8, "method": "tools/call", "params": {"name": "items-list", "arguments": {"fail": true}}}`,
+		`{"jsonrpc": "2.0", "method": "unknown"}`,
+	)
+	require.Len(t, responses, 10)
+
+	codes := []interface{}{}
+	for _, response := range responses {
+		codes = append(codes, response["error"].(map[string]interface{})["code"])
+	}
+	assert.Equal(t, []interface{}{
+		float64(CodeParseError),
+		float64(CodeInvalidRequest),
+		float64(CodeMethodNotFound),
+		float64(CodeInvalidParams),
+		float64(CodeInvalidParams),
+		float64(CodeInvalidParams),
+		float64(CodeInvalidParams),
+		float64(CodeInvalidParams),
+		float64(CodeInvalidParams),
+		float64(CodeCommandError),
+	}, codes)
+	assert.Nil(t, responses[0]["id"])
+	assert.Equal(t, "unknown parameter output", responses[7]["error"].(map[string]interface{})["message"])
+	assert.Equal(t, "unknown parameter replace-file", responses[8]["error"].(map[string]interface{})["message"])
+	assert.Equal(t, float64(8), responses[9]["id"])
+	assert.Equal(t, "failed", responses[9]["error"].(map[string]interface{})["message"])
+}